@@ -0,0 +1,94 @@
+// Package main copies every game from one store to another, verifying each
+// copy, for moving a server to a different backend:
+//
+//	copy-store -source fs:/data -target sqlite:/data/words.db -progress /data/copy.progress
+//
+// Stores are given as kind:location, where kind is fs, sqlite or journal,
+// as the server's STORE setting names them. FS stores use the keys in
+// ENCRYPTION_KEYS. Rerunning with the same progress file resumes a copy that
+// stopped part way. Run it while no server is saving games to either store.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/carterjs/words/internal/crypt"
+	"github.com/carterjs/words/internal/store"
+)
+
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	source := flag.String("source", "", "store to copy from, as kind:location")
+	target := flag.String("target", "", "store to copy to, as kind:location")
+	progress := flag.String("progress", "", "file recording finished games, to resume from")
+	flag.Parse()
+
+	keyring, err := newKeyring()
+	if err != nil {
+		panic(fmt.Sprintf("loading encryption keys: %v", err))
+	}
+
+	sourceStore, err := openStore(*source, keyring, logger)
+	if err != nil {
+		panic(fmt.Sprintf("opening source store: %v", err))
+	}
+
+	targetStore, err := openStore(*target, keyring, logger)
+	if err != nil {
+		panic(fmt.Sprintf("opening target store: %v", err))
+	}
+
+	report, err := store.CopyGames(context.Background(), sourceStore, targetStore, *progress)
+	logger.Info("copied games", "copied", len(report.Copied), "resumed", len(report.Resumed), "failed", len(report.Failed))
+	for _, failure := range report.Failed {
+		logger.Warn("could not copy game", "gameID", failure.GameID, "reason", failure.Reason)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("copying games: %v", err))
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+		panic(fmt.Sprintf("writing report: %v", err))
+	}
+
+	if len(report.Failed) > 0 {
+		os.Exit(1)
+	}
+}
+
+// openStore opens the store described as kind:location.
+func openStore(description string, keyring *crypt.Keyring, logger *slog.Logger) (store.Restorer, error) {
+	kind, location, found := strings.Cut(description, ":")
+	if !found || location == "" {
+		return nil, fmt.Errorf("store %q is not kind:location", description)
+	}
+
+	switch kind {
+	case "fs":
+		return store.NewEncryptedFS(location, keyring, logger), nil
+	case "sqlite":
+		return store.OpenSQLite(context.Background(), location)
+	case "journal":
+		return store.NewJournal(location), nil
+	default:
+		return nil, fmt.Errorf("unknown store %q", kind)
+	}
+}
+
+// newKeyring reads the keys snapshots are encrypted with from
+// ENCRYPTION_KEYS, as the server does.
+func newKeyring() (*crypt.Keyring, error) {
+	keys := os.Getenv("ENCRYPTION_KEYS")
+	if keys == "" {
+		return nil, nil
+	}
+
+	return crypt.ParseKeyring(keys)
+}
@@ -0,0 +1,51 @@
+// Package main rewrites the games stored in DATA_DIR in the current snapshot
+// schema, so old snapshots stop being migrated on every load. Run it while
+// no server is saving games to the directory.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/carterjs/words/internal/crypt"
+	"github.com/carterjs/words/internal/store"
+	"github.com/carterjs/words/internal/words"
+)
+
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	dataDirectory := envOrDefault("DATA_DIR", "/tmp/word-game")
+
+	keyring, err := newKeyring()
+	if err != nil {
+		panic(fmt.Sprintf("loading encryption keys: %v", err))
+	}
+
+	migrated, err := store.NewEncryptedFS(dataDirectory, keyring, logger).MigrateGames(context.Background())
+	logger.Info("migrated games", "count", migrated, "schema", words.CurrentSchema, "directory", dataDirectory)
+	if err != nil {
+		panic(fmt.Sprintf("migrating games: %v", err))
+	}
+}
+
+// newKeyring reads the keys snapshots are encrypted with from
+// ENCRYPTION_KEYS, as the server does.
+func newKeyring() (*crypt.Keyring, error) {
+	keys := envOrDefault("ENCRYPTION_KEYS", "")
+	if keys == "" {
+		return nil, nil
+	}
+
+	return crypt.ParseKeyring(keys)
+}
+
+func envOrDefault(key string, fallback string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+
+	return fallback
+}
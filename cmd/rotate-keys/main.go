@@ -0,0 +1,49 @@
+// Package main reseals every game stored in DATA_DIR, along with the game
+// index and share links, and every archived game, with the first key in
+// ENCRYPTION_KEYS, encrypting any stored unencrypted. Once it finishes, the
+// other keys can be dropped from ENCRYPTION_KEYS. Run it while no server is
+// saving games to the directory.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/carterjs/words/internal/crypt"
+	"github.com/carterjs/words/internal/store"
+)
+
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	dataDirectory := envOrDefault("DATA_DIR", "/tmp/word-game")
+
+	keyring, err := crypt.ParseKeyring(envOrDefault("ENCRYPTION_KEYS", ""))
+	if err != nil {
+		panic(fmt.Sprintf("loading encryption keys: %v", err))
+	}
+
+	rotated, err := store.NewEncryptedFS(dataDirectory, keyring, logger).RotateKeys(context.Background())
+	logger.Info("rotated game keys", "count", rotated, "key", keyring.CurrentKeyID(), "directory", dataDirectory)
+	if err != nil {
+		panic(fmt.Sprintf("rotating keys: %v", err))
+	}
+
+	archiveDirectory := filepath.Join(dataDirectory, "archive")
+	rotated, err = store.NewArchive(archiveDirectory, keyring).RotateKeys(context.Background())
+	logger.Info("rotated archive keys", "count", rotated, "key", keyring.CurrentKeyID(), "directory", archiveDirectory)
+	if err != nil {
+		panic(fmt.Sprintf("rotating archive keys: %v", err))
+	}
+}
+
+func envOrDefault(key string, fallback string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+
+	return fallback
+}
@@ -7,39 +7,65 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/carterjs/words/internal/api"
+	"github.com/carterjs/words/internal/crypt"
+	"github.com/carterjs/words/internal/lock"
 	"github.com/carterjs/words/internal/pubsub"
+	"github.com/carterjs/words/internal/retention"
 	"github.com/carterjs/words/internal/store"
 	"github.com/carterjs/words/internal/words"
 )
 
-const (
-	// cleanupInterval is how often idle games are swept.
-	cleanupInterval = time.Hour
-	// maxGameIdle is how long an unfinished game may go without a save
-	// before it is deleted. Finished games are kept.
-	maxGameIdle = 14 * 24 * time.Hour
-)
+// cacheReportInterval is how often the game cache's hit rate is logged.
+const cacheReportInterval = 10 * time.Minute
 
 func main() {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
 	port := envOrDefault("PORT", "8080")
 
-	fileStore := store.NewFS(envOrDefault("DATA_DIR", "/tmp/word-game"))
-	go removeIdleGames(fileStore, logger)
+	dataDirectory := envOrDefault("DATA_DIR", "/tmp/word-game")
+	keyring, err := newKeyring()
+	if err != nil {
+		panic(fmt.Sprintf("loading encryption keys: %v", err))
+	}
+	fileStore := store.NewEncryptedFS(dataDirectory, keyring, logger)
+	if envOrDefault("VALIDATE_ON_LOAD", "") != "" {
+		fileStore.ValidateOnLoad()
+	}
 
-	service := words.NewService(
-		fileStore,
-		pubsub.NewGameBroker(),
-		logger,
-	)
+	gameStore, err := newStore(fileStore, dataDirectory)
+	if err != nil {
+		panic(fmt.Sprintf("opening store: %v", err))
+	}
+	gameStore = newCachedStore(gameStore, logger)
+	archive := store.NewArchive(filepath.Join(dataDirectory, "archive"), keyring)
+	policy, interval, err := retentionSettings()
+	if err != nil {
+		panic(fmt.Sprintf("reading retention settings: %v", err))
+	}
+	go enforceRetention(gameStore, archive, policy, interval, logger)
+
+	broker, err := newBroker(logger)
+	if err != nil {
+		panic(fmt.Sprintf("starting broker: %v", err))
+	}
+
+	locker, err := newLocker(fileStore)
+	if err != nil {
+		panic(fmt.Sprintf("starting locker: %v", err))
+	}
+
+	service := words.NewService(gameStore, broker, locker, logger)
 
 	server := api.NewServer(service, logger, api.Config{
 		PublicDirectory: envOrDefault("PUBLIC_DIR", ""),
 		AllowedOrigin:   envOrDefault("ALLOWED_ORIGIN", ""),
+		AdminToken:      envOrDefault("ADMIN_TOKEN", ""),
 	})
 
 	logger.Info("starting server", "port", port)
@@ -48,20 +74,241 @@ func main() {
 	}
 }
 
-// removeIdleGames periodically deletes unfinished games that have gone stale.
-func removeIdleGames(fileStore *store.FS, logger *slog.Logger) {
+// newKeyring reads the keys snapshots are encrypted with from
+// ENCRYPTION_KEYS, a comma-separated list of id:base64-key pairs whose first
+// key encrypts new saves. Without it, snapshots are stored unencrypted.
+func newKeyring() (*crypt.Keyring, error) {
+	keys := envOrDefault("ENCRYPTION_KEYS", "")
+	if keys == "" {
+		return nil, nil
+	}
+
+	return crypt.ParseKeyring(keys)
+}
+
+// gameStore is a words.Store that can also delete games once they expire.
+type gameStore interface {
+	words.Store
+	DeleteGames(ctx context.Context, gameIDs ...string) error
+}
+
+// newStore picks where games are kept from STORE: "fs" (the default) keeps
+// gzipped snapshots in DATA_DIR, checking each game's invariants as it loads
+// if VALIDATE_ON_LOAD is set, "sqlite" keeps queryable rows in the
+// database at DATABASE_PATH, by default inside DATA_DIR, and "journal" keeps
+// replayable action logs under DATA_DIR/journal.
+func newStore(fileStore *store.FS, dataDirectory string) (gameStore, error) {
+	switch backend := envOrDefault("STORE", "fs"); backend {
+	case "fs":
+		return fileStore, nil
+	case "sqlite":
+		if err := os.MkdirAll(dataDirectory, 0o755); err != nil {
+			return nil, fmt.Errorf("creating data directory: %w", err)
+		}
+
+		return store.OpenSQLite(context.Background(), envOrDefault("DATABASE_PATH", filepath.Join(dataDirectory, "words.db")))
+	case "journal":
+		return store.NewJournal(filepath.Join(dataDirectory, "journal")), nil
+	default:
+		return nil, fmt.Errorf("unknown store %q", backend)
+	}
+}
+
+// cachedStore fronts a gameStore with a store.Cache, dropping deleted games
+// from the cache so it never serves them.
+type cachedStore struct {
+	*store.Cache
+	gameStore gameStore
+}
+
+// newCachedStore keeps up to CACHE_SIZE recently used games in memory; zero
+// disables the cache. It defaults to 256 games, but to none when LOCKER or
+// BROKER let other instances change games behind this one's back, since
+// nothing would tell the cache its copies went stale.
+func newCachedStore(gameStore gameStore, logger *slog.Logger) gameStore {
+	defaultSize := 256
+	if envOrDefault("LOCKER", "local") != "local" || envOrDefault("BROKER", "local") != "local" {
+		defaultSize = 0
+	}
+
+	size := intEnvOrDefault("CACHE_SIZE", defaultSize)
+	if size <= 0 {
+		return gameStore
+	}
+
+	cache := store.NewCache(gameStore, size)
+	go reportCacheStats(cache, logger)
+
+	return cachedStore{Cache: cache, gameStore: gameStore}
+}
+
+func (cached cachedStore) DeleteGames(ctx context.Context, gameIDs ...string) error {
+	// drop them even if deleting fails part way, so none is served stale
+	defer cached.Invalidate(gameIDs...)
+
+	return cached.gameStore.DeleteGames(ctx, gameIDs...)
+}
+
+// reportCacheStats periodically logs how well the game cache is working.
+func reportCacheStats(cache *store.Cache, logger *slog.Logger) {
+	for {
+		time.Sleep(cacheReportInterval)
+
+		stats := cache.Stats()
+		logger.Info("game cache stats",
+			"hitRate", stats.HitRate(),
+			"hits", stats.Hits,
+			"misses", stats.Misses,
+			"evictions", stats.Evictions,
+			"size", stats.Size,
+		)
+	}
+}
+
+// newBroker picks the event broker from BROKER: "local" (the default) keeps
+// events in this process, "remote" connects to the hub at BROKER_HUB_ADDRESS,
+// and "hub" also runs that hub here, listening on BROKER_HUB_LISTEN
+// (loopback only by default). The hub and every remote must share the secret
+// in BROKER_HUB_SECRET.
+func newBroker(logger *slog.Logger) (words.Broker, error) {
+	config := pubsub.Config{
+		BufferSize: intEnvOrDefault("EVENT_BUFFER_SIZE", 0),
+	}
+	secret := envOrDefault("BROKER_HUB_SECRET", "")
+
+	switch backend := envOrDefault("BROKER", "local"); backend {
+	case "local":
+		return pubsub.NewGameBroker(config), nil
+	case "hub":
+		hub, err := pubsub.ListenHub(envOrDefault("BROKER_HUB_LISTEN", "127.0.0.1:7070"), secret, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		go func() {
+			if err := hub.Serve(); err != nil {
+				logger.Error("hub stopped", "error", err)
+			}
+		}()
+
+		logger.Info("starting hub", "address", hub.Addr().String())
+		return pubsub.DialRemote(context.Background(), hub.Addr().String(), secret, config, logger)
+	case "remote":
+		return pubsub.DialRemote(context.Background(), envOrDefault("BROKER_HUB_ADDRESS", "localhost:7070"), secret, config, logger)
+	default:
+		return nil, fmt.Errorf("unknown broker %q", backend)
+	}
+}
+
+// newLocker picks how game mutations are serialized from LOCKER: "local"
+// (the default) within this process, or "lease" across every instance
+// sharing DATA_DIR.
+func newLocker(fileStore *store.FS) (words.Locker, error) {
+	switch backend := envOrDefault("LOCKER", "local"); backend {
+	case "local":
+		return lock.NewLocal(), nil
+	case "lease":
+		return fileStore, nil
+	default:
+		return nil, fmt.Errorf("unknown locker %q", backend)
+	}
+}
+
+// retentionSettings reads the retention policy and how often to enforce it,
+// every RETENTION_INTERVAL (an hour by default). Lobbies expire after
+// RETENTION_LOBBY without a save and running games after RETENTION_RUNNING,
+// both two weeks by default as unfinished games always were; both are
+// deleted. Finished games are kept unless RETENTION_FINISHED is set, after
+// which they are moved to the archive. Limits are Go durations, and zero
+// keeps games forever.
+func retentionSettings() (retention.Policy, time.Duration, error) {
+	var (
+		policy   retention.Policy
+		interval time.Duration
+	)
+	settings := []struct {
+		key      string
+		fallback time.Duration
+		value    *time.Duration
+	}{
+		{key: "RETENTION_LOBBY", fallback: 14 * 24 * time.Hour, value: &policy.LobbyMaxIdle},
+		{key: "RETENTION_RUNNING", fallback: 14 * 24 * time.Hour, value: &policy.RunningMaxIdle},
+		{key: "RETENTION_FINISHED", value: &policy.FinishedMaxIdle},
+		{key: "RETENTION_INTERVAL", fallback: time.Hour, value: &interval},
+	}
+
+	for _, setting := range settings {
+		value, err := durationEnvOrDefault(setting.key, setting.fallback)
+		if err != nil {
+			return retention.Policy{}, 0, err
+		}
+		*setting.value = value
+	}
+
+	if interval <= 0 {
+		return retention.Policy{}, 0, fmt.Errorf("RETENTION_INTERVAL must be positive, got %s", interval)
+	}
+
+	return policy, interval, nil
+}
+
+// enforceRetention takes games the policy expires out of the store every
+// interval. With RETENTION_DRY_RUN set, each pass only logs what it would
+// do.
+func enforceRetention(gameStore gameStore, archive *store.Archive, policy retention.Policy, interval time.Duration, logger *slog.Logger) {
+	dryRun := envOrDefault("RETENTION_DRY_RUN", "") != ""
+
+	enforcer := retention.NewEnforcer(gameStore, archive, policy)
+
 	for {
-		removed, err := fileStore.RemoveIdleGames(context.Background(), maxGameIdle)
+		report, err := enforcer.Enforce(context.Background(), time.Now(), dryRun)
 		if err != nil {
-			logger.Error("cleaning up idle games", "error", err)
-		} else if removed > 0 {
-			logger.Info("cleaned up idle games", "count", removed)
+			logger.Error("enforcing retention", "error", err)
 		}
 
-		time.Sleep(cleanupInterval)
+		if dryRun {
+			for _, summary := range report.Deleted {
+				logger.Info("would delete game", "gameID", summary.ID, "status", summary.Status, "updatedAt", summary.UpdatedAt)
+			}
+			for _, summary := range report.Archived {
+				logger.Info("would archive game", "gameID", summary.ID, "status", summary.Status, "updatedAt", summary.UpdatedAt)
+			}
+		} else if len(report.Deleted) > 0 || len(report.Archived) > 0 {
+			logger.Info("enforced retention", "deleted", len(report.Deleted), "archived", len(report.Archived))
+		}
+
+		time.Sleep(interval)
 	}
 }
 
+// durationEnvOrDefault reads a duration setting, falling back when it is
+// unset. A malformed one is an error rather than quietly replaced, since a
+// typo in a retention limit would otherwise delete games on the default.
+func durationEnvOrDefault(key string, fallback time.Duration) (time.Duration, error) {
+	raw := envOrDefault(key, "")
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", key, err)
+	}
+
+	return value, nil
+}
+
+// intEnvOrDefault reads an integer setting, falling back when it is unset or
+// malformed.
+func intEnvOrDefault(key string, fallback int) int {
+	value, err := strconv.Atoi(envOrDefault(key, ""))
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
 func envOrDefault(key string, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
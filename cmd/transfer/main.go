@@ -0,0 +1,169 @@
+// Package main exports the games a server stores, or imports games into its
+// store, for backups and moving games between servers:
+//
+//	transfer export [flags] > games.jsonl
+//	transfer import [flags] < games.jsonl
+//
+// It opens the store the way the server does, from DATA_DIR, STORE,
+// DATABASE_PATH and ENCRYPTION_KEYS. Imports skip games already stored, so
+// run them while no server is saving games to the same store.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/carterjs/words/internal/crypt"
+	"github.com/carterjs/words/internal/lock"
+	"github.com/carterjs/words/internal/pubsub"
+	"github.com/carterjs/words/internal/store"
+	"github.com/carterjs/words/internal/words"
+)
+
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: transfer export|import [flags]")
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	format := flags.String("format", string(words.TransferFormatJSONLines), "jsonl or tar")
+	file := flags.String("file", "", "file to write or read instead of standard output or input")
+	gameIDs := flags.String("ids", "", "comma-separated IDs of the only games to include")
+	status := flags.String("status", "", "include only games in this status: LOBBY, RUNNING or FINISHED")
+	player := flags.String("player", "", "include only games with a player of this name")
+	createdAfter := flags.String("created-after", "", "include only games created after this RFC 3339 time")
+	createdBefore := flags.String("created-before", "", "include only games created before this RFC 3339 time")
+	if err := flags.Parse(os.Args[2:]); err != nil {
+		panic(fmt.Sprintf("parsing flags: %v", err))
+	}
+
+	transferFormat, err := words.ParseTransferFormat(*format)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	filter := words.TransferFilter{
+		Query: words.GameQuery{
+			Status:     words.GameStatus(strings.ToUpper(*status)),
+			PlayerName: *player,
+		},
+	}
+	if *gameIDs != "" {
+		filter.GameIDs = strings.Split(*gameIDs, ",")
+	}
+	for raw, bound := range map[string]*time.Time{*createdAfter: &filter.Query.CreatedAfter, *createdBefore: &filter.Query.CreatedBefore} {
+		if raw == "" {
+			continue
+		}
+		moment, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			panic(fmt.Sprintf("parsing time %q: %v", raw, err))
+		}
+		*bound = moment
+	}
+
+	service, err := newService(logger)
+	if err != nil {
+		panic(fmt.Sprintf("opening store: %v", err))
+	}
+
+	ctx := context.Background()
+
+	switch command {
+	case "export":
+		output := io.Writer(os.Stdout)
+		if *file != "" {
+			created, err := os.Create(*file)
+			if err != nil {
+				panic(fmt.Sprintf("creating export file: %v", err))
+			}
+			defer created.Close()
+			output = created
+		}
+
+		exported, err := service.ExportGames(ctx, output, transferFormat, filter)
+		logger.Info("exported games", "count", exported, "format", transferFormat)
+		if err != nil {
+			panic(fmt.Sprintf("exporting games: %v", err))
+		}
+	case "import":
+		input := io.Reader(os.Stdin)
+		if *file != "" {
+			opened, err := os.Open(*file)
+			if err != nil {
+				panic(fmt.Sprintf("opening import file: %v", err))
+			}
+			defer opened.Close()
+			input = opened
+		}
+
+		report, err := service.ImportGames(ctx, input, transferFormat, filter)
+		logger.Info("imported games", "imported", len(report.Imported), "skipped", len(report.Skipped), "rejected", len(report.Rejected))
+		for _, rejection := range report.Rejected {
+			logger.Warn("rejected game", "entry", rejection.Entry, "gameID", rejection.GameID, "reason", rejection.Reason)
+		}
+		if err != nil {
+			panic(fmt.Sprintf("importing games: %v", err))
+		}
+
+		if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+			panic(fmt.Sprintf("writing report: %v", err))
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q; want export or import\n", command)
+		os.Exit(2)
+	}
+}
+
+// newService opens the store selected by STORE, as the server does. Nothing
+// subscribes to its events, so they go to a broker of its own.
+func newService(logger *slog.Logger) (*words.Service, error) {
+	dataDirectory := envOrDefault("DATA_DIR", "/tmp/word-game")
+
+	var keyring *crypt.Keyring
+	if keys := envOrDefault("ENCRYPTION_KEYS", ""); keys != "" {
+		parsed, err := crypt.ParseKeyring(keys)
+		if err != nil {
+			return nil, fmt.Errorf("loading encryption keys: %w", err)
+		}
+		keyring = parsed
+	}
+
+	var gameStore words.Store
+	switch backend := envOrDefault("STORE", "fs"); backend {
+	case "fs":
+		gameStore = store.NewEncryptedFS(dataDirectory, keyring, logger)
+	case "sqlite":
+		opened, err := store.OpenSQLite(context.Background(), envOrDefault("DATABASE_PATH", filepath.Join(dataDirectory, "words.db")))
+		if err != nil {
+			return nil, err
+		}
+		gameStore = opened
+	case "journal":
+		gameStore = store.NewJournal(filepath.Join(dataDirectory, "journal"))
+	default:
+		return nil, fmt.Errorf("unknown store %q", backend)
+	}
+
+	return words.NewService(gameStore, pubsub.NewGameBroker(pubsub.Config{}), lock.NewLocal(), logger), nil
+}
+
+func envOrDefault(key string, fallback string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+
+	return fallback
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/carterjs/words/internal/errcode"
+	"github.com/carterjs/words/internal/words"
+)
+
+// withAdmin lets a request through only if it carries the configured admin
+// token as a bearer token. With no token configured, admin routes refuse
+// every request.
+func (server *Server) withAdmin(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if server.config.AdminToken == "" || !found ||
+			subtle.ConstantTimeCompare([]byte(token), []byte(server.config.AdminToken)) != 1 {
+			server.respondWithCode(w, errcode.NotAdmin)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// transferFormat reads an export or import's format from the query
+// string, jsonl by default.
+func transferFormat(r *http.Request) (words.TransferFormat, error) {
+	raw := r.URL.Query().Get("format")
+	if raw == "" {
+		return words.TransferFormatJSONLines, nil
+	}
+
+	return words.ParseTransferFormat(raw)
+}
+
+// transferFilter reads which games an export or import includes from the
+// query string: each id parameter restricts them to those IDs, and the
+// listing filters apply as they do to GET /games.
+func transferFilter(r *http.Request) (words.TransferFilter, bool) {
+	query, valid := parseGameQuery(r)
+	if !valid {
+		return words.TransferFilter{}, false
+	}
+
+	return words.TransferFilter{GameIDs: r.URL.Query()["id"], Query: query}, true
+}
+
+func (server *Server) handleExportGames() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format, err := transferFormat(r)
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		filter, valid := transferFilter(r)
+		if !valid {
+			server.respondWithCode(w, errcode.BadRequest)
+			return
+		}
+
+		contentType := "application/jsonl"
+		if format == words.TransferFormatTar {
+			contentType = "application/x-tar"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="games.`+string(format)+`"`)
+
+		// the status is sent with the first game, so a failure part way can
+		// only cut the export short
+		exported, err := server.service.ExportGames(r.Context(), w, format, filter)
+		if err != nil {
+			server.logger.Error("exporting games", "error", err, "exported", exported)
+			return
+		}
+
+		server.logger.Info("exported games", "count", exported, "format", format)
+	}
+}
+
+func (server *Server) handleImportGames() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format, err := transferFormat(r)
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		filter, valid := transferFilter(r)
+		if !valid {
+			server.respondWithCode(w, errcode.BadRequest)
+			return
+		}
+
+		report, err := server.service.ImportGames(r.Context(), r.Body, format, filter)
+		if err != nil {
+			server.logger.Warn("import stopped", "error", err, "imported", len(report.Imported))
+			server.respondWithError(w, err)
+			return
+		}
+
+		server.logger.Info("imported games", "imported", len(report.Imported), "skipped", len(report.Skipped), "rejected", len(report.Rejected))
+		server.respondWithJSON(w, http.StatusOK, report)
+	}
+}
+
+func (server *Server) handleValidateGames() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query, valid := parseGameQuery(r)
+		if !valid {
+			server.respondWithCode(w, errcode.BadRequest)
+			return
+		}
+
+		report, err := server.service.ValidateGames(r.Context(), query)
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		server.logger.Info("validated games", "checked", report.Checked, "invalid", len(report.Invalid), "unreadable", len(report.Unreadable))
+		server.respondWithJSON(w, http.StatusOK, report)
+	}
+}
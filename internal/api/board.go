@@ -55,6 +55,10 @@ func (server *Server) handleGetGameBoard() http.HandlerFunc {
 			return
 		}
 
+		if notModified(w, r, game) {
+			return
+		}
+
 		area := parseExtents(r, extentsCovering(game.Board().Bounds()))
 
 		server.respondWithJSON(w, http.StatusOK, boardResponse{
@@ -76,6 +80,12 @@ func (server *Server) handleUpdateBoard() http.HandlerFunc {
 			return
 		}
 
+		r, code, valid := withIfMatch(r)
+		if !valid {
+			server.respondWithCode(w, code)
+			return
+		}
+
 		switch body.Operation {
 		case "ADD_WORD":
 			server.addWordToBoard(w, r, r.PathValue("gameId"), body.Payload)
@@ -111,12 +121,14 @@ func (server *Server) addWordToBoard(w http.ResponseWriter, r *http.Request, gam
 
 	word := words.NewWord(words.NewPoint(request.X, request.Y), direction, request.Word)
 
-	_, result, err := server.service.PlayWord(r.Context(), gameID, playerID, word)
+	game, result, err := server.service.PlayWord(r.Context(), gameID, playerID, word)
 	if err != nil {
 		server.respondWithError(w, err)
 		return
 	}
 
+	setETag(w, r, game)
+
 	server.respondWithJSON(w, http.StatusOK, constructPlacementResponse(result))
 }
 
@@ -142,6 +154,10 @@ func (server *Server) handleGetGameBoardPlacements() http.HandlerFunc {
 			return
 		}
 
+		if notModified(w, r, game) {
+			return
+		}
+
 		placements, err := game.FindPlacements(playerID, words.NewPoint(column, row), word)
 		if err != nil {
 			server.respondWithError(w, err)
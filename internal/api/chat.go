@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/carterjs/words/internal/errcode"
+	"github.com/carterjs/words/internal/words"
+)
+
+type messageResponse struct {
+	ID          string    `json:"id"`
+	SenderID    string    `json:"senderId"`
+	SenderName  string    `json:"senderName"`
+	RecipientID string    `json:"recipientId,omitempty"`
+	Text        string    `json:"text"`
+	SentAt      time.Time `json:"sentAt"`
+}
+
+// handleGetGameMessages returns the chat backlog visible to the requester:
+// public messages plus their own whispers.
+func (server *Server) handleGetGameMessages() http.HandlerFunc {
+	type responseBody struct {
+		Messages []messageResponse `json:"messages"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		game, err := server.service.GameByID(r.Context(), r.PathValue("gameId"))
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		if notModified(w, r, game) {
+			return
+		}
+
+		participantID, _ := participantIDFromRequest(r)
+
+		messages := []messageResponse{}
+		for _, message := range game.MessagesFor(participantID) {
+			messages = append(messages, constructMessageResponse(message))
+		}
+
+		server.respondWithJSON(w, http.StatusOK, responseBody{Messages: messages})
+	}
+}
+
+func (server *Server) sendMessage(w http.ResponseWriter, r *http.Request, gameID string, payload json.RawMessage) {
+	senderID, identified := participantIDFromRequest(r)
+	if !identified {
+		server.respondWithCode(w, errcode.MissingParticipant)
+		return
+	}
+
+	var request struct {
+		Text        string `json:"text"`
+		RecipientID string `json:"recipientId"`
+	}
+	if err := json.Unmarshal(payload, &request); err != nil {
+		server.respondWithCode(w, errcode.BadRequest)
+		return
+	}
+
+	game, message, err := server.service.SendMessage(r.Context(), gameID, senderID, request.RecipientID, request.Text)
+	if err != nil {
+		server.respondWithError(w, err)
+		return
+	}
+
+	setETag(w, r, game)
+
+	server.respondWithJSON(w, http.StatusCreated, constructMessageResponse(message))
+}
+
+func (server *Server) muteParticipant(w http.ResponseWriter, r *http.Request, gameID string, payload json.RawMessage) {
+	type muteResponse struct {
+		ParticipantID string `json:"participantId"`
+		Muted         bool   `json:"muted"`
+	}
+
+	hostID, identified := playerIDFromRequest(r)
+	if !identified {
+		server.respondWithCode(w, errcode.MissingPlayer)
+		return
+	}
+
+	var request struct {
+		ParticipantID string `json:"participantId"`
+		Muted         bool   `json:"muted"`
+	}
+	if err := json.Unmarshal(payload, &request); err != nil {
+		server.respondWithCode(w, errcode.BadRequest)
+		return
+	}
+
+	game, err := server.service.MuteParticipant(r.Context(), gameID, hostID, request.ParticipantID, request.Muted)
+	if err != nil {
+		server.respondWithError(w, err)
+		return
+	}
+
+	setETag(w, r, game)
+
+	server.respondWithJSON(w, http.StatusOK, muteResponse{
+		ParticipantID: request.ParticipantID,
+		Muted:         game.Muted(request.ParticipantID),
+	})
+}
+
+func constructMessageResponse(message words.ChatMessage) messageResponse {
+	return messageResponse{
+		ID:          message.ID,
+		SenderID:    message.SenderID,
+		SenderName:  message.SenderName,
+		RecipientID: message.RecipientID,
+		Text:        message.Text,
+		SentAt:      message.SentAt,
+	}
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/carterjs/words/internal/errcode"
+	"github.com/carterjs/words/internal/words"
+)
+
+// setETag labels the response with a tag for this representation of the
+// game. It leads with the state version, which If-Match compares against,
+// followed by the full version, so chat and spectators still change the tag,
+// and a digest of the query and the viewer's identity cookies, since both
+// change what the response shows. The response varies by cookie
+// accordingly.
+func setETag(w http.ResponseWriter, r *http.Request, game *words.Game) {
+	digest := fnv.New64a()
+	io.WriteString(digest, r.URL.RawQuery)
+	for _, name := range []string{playerIDCookie, spectatorIDCookie} {
+		value, _ := cookieValue(r, name)
+		io.WriteString(digest, "\x00"+value)
+	}
+
+	w.Header().Set("ETag", strconv.Quote(fmt.Sprintf("%d-%d-%x", game.StateVersion(), game.Version(), digest.Sum64())))
+	w.Header().Add("Vary", "Cookie")
+}
+
+// notModified sets the game's ETag and, if the request's If-None-Match
+// already names it, answers 304 Not Modified and reports true. If-None-Match
+// uses weak comparison, so a weak copy of the tag counts.
+func notModified(w http.ResponseWriter, r *http.Request, game *words.Game) bool {
+	setETag(w, r, game)
+
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	if strings.TrimSpace(header) == "*" {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+
+	tags, valid := parseEntityTags(header)
+	if !valid {
+		return false
+	}
+
+	current := w.Header().Get("ETag")
+	for _, tag := range tags {
+		if strconv.Quote(tag.opaque) == current {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// withIfMatch carries the state versions of the request's If-Match tags into
+// its context, so the service refuses the update unless the game is still at
+// one of them. If-Match uses strong comparison: a weak tag never matches,
+// and neither does a strong one this server did not issue, so a header
+// naming no usable tag fails the precondition outright. It reports false,
+// with the code to respond with, if the header cannot be honored.
+func withIfMatch(r *http.Request) (*http.Request, errcode.Code, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return r, "", true
+	}
+
+	tags, valid := parseEntityTags(header)
+	if !valid {
+		return r, errcode.BadRequest, false
+	}
+
+	var versions []uint64
+	for _, tag := range tags {
+		if tag.weak {
+			continue
+		}
+
+		if version, issued := stateVersionOf(tag.opaque); issued {
+			versions = append(versions, version)
+		}
+	}
+
+	if len(versions) == 0 {
+		return r, errcode.VersionMismatch, false
+	}
+
+	return r.WithContext(words.WithExpectedVersion(r.Context(), versions...)), "", true
+}
+
+// entityTag is one tag from a conditional request header.
+type entityTag struct {
+	weak   bool
+	opaque string
+}
+
+// parseEntityTags parses a comma-separated list of entity tags, each a
+// quoted string optionally prefixed with W/ to mark it weak. It reports
+// false if the list is malformed or empty.
+func parseEntityTags(header string) ([]entityTag, bool) {
+	var tags []entityTag
+
+	rest := header
+	for {
+		rest = strings.TrimLeft(rest, " \t,")
+		if rest == "" {
+			break
+		}
+
+		var tag entityTag
+		if strings.HasPrefix(rest, "W/") {
+			tag.weak = true
+			rest = rest[len("W/"):]
+		}
+
+		if !strings.HasPrefix(rest, `"`) {
+			return nil, false
+		}
+
+		opaque, remainder, closed := strings.Cut(rest[1:], `"`)
+		if !closed || strings.ContainsAny(opaque, " \t") {
+			return nil, false
+		}
+		tag.opaque = opaque
+
+		rest = strings.TrimLeft(remainder, " \t")
+		if rest != "" && rest[0] != ',' {
+			return nil, false
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, len(tags) > 0
+}
+
+// stateVersionOf reads the state version leading a tag set by setETag,
+// reporting false if the tag is not one of ours.
+func stateVersionOf(opaque string) (uint64, bool) {
+	parts := strings.Split(opaque, "-")
+	if len(parts) != 3 {
+		return 0, false
+	}
+
+	stateVersion, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	if _, err := strconv.ParseUint(parts[1], 10, 64); err != nil {
+		return 0, false
+	}
+
+	if _, err := strconv.ParseUint(parts[2], 16, 64); err != nil {
+		return 0, false
+	}
+
+	return stateVersion, true
+}
@@ -6,7 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/carterjs/words/internal/words"
 )
 
 // keepaliveInterval is how often a comment is written to an otherwise quiet
@@ -14,55 +17,80 @@ import (
 const keepaliveInterval = 25 * time.Second
 
 // handleStreamGameEvents streams a game's events over server-sent events.
-// Players identified by their cookie also receive their private events.
+// Players identified by their cookie also receive their private events;
+// everyone else is treated as a spectator. Each event carries its ID, and a
+// reconnecting client's Last-Event-ID header replays what it missed.
 func (server *Server) handleStreamGameEvents() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "text/event-stream")
-		w.Header().Set("Cache-Control", "no-cache")
-		w.Header().Set("Connection", "keep-alive")
-
-		gameID := r.PathValue("gameId")
 		playerID, _ := playerIDFromRequest(r)
 
-		subscription := server.service.Subscribe(r.Context(), gameID, playerID)
+		// browsers resend the ID of the last event they saw on reconnect
+		lastEventID, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+
+		subscription, err := server.service.Subscribe(r.Context(), r.PathValue("gameId"), playerID, lastEventID)
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
 		defer subscription.Close()
 
-		flusher, canFlush := w.(http.Flusher)
+		streamEvents(w, r, subscription, nil)
+	}
+}
 
-		// flush the headers right away so clients see the stream as open and
-		// can catch up on state missed while (re)connecting
-		if canFlush {
-			flusher.Flush()
-		}
+// streamEvents writes the subscription's events to the response as
+// server-sent events until the client goes away. A non-nil redact rewrites
+// each event first, and drops those it reports false for.
+func streamEvents(w http.ResponseWriter, r *http.Request, subscription words.Subscription, redact func(event words.Event) (words.Event, bool)) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
 
-		for {
-			waitCtx, cancel := context.WithTimeout(r.Context(), keepaliveInterval)
-			event, err := subscription.Next(waitCtx)
-			cancel()
-
-			if err != nil {
-				if r.Context().Err() != nil || !errors.Is(err, context.DeadlineExceeded) {
-					return
-				}
-
-				// quiet stretch: write a comment so the connection stays alive
-				fmt.Fprint(w, ": keepalive\n\n")
-				if canFlush {
-					flusher.Flush()
-				}
-				continue
-			}
+	flusher, canFlush := w.(http.Flusher)
 
-			payload := event.Payload
-			if payload == nil {
-				payload = json.RawMessage("{}")
-			}
+	// flush the headers right away so clients see the stream as open and
+	// can catch up on state missed while (re)connecting
+	if canFlush {
+		flusher.Flush()
+	}
 
-			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, payload)
+	for {
+		waitCtx, cancel := context.WithTimeout(r.Context(), keepaliveInterval)
+		event, err := subscription.Next(waitCtx)
+		cancel()
 
+		if err != nil {
+			if r.Context().Err() != nil || !errors.Is(err, context.DeadlineExceeded) {
+				return
+			}
+
+			// quiet stretch: write a comment so the connection stays alive
+			fmt.Fprint(w, ": keepalive\n\n")
 			if canFlush {
 				flusher.Flush()
 			}
+			continue
+		}
+
+		if redact != nil {
+			var keep bool
+			if event, keep = redact(event); !keep {
+				continue
+			}
+		}
+
+		payload := event.Payload
+		if payload == nil {
+			payload = json.RawMessage("{}")
+		}
+
+		if event.ID != 0 {
+			fmt.Fprintf(w, "id: %d\n", event.ID)
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, payload)
+
+		if canFlush {
+			flusher.Flush()
 		}
 	}
 }
@@ -3,6 +3,9 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/carterjs/words/internal/errcode"
 	"github.com/carterjs/words/internal/words"
@@ -15,6 +18,11 @@ type (
 		Score int    `json:"score"`
 	}
 
+	spectatorResponse struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
 	challengeResponse struct {
 		ChallengerID   string `json:"challengerId"`
 		MoverID        string `json:"moverId"`
@@ -28,19 +36,26 @@ type (
 	}
 
 	gameResponse struct {
-		ID                   string             `json:"id"`
-		Started              bool               `json:"started"`
-		Finished             bool               `json:"finished"`
-		Round                int                `json:"round"`
-		CurrentPlayerID      string             `json:"currentPlayerId"`
-		LettersRemaining     int                `json:"lettersRemaining"`
-		Players              []playerResponse   `json:"players"`
-		LetterPoints         map[string]int     `json:"letterPoints"`
-		WinnerIDs            []string           `json:"winnerIds,omitempty"`
-		Challenge            *challengeResponse `json:"challenge,omitempty"`
-		ChallengeableMoverID string             `json:"challengeableMoverId,omitempty"`
-		PlayerID             string             `json:"playerId"`
-		Rack                 []string           `json:"rack,omitempty"`
+		ID                   string              `json:"id"`
+		Version              uint64              `json:"version"`
+		StateVersion         uint64              `json:"stateVersion"`
+		Started              bool                `json:"started"`
+		Finished             bool                `json:"finished"`
+		Round                int                 `json:"round"`
+		CurrentPlayerID      string              `json:"currentPlayerId"`
+		LettersRemaining     int                 `json:"lettersRemaining"`
+		Players              []playerResponse    `json:"players"`
+		HostID               string              `json:"hostId,omitempty"`
+		SpectatorCount       int                 `json:"spectatorCount"`
+		Spectators           []spectatorResponse `json:"spectators"`
+		LetterPoints         map[string]int      `json:"letterPoints"`
+		WinnerIDs            []string            `json:"winnerIds,omitempty"`
+		Challenge            *challengeResponse  `json:"challenge,omitempty"`
+		ChallengeableMoverID string              `json:"challengeableMoverId,omitempty"`
+		PlayerID             string              `json:"playerId"`
+		Rack                 []string            `json:"rack,omitempty"`
+		LastEventID          uint64              `json:"lastEventId"`
+		SpectatorID          string              `json:"spectatorId,omitempty"`
 	}
 
 	turnResponse struct {
@@ -57,6 +72,7 @@ func (server *Server) handleCreateGame() http.HandlerFunc {
 		RackSize           int            `json:"rackSize,omitempty"`
 		LetterDistribution map[string]int `json:"letterDistribution,omitempty"`
 		LetterPoints       map[string]int `json:"letterPoints,omitempty"`
+		SpectatorDelay     int            `json:"spectatorDelaySeconds,omitempty"`
 	}
 
 	type requestBody struct {
@@ -75,12 +91,15 @@ func (server *Server) handleCreateGame() http.HandlerFunc {
 			RackSize:           body.Overrides.RackSize,
 			LetterDistribution: runeCounts(body.Overrides.LetterDistribution),
 			LetterPoints:       runeCounts(body.Overrides.LetterPoints),
+			SpectatorDelay:     time.Duration(body.Overrides.SpectatorDelay) * time.Second,
 		})
 		if err != nil {
 			server.respondWithError(w, err)
 			return
 		}
 
+		setETag(w, r, game)
+
 		server.respondWithJSON(w, http.StatusCreated, constructGameResponse(r, game))
 	}
 }
@@ -93,6 +112,10 @@ func (server *Server) handleGetGameByID() http.HandlerFunc {
 			return
 		}
 
+		if notModified(w, r, game) {
+			return
+		}
+
 		server.respondWithJSON(w, http.StatusOK, constructGameResponse(r, game))
 	}
 }
@@ -110,11 +133,21 @@ func (server *Server) handleUpdateGame() http.HandlerFunc {
 			return
 		}
 
+		r, code, valid := withIfMatch(r)
+		if !valid {
+			server.respondWithCode(w, code)
+			return
+		}
+
 		gameID := r.PathValue("gameId")
 
 		switch body.Operation {
 		case "JOIN_GAME":
 			server.joinGame(w, r, gameID, body.Payload)
+		case "SPECTATE_GAME":
+			server.spectateGame(w, r, gameID, body.Payload)
+		case "LEAVE_GAME":
+			server.leaveGame(w, r, gameID)
 		case "START_GAME":
 			server.startGame(w, r, gameID)
 		case "PASS_TURN":
@@ -125,6 +158,10 @@ func (server *Server) handleUpdateGame() http.HandlerFunc {
 			server.challengeWord(w, r, gameID)
 		case "CAST_VOTE":
 			server.castVote(w, r, gameID, body.Payload)
+		case "SEND_MESSAGE":
+			server.sendMessage(w, r, gameID, body.Payload)
+		case "MUTE_PARTICIPANT":
+			server.muteParticipant(w, r, gameID, body.Payload)
 		default:
 			server.respondWithCode(w, errcode.UnknownOperation)
 		}
@@ -151,14 +188,9 @@ func (server *Server) joinGame(w http.ResponseWriter, r *http.Request, gameID st
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     playerIDCookie,
-		Value:    player.ID(),
-		Path:     r.URL.Path,
-		Secure:   true,
-		SameSite: http.SameSiteStrictMode,
-		HttpOnly: true,
-	})
+	setETag(w, r, game)
+
+	setIdentityCookie(w, r, playerIDCookie, player.ID())
 
 	server.respondWithJSON(w, http.StatusCreated, joinResponse{
 		PlayerID: player.ID(),
@@ -166,6 +198,60 @@ func (server *Server) joinGame(w http.ResponseWriter, r *http.Request, gameID st
 	})
 }
 
+func (server *Server) spectateGame(w http.ResponseWriter, r *http.Request, gameID string, payload json.RawMessage) {
+	type spectateResponse struct {
+		SpectatorID string              `json:"spectatorId"`
+		Spectators  []spectatorResponse `json:"spectators"`
+	}
+
+	var request struct {
+		SpectatorName string `json:"spectatorName"`
+	}
+	if err := json.Unmarshal(payload, &request); err != nil {
+		server.respondWithCode(w, errcode.BadRequest)
+		return
+	}
+
+	game, spectator, err := server.service.SpectateGame(r.Context(), gameID, request.SpectatorName)
+	if err != nil {
+		server.respondWithError(w, err)
+		return
+	}
+
+	setETag(w, r, game)
+
+	setIdentityCookie(w, r, spectatorIDCookie, spectator.ID())
+
+	server.respondWithJSON(w, http.StatusCreated, spectateResponse{
+		SpectatorID: spectator.ID(),
+		Spectators:  constructSpectatorResponses(game),
+	})
+}
+
+func (server *Server) leaveGame(w http.ResponseWriter, r *http.Request, gameID string) {
+	type leaveResponse struct {
+		Spectators []spectatorResponse `json:"spectators"`
+	}
+
+	spectatorID, identified := spectatorIDFromRequest(r)
+	if !identified {
+		server.respondWithCode(w, errcode.MissingParticipant)
+		return
+	}
+
+	game, err := server.service.LeaveGame(r.Context(), gameID, spectatorID)
+	if err != nil {
+		server.respondWithError(w, err)
+		return
+	}
+
+	setETag(w, r, game)
+
+	server.respondWithJSON(w, http.StatusOK, leaveResponse{
+		Spectators: constructSpectatorResponses(game),
+	})
+}
+
 func (server *Server) startGame(w http.ResponseWriter, r *http.Request, gameID string) {
 	type startResponse struct {
 		Started bool     `json:"started"`
@@ -178,6 +264,8 @@ func (server *Server) startGame(w http.ResponseWriter, r *http.Request, gameID s
 		return
 	}
 
+	setETag(w, r, game)
+
 	rack := []string{}
 	if playerID, identified := playerIDFromRequest(r); identified {
 		if player, exists := game.PlayerByID(playerID); exists {
@@ -204,6 +292,8 @@ func (server *Server) passTurn(w http.ResponseWriter, r *http.Request, gameID st
 		return
 	}
 
+	setETag(w, r, game)
+
 	server.respondWithJSON(w, http.StatusOK, constructTurnResponse(game, playerID))
 }
 
@@ -234,6 +324,8 @@ func (server *Server) exchangeLetters(w http.ResponseWriter, r *http.Request, ga
 		return
 	}
 
+	setETag(w, r, game)
+
 	server.respondWithJSON(w, http.StatusOK, constructTurnResponse(game, playerID))
 }
 
@@ -244,12 +336,14 @@ func (server *Server) challengeWord(w http.ResponseWriter, r *http.Request, game
 		return
 	}
 
-	_, outcome, err := server.service.ChallengeWord(r.Context(), gameID, playerID)
+	game, outcome, err := server.service.ChallengeWord(r.Context(), gameID, playerID)
 	if err != nil {
 		server.respondWithError(w, err)
 		return
 	}
 
+	setETag(w, r, game)
+
 	server.respondWithJSON(w, http.StatusOK, constructChallengeResponse(outcome))
 }
 
@@ -268,12 +362,14 @@ func (server *Server) castVote(w http.ResponseWriter, r *http.Request, gameID st
 		return
 	}
 
-	_, outcome, err := server.service.CastVote(r.Context(), gameID, playerID, request.Vote)
+	game, outcome, err := server.service.CastVote(r.Context(), gameID, playerID, request.Vote)
 	if err != nil {
 		server.respondWithError(w, err)
 		return
 	}
 
+	setETag(w, r, game)
+
 	server.respondWithJSON(w, http.StatusOK, constructChallengeResponse(outcome))
 }
 
@@ -285,12 +381,18 @@ func constructGameResponse(r *http.Request, game *words.Game) gameResponse {
 
 	response := gameResponse{
 		ID:               game.ID(),
+		Version:          game.Version(),
+		StateVersion:     game.StateVersion(),
 		Started:          game.Started(),
 		Finished:         game.Finished(),
 		Round:            game.Round(),
 		CurrentPlayerID:  game.CurrentPlayerID(),
 		LettersRemaining: game.LettersRemaining(),
+		HostID:           game.HostID(),
+		LastEventID:      game.LastEventID(),
 		Players:          constructPlayerResponses(game),
+		SpectatorCount:   len(game.Spectators()),
+		Spectators:       constructSpectatorResponses(game),
 		LetterPoints:     letterPoints,
 		WinnerIDs:        game.WinnerIDs(),
 	}
@@ -311,9 +413,27 @@ func constructGameResponse(r *http.Request, game *words.Game) gameResponse {
 		}
 	}
 
+	if spectatorID, identified := spectatorIDFromRequest(r); identified {
+		if _, exists := game.SpectatorByID(spectatorID); exists {
+			response.SpectatorID = spectatorID
+		}
+	}
+
 	return response
 }
 
+func constructSpectatorResponses(game *words.Game) []spectatorResponse {
+	spectators := []spectatorResponse{}
+	for _, spectator := range game.Spectators() {
+		spectators = append(spectators, spectatorResponse{
+			ID:   spectator.ID(),
+			Name: spectator.Name(),
+		})
+	}
+
+	return spectators
+}
+
 func constructPlayerResponses(game *words.Game) []playerResponse {
 	players := []playerResponse{}
 	for _, player := range game.Players() {
@@ -398,3 +518,120 @@ func lettersFromStrings(rawLetters []string) ([]rune, bool) {
 
 	return letters, true
 }
+
+type gameSummaryResponse struct {
+	ID        string           `json:"id"`
+	Version   uint64           `json:"version"`
+	Status    words.GameStatus `json:"status"`
+	Round     int              `json:"round"`
+	CreatedAt time.Time        `json:"createdAt"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+	Players   []playerResponse `json:"players"`
+}
+
+// handleListGames lists games newest first, filtered by the status, player,
+// createdAfter, createdBefore, updatedAfter, and updatedBefore parameters.
+// A page ends with a nextCursor to pass back as cursor for the next one.
+// Summaries carry game and player IDs, which are enough to act in a game,
+// so the listing is only served to admins.
+func (server *Server) handleListGames() http.HandlerFunc {
+	type responseBody struct {
+		Games      []gameSummaryResponse `json:"games"`
+		NextCursor string                `json:"nextCursor,omitempty"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		query, valid := parseGameQuery(r)
+		if !valid {
+			server.respondWithCode(w, errcode.BadRequest)
+			return
+		}
+
+		if raw := r.URL.Query().Get("cursor"); raw != "" {
+			cursor, err := words.ParseGameCursor(raw)
+			if err != nil {
+				server.respondWithError(w, err)
+				return
+			}
+			query.After = &cursor
+		}
+
+		page, err := server.service.ListGames(r.Context(), query)
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		response := responseBody{Games: []gameSummaryResponse{}, NextCursor: page.NextCursor}
+		for _, summary := range page.Games {
+			response.Games = append(response.Games, constructGameSummaryResponse(summary))
+		}
+
+		server.respondWithJSON(w, http.StatusOK, response)
+	}
+}
+
+// parseGameQuery reads a listing's filters and limit, reporting false if any
+// is malformed.
+func parseGameQuery(r *http.Request) (words.GameQuery, bool) {
+	parameters := r.URL.Query()
+
+	query := words.GameQuery{
+		Status:     words.GameStatus(strings.ToUpper(parameters.Get("status"))),
+		PlayerName: parameters.Get("player"),
+	}
+
+	switch query.Status {
+	case "", words.GameStatusLobby, words.GameStatusRunning, words.GameStatusFinished:
+	default:
+		return words.GameQuery{}, false
+	}
+
+	bounds := map[string]*time.Time{
+		"createdAfter":  &query.CreatedAfter,
+		"createdBefore": &query.CreatedBefore,
+		"updatedAfter":  &query.UpdatedAfter,
+		"updatedBefore": &query.UpdatedBefore,
+	}
+	for key, bound := range bounds {
+		if raw := parameters.Get(key); raw != "" {
+			moment, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return words.GameQuery{}, false
+			}
+			*bound = moment
+		}
+	}
+
+	if raw := parameters.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return words.GameQuery{}, false
+		}
+		query.Limit = limit
+	}
+
+	return query, true
+}
+
+func constructGameSummaryResponse(summary words.GameSummary) gameSummaryResponse {
+	response := gameSummaryResponse{
+		ID:        summary.ID,
+		Version:   summary.Version,
+		Status:    summary.Status,
+		Round:     summary.Round,
+		CreatedAt: summary.CreatedAt,
+		UpdatedAt: summary.UpdatedAt,
+		Players:   []playerResponse{},
+	}
+
+	for _, player := range summary.Players {
+		response.Players = append(response.Players, playerResponse{
+			ID:    player.ID,
+			Name:  player.Name,
+			Score: player.Score,
+		})
+	}
+
+	return response
+}
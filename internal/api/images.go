@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/carterjs/words/internal/errcode"
+	"github.com/carterjs/words/internal/render"
+	"github.com/carterjs/words/internal/words"
+)
+
+// replayFrameDelay is how long each move stays on screen in a replay.
+const replayFrameDelay = time.Second
+
+// boardRenderer draws a window of a board in one image format.
+type boardRenderer func(w io.Writer, board *words.Board, config words.Config, window render.Window) error
+
+// gameLoader finds the game a request is about.
+type gameLoader func(r *http.Request) (*words.Game, error)
+
+// gameFromPath loads the game named by the request's gameId.
+func (server *Server) gameFromPath(r *http.Request) (*words.Game, error) {
+	return server.service.GameByID(r.Context(), r.PathValue("gameId"))
+}
+
+func (server *Server) handleGetBoardImage(loadGame gameLoader, contentType string, renderBoard boardRenderer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		game, err := loadGame(r)
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		window, valid := parseWindow(r, game.Board())
+		if !valid {
+			server.respondWithCode(w, errcode.BadRequest)
+			return
+		}
+
+		if notModified(w, r, game) {
+			return
+		}
+
+		// drawn in full first, so a failure can still set the status
+		var image bytes.Buffer
+		if err := renderBoard(&image, game.Board(), game.Config(), window); err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		server.writeImage(w, contentType, &image)
+	}
+}
+
+func (server *Server) handleGetReplay(loadGame gameLoader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		game, err := loadGame(r)
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		window, valid := parseWindow(r, game.Board())
+		if !valid {
+			server.respondWithCode(w, errcode.BadRequest)
+			return
+		}
+
+		if notModified(w, r, game) {
+			return
+		}
+
+		var animation bytes.Buffer
+		if err := render.ReplayGIF(&animation, game, window, replayFrameDelay); err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		server.writeImage(w, "image/gif", &animation)
+	}
+}
+
+// parseWindow reads the window to draw from the query, defaulting to the
+// same window the board endpoint reports, and reports false when it is not
+// one render can draw.
+func parseWindow(r *http.Request, board *words.Board) (render.Window, bool) {
+	area := parseExtents(r, extentsCovering(board.Bounds()))
+	window := render.Window{MinX: area.minX, MinY: area.minY, MaxX: area.maxX, MaxY: area.maxY}
+
+	return window, window.Validate() == nil
+}
+
+func (server *Server) writeImage(w http.ResponseWriter, contentType string, image *bytes.Buffer) {
+	w.Header().Set("Content-Type", contentType)
+	if _, err := image.WriteTo(w); err != nil {
+		server.logger.Error("writing board image", "error", err)
+	}
+}
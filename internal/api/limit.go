@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/carterjs/words/internal/errcode"
+)
+
+const (
+	// importRateLimit is how many GCG imports one client may make per
+	// importRateWindow.
+	importRateLimit  = 10
+	importRateWindow = time.Minute
+)
+
+// rateLimiter counts each client's requests in fixed windows, forgetting
+// every count when a new window starts. Clients are told apart by address,
+// so everyone behind one proxy shares a limit. It is in-process only: a
+// client spread across several servers gets the limit on each.
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mutex     sync.Mutex
+	counts    map[string]int
+	startedAt time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:  limit,
+		window: window,
+		counts: make(map[string]int),
+	}
+}
+
+// allow counts a request from the client at the given time, reporting
+// whether it is within the limit.
+func (limiter *rateLimiter) allow(client string, now time.Time) bool {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if now.Sub(limiter.startedAt) >= limiter.window {
+		clear(limiter.counts)
+		limiter.startedAt = now
+	}
+
+	if limiter.counts[client] >= limiter.limit {
+		return false
+	}
+
+	limiter.counts[client]++
+	return true
+}
+
+// withRateLimit refuses requests from clients over the limiter's limit with
+// the given code.
+func (server *Server) withRateLimit(limiter *rateLimiter, code errcode.Code, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			client = r.RemoteAddr
+		}
+
+		if !limiter.allow(client, time.Now()) {
+			server.respondWithCode(w, code)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
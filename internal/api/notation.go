@@ -0,0 +1,90 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"github.com/carterjs/words/internal/errcode"
+	"github.com/carterjs/words/internal/words"
+)
+
+func (server *Server) handleExportGCG() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gameID := r.PathValue("gameId")
+
+		// written in full first, so a failure can still set the status
+		var notation bytes.Buffer
+		if err := server.service.ExportGCG(r.Context(), gameID, &notation); err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+gameID+`.gcg"`)
+		if _, err := notation.WriteTo(w); err != nil {
+			server.logger.Error("writing game notation", "error", err, "gameID", gameID)
+		}
+	}
+}
+
+// maxNotationBytes bounds the GCG notation an import reads.
+const maxNotationBytes = 1 << 20
+
+func (server *Server) handleImportGCG() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body := http.MaxBytesReader(w, r.Body, maxNotationBytes)
+
+		game, err := server.service.ImportGCG(r.Context(), body, r.URL.Query().Get("preset"))
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		setETag(w, r, game)
+
+		server.respondWithJSON(w, http.StatusCreated, constructGameResponse(r, game))
+	}
+}
+
+func (server *Server) handleGetTranscript() http.HandlerFunc {
+	contentTypes := map[words.TranscriptFormat]string{
+		words.TranscriptFormatText:      "text/plain; charset=utf-8",
+		words.TranscriptFormatJSONLines: "application/jsonl",
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		format := words.TranscriptFormat(query.Get("format"))
+		if format == "" {
+			format = words.TranscriptFormatText
+		}
+
+		contentType, known := contentTypes[format]
+		if !known {
+			server.respondWithCode(w, errcode.BadRequest)
+			return
+		}
+
+		var boardEvery int
+		if rawBoardEvery := query.Get("boardEvery"); rawBoardEvery != "" {
+			var err error
+			if boardEvery, err = strconv.Atoi(rawBoardEvery); err != nil || boardEvery < 0 {
+				server.respondWithCode(w, errcode.BadRequest)
+				return
+			}
+		}
+
+		var transcript bytes.Buffer
+		if err := server.service.WriteTranscript(r.Context(), r.PathValue("gameId"), &transcript, format, boardEvery); err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		if _, err := transcript.WriteTo(w); err != nil {
+			server.logger.Error("writing transcript", "error", err, "gameID", r.PathValue("gameId"))
+		}
+	}
+}
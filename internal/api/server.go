@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/carterjs/words/internal/errcode"
+	"github.com/carterjs/words/internal/render"
 	"github.com/carterjs/words/internal/words"
 )
 
@@ -23,6 +24,9 @@ const (
 type Config struct {
 	PublicDirectory string
 	AllowedOrigin   string
+	// AdminToken is the bearer token admin routes require. Empty disables
+	// them.
+	AdminToken string
 }
 
 // Server exposes the words service over HTTP.
@@ -30,6 +34,7 @@ type Server struct {
 	service *words.Service
 	logger  *slog.Logger
 	config  Config
+	imports *rateLimiter
 }
 
 // NewServer returns a server for the given service. Empty config fields fall
@@ -46,6 +51,7 @@ func NewServer(service *words.Service, logger *slog.Logger, config Config) *Serv
 		service: service,
 		logger:  logger,
 		config:  config,
+		imports: newRateLimiter(importRateLimit, importRateWindow),
 	}
 }
 
@@ -62,14 +68,46 @@ func (server *Server) Handler() http.Handler {
 	mux.Handle("POST /api/v1/games", server.handleCreateGame())
 	mux.Handle("GET /api/v1/games/{gameId}", server.handleGetGameByID())
 	mux.Handle("PATCH /api/v1/games/{gameId}", server.handleUpdateGame())
+	mux.Handle("GET /api/v1/games/{gameId}/export.gcg", server.handleExportGCG())
+	mux.Handle("GET /api/v1/games/{gameId}/transcript", server.handleGetTranscript())
+	mux.Handle("POST /api/v1/games/import", server.withRateLimit(server.imports, errcode.TooManyImports, server.handleImportGCG()))
 
 	// board
 	mux.Handle("GET /api/v1/games/{gameId}/board", server.handleGetGameBoard())
 	mux.Handle("GET /api/v1/games/{gameId}/board/placements", server.handleGetGameBoardPlacements())
 	mux.Handle("PATCH /api/v1/games/{gameId}/board", server.handleUpdateBoard())
+	mux.Handle("GET /api/v1/games/{gameId}/board.svg", server.handleGetBoardImage(server.gameFromPath, "image/svg+xml", render.SVG))
+	mux.Handle("GET /api/v1/games/{gameId}/board.png", server.handleGetBoardImage(server.gameFromPath, "image/png", render.PNG))
+	mux.Handle("GET /api/v1/games/{gameId}/replay.gif", server.handleGetReplay(server.gameFromPath))
+
+	// share links
+	mux.Handle("GET /api/v1/games/{gameId}/share-links", server.handleGetShareLinks())
+	mux.Handle("POST /api/v1/games/{gameId}/share-links", server.handleCreateShareLink())
+	mux.Handle("DELETE /api/v1/games/{gameId}/share-links/{linkId}", server.handleRevokeShareLink())
+
+	// shared, read-only views; anything else under /shared/ is refused
+	mux.Handle("GET /api/v1/shared/{token}", server.handleGetSharedGame())
+	mux.Handle("GET /api/v1/shared/{token}/board", server.handleGetSharedBoard())
+	mux.Handle("GET /api/v1/shared/{token}/history", server.handleGetSharedHistory())
+	mux.Handle("GET /api/v1/shared/{token}/board.svg", server.handleGetBoardImage(server.gameFromShareLink, "image/svg+xml", render.SVG))
+	mux.Handle("GET /api/v1/shared/{token}/board.png", server.handleGetBoardImage(server.gameFromShareLink, "image/png", render.PNG))
+	mux.Handle("GET /api/v1/shared/{token}/replay.gif", server.handleGetReplay(server.gameFromShareLink))
+	mux.Handle("GET /api/v1/shared/{token}/events", server.handleStreamSharedEvents())
+	mux.Handle("/api/v1/shared/", server.handleSharedFallback())
 
 	// events
 	mux.Handle("GET /api/v1/games/{gameId}/events", server.handleStreamGameEvents())
+	mux.Handle("GET /api/v1/games/{gameId}/ws", server.handleGameSocket())
+
+	// chat
+	mux.Handle("GET /api/v1/games/{gameId}/messages", server.handleGetGameMessages())
+
+	// admin
+	mux.Handle("GET /api/v1/admin/games", server.withAdmin(server.handleListGames()))
+	mux.Handle("GET /api/v1/admin/games/export", server.withAdmin(server.handleExportGames()))
+	mux.Handle("POST /api/v1/admin/games/import", server.withAdmin(server.handleImportGames()))
+	mux.Handle("POST /api/v1/admin/games/import.gcg", server.withAdmin(server.handleImportGCG()))
+	mux.Handle("GET /api/v1/admin/games/validate", server.withAdmin(server.handleValidateGames()))
 
 	// frontend
 	mux.Handle("/", http.FileServer(http.Dir(server.config.PublicDirectory)))
@@ -78,15 +116,16 @@ func (server *Server) Handler() http.Handler {
 }
 
 func (server *Server) withCORS(handler http.Handler) http.Handler {
-	methods := strings.Join([]string{http.MethodGet, http.MethodPost, http.MethodPatch}, ",")
+	methods := strings.Join([]string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete}, ",")
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", server.config.AllowedOrigin)
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag")
 
 		if r.Method == http.MethodOptions {
 			w.Header().Set("Access-Control-Allow-Methods", methods)
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, If-Match, If-None-Match")
 			w.WriteHeader(http.StatusNoContent)
 			return
 		}
@@ -95,11 +134,33 @@ func (server *Server) withCORS(handler http.Handler) http.Handler {
 	})
 }
 
-// playerIDCookie carries the player's identity for a specific game.
-const playerIDCookie = "playerId"
+const (
+	// playerIDCookie carries the player's identity for a specific game.
+	playerIDCookie = "playerId"
+	// spectatorIDCookie carries a named spectator's identity for a specific game.
+	spectatorIDCookie = "spectatorId"
+)
 
 func playerIDFromRequest(r *http.Request) (string, bool) {
-	cookie, err := r.Cookie(playerIDCookie)
+	return cookieValue(r, playerIDCookie)
+}
+
+func spectatorIDFromRequest(r *http.Request) (string, bool) {
+	return cookieValue(r, spectatorIDCookie)
+}
+
+// participantIDFromRequest identifies a player or, failing that, a named
+// spectator.
+func participantIDFromRequest(r *http.Request) (string, bool) {
+	if playerID, identified := playerIDFromRequest(r); identified {
+		return playerID, true
+	}
+
+	return spectatorIDFromRequest(r)
+}
+
+func cookieValue(r *http.Request, name string) (string, bool) {
+	cookie, err := r.Cookie(name)
 	if err != nil {
 		return "", false
 	}
@@ -107,6 +168,18 @@ func playerIDFromRequest(r *http.Request) (string, bool) {
 	return cookie.Value, true
 }
 
+// setIdentityCookie scopes an identity cookie to the game's path.
+func setIdentityCookie(w http.ResponseWriter, r *http.Request, name, value string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     r.URL.Path,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+		HttpOnly: true,
+	})
+}
+
 func parseRequestBody[T any](r *http.Request) (T, error) {
 	var body T
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
@@ -156,6 +229,12 @@ func statusForClass(class errcode.Class) int {
 		return http.StatusConflict
 	case errcode.ClassUnauthenticated:
 		return http.StatusUnauthorized
+	case errcode.ClassForbidden:
+		return http.StatusForbidden
+	case errcode.ClassRateLimited:
+		return http.StatusTooManyRequests
+	case errcode.ClassPreconditionFailed:
+		return http.StatusPreconditionFailed
 	default:
 		return http.StatusInternalServerError
 	}
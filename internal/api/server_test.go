@@ -1,18 +1,25 @@
 package api_test
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/carterjs/words/internal/api"
+	"github.com/carterjs/words/internal/lock"
 	"github.com/carterjs/words/internal/pubsub"
 	"github.com/carterjs/words/internal/store"
 	"github.com/carterjs/words/internal/words"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -25,6 +32,7 @@ func TestServer_Handler(t *testing.T) {
 		method     string
 		path       string
 		body       string
+		admin      bool
 		wantStatus int
 	}{
 		{name: "reports an unknown game", method: http.MethodGet, path: "/api/v1/games/nope", wantStatus: http.StatusNotFound},
@@ -32,15 +40,24 @@ func TestServer_Handler(t *testing.T) {
 		{name: "rejects an unknown operation", method: http.MethodPatch, path: "/api/v1/games/nope", body: `{"operation":"EXPLODE"}`, wantStatus: http.StatusBadRequest},
 		{name: "requires a player to pass", method: http.MethodPatch, path: "/api/v1/games/nope", body: `{"operation":"PASS_TURN"}`, wantStatus: http.StatusUnauthorized},
 		{name: "lists presets", method: http.MethodGet, path: "/api/v1/presets", wantStatus: http.StatusOK},
+		{name: "lists games", method: http.MethodGet, path: "/api/v1/admin/games?status=lobby&limit=5", admin: true, wantStatus: http.StatusOK},
+		{name: "lists games only to admins", method: http.MethodGet, path: "/api/v1/admin/games", wantStatus: http.StatusUnauthorized},
+		{name: "does not list games publicly", method: http.MethodGet, path: "/api/v1/games", wantStatus: http.StatusNotFound},
+		{name: "rejects an unknown game status", method: http.MethodGet, path: "/api/v1/admin/games?status=paused", admin: true, wantStatus: http.StatusBadRequest},
+		{name: "rejects a malformed time filter", method: http.MethodGet, path: "/api/v1/admin/games?createdAfter=yesterday", admin: true, wantStatus: http.StatusBadRequest},
+		{name: "rejects a malformed cursor", method: http.MethodGet, path: "/api/v1/admin/games?cursor=nope", admin: true, wantStatus: http.StatusBadRequest},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			server := newTestServer(t)
+			server := newAdminServer(t, "secret")
 
 			request := httptest.NewRequest(test.method, test.path, bytes.NewBufferString(test.body))
+			if test.admin {
+				request.Header.Set("Authorization", "Bearer secret")
+			}
 			recorder := httptest.NewRecorder()
 			server.Handler().ServeHTTP(recorder, request)
 
@@ -49,8 +66,61 @@ func TestServer_Handler(t *testing.T) {
 	}
 }
 
+func TestServer_Handler_ListGames(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		games     int
+		limit     int
+		wantPages int
+	}{
+		{name: "lists every game on one page", games: 2, limit: 5, wantPages: 1},
+		{name: "follows cursors across pages", games: 3, limit: 2, wantPages: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &apiClient{t: t, handler: newAdminServer(t, "secret").Handler(), adminToken: "secret"}
+
+			created := make(map[string]bool)
+			for range test.games {
+				game := client.do(http.MethodPost, "/api/v1/games", createGameBody(), "")
+				created[game["id"].(string)] = true
+			}
+
+			listed := make(map[string]bool)
+			path := fmt.Sprintf("/api/v1/admin/games?limit=%d", test.limit)
+
+			var pages int
+			for {
+				page := client.do(http.MethodGet, path, "", "")
+				pages++
+
+				for _, game := range page["games"].([]any) {
+					summary := game.(map[string]any)
+					assert.Equal(t, "LOBBY", summary["status"])
+					listed[summary["id"].(string)] = true
+				}
+
+				cursor, more := page["nextCursor"].(string)
+				if !more {
+					break
+				}
+				path = fmt.Sprintf("/api/v1/admin/games?limit=%d&cursor=%s", test.limit, cursor)
+			}
+
+			assert.Equal(t, created, listed)
+			assert.Equal(t, test.wantPages, pages)
+		})
+	}
+}
+
 // TestServer_Handler_Integration drives a full two-player game through the
-// HTTP API: create, join, start, play, and a successful challenge vote.
+// HTTP API: create, join, spectate, start, play, and a successful challenge
+// vote.
 func TestServer_Handler_Integration(t *testing.T) {
 	t.Parallel()
 
@@ -79,9 +149,13 @@ func TestServer_Handler_Integration(t *testing.T) {
 			second := client.do(http.MethodPatch, gamePath, joinBody, "")
 			secondID := second["playerId"].(string)
 
+			spectateBody := `{"operation":"SPECTATE_GAME","payload":{"spectatorName":"watcher"}}`
+			client.do(http.MethodPatch, gamePath, spectateBody, "")
+
 			client.do(http.MethodPatch, gamePath, `{"operation":"START_GAME"}`, firstID)
 
 			state := client.do(http.MethodGet, gamePath, "", firstID)
+			assert.Equal(t, float64(1), state["spectatorCount"])
 			mover := state["currentPlayerId"].(string)
 			opponent := secondID
 			if mover == secondID {
@@ -105,10 +179,661 @@ func TestServer_Handler_Integration(t *testing.T) {
 	}
 }
 
+func TestServer_Handler_Admin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		adminToken    string
+		authorization string
+		path          string
+		wantStatus    int
+	}{
+		{name: "exports with the admin token", adminToken: "secret", authorization: "Bearer secret", path: "/api/v1/admin/games/export", wantStatus: http.StatusOK},
+		{name: "refuses a wrong token", adminToken: "secret", authorization: "Bearer guess", path: "/api/v1/admin/games/export", wantStatus: http.StatusUnauthorized},
+		{name: "refuses a missing token", adminToken: "secret", path: "/api/v1/admin/games/export", wantStatus: http.StatusUnauthorized},
+		{name: "refuses everyone without a configured token", authorization: "Bearer ", path: "/api/v1/admin/games/export", wantStatus: http.StatusUnauthorized},
+		{name: "rejects an unknown format", adminToken: "secret", authorization: "Bearer secret", path: "/api/v1/admin/games/export?format=zip", wantStatus: http.StatusBadRequest},
+		{name: "validates games", adminToken: "secret", authorization: "Bearer secret", path: "/api/v1/admin/games/validate?status=running", wantStatus: http.StatusOK},
+		{name: "refuses validation without the token", adminToken: "secret", path: "/api/v1/admin/games/validate", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newAdminServer(t, test.adminToken)
+
+			request := httptest.NewRequest(http.MethodGet, test.path, nil)
+			if test.authorization != "" {
+				request.Header.Set("Authorization", test.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			server.Handler().ServeHTTP(recorder, request)
+
+			assert.Equal(t, test.wantStatus, recorder.Code)
+		})
+	}
+}
+
+func TestServer_Handler_ExportImport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		format string
+	}{
+		{name: "moves games as JSON lines", format: "jsonl"},
+		{name: "moves games as a tar", format: "tar"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			source := newAdminServer(t, "secret").Handler()
+			created := (&apiClient{t: t, handler: source}).do(http.MethodPost, "/api/v1/games", createGameBody(), "")
+
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/admin/games/export?format="+test.format, nil)
+			request.Header.Set("Authorization", "Bearer secret")
+			exported := httptest.NewRecorder()
+			source.ServeHTTP(exported, request)
+			require.Equal(t, http.StatusOK, exported.Code)
+
+			destination := newAdminServer(t, "secret").Handler()
+			request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/games/import?format="+test.format, exported.Body)
+			request.Header.Set("Authorization", "Bearer secret")
+			imported := httptest.NewRecorder()
+			destination.ServeHTTP(imported, request)
+			require.Equal(t, http.StatusOK, imported.Code, imported.Body.String())
+
+			var report words.ImportReport
+			require.NoError(t, json.Unmarshal(imported.Body.Bytes(), &report))
+			assert.Equal(t, []string{created["id"].(string)}, report.Imported)
+
+			game := (&apiClient{t: t, handler: destination}).do(http.MethodGet, "/api/v1/games/"+created["id"].(string), "", "")
+			assert.Equal(t, created["id"], game["id"])
+		})
+	}
+}
+
+func TestServer_Handler_GCG(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		passes           int
+		preset           string
+		path             string
+		token            string
+		oversized        bool
+		earlierImports   int
+		wantImportStatus int
+		wantExportStatus int
+	}{
+		{name: "imports and exports a finished game", passes: 4, preset: "standard", wantImportStatus: http.StatusCreated, wantExportStatus: http.StatusOK},
+		{name: "keeps a game in play private", passes: 1, preset: "standard", wantImportStatus: http.StatusCreated, wantExportStatus: http.StatusConflict},
+		{name: "rejects an unknown preset", passes: 4, preset: "missing", wantImportStatus: http.StatusNotFound},
+		{name: "rejects notation over the size limit", passes: 4, preset: "standard", oversized: true, wantImportStatus: http.StatusBadRequest},
+		{name: "refuses imports past the rate limit", passes: 4, preset: "standard", earlierImports: 10, wantImportStatus: http.StatusTooManyRequests},
+		{name: "imports through the admin route", passes: 4, preset: "standard", path: "/api/v1/admin/games/import.gcg", token: "secret", wantImportStatus: http.StatusCreated, wantExportStatus: http.StatusOK},
+		{name: "refuses an admin import without the admin token", passes: 4, preset: "standard", path: "/api/v1/admin/games/import.gcg", wantImportStatus: http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			preset, exists := words.PresetByID("standard")
+			require.True(t, exists)
+
+			played := words.NewGame(preset.Config)
+			for _, name := range []string{"one", "two"} {
+				_, err := played.AddPlayer(name)
+				require.NoError(t, err)
+			}
+			require.NoError(t, played.Start())
+			for range test.passes {
+				require.NoError(t, played.PassTurn(played.CurrentPlayerID()))
+			}
+
+			var notation strings.Builder
+			require.NoError(t, played.WriteGCG(&notation))
+			if test.oversized {
+				notation.WriteString(strings.Repeat("#note padding\n", 100_000))
+			}
+
+			handler := newAdminServer(t, "secret").Handler()
+			for range test.earlierImports {
+				earlier := httptest.NewRecorder()
+				handler.ServeHTTP(earlier, httptest.NewRequest(http.MethodPost, "/api/v1/games/import?preset="+test.preset, strings.NewReader(notation.String())))
+				require.Equal(t, http.StatusCreated, earlier.Code, earlier.Body.String())
+			}
+
+			path := test.path
+			if path == "" {
+				path = "/api/v1/games/import"
+			}
+			request := httptest.NewRequest(http.MethodPost, path+"?preset="+test.preset, strings.NewReader(notation.String()))
+			if test.token != "" {
+				request.Header.Set("Authorization", "Bearer "+test.token)
+			}
+			imported := httptest.NewRecorder()
+			handler.ServeHTTP(imported, request)
+			require.Equal(t, test.wantImportStatus, imported.Code, imported.Body.String())
+			if test.wantImportStatus != http.StatusCreated {
+				return
+			}
+
+			var game map[string]any
+			require.NoError(t, json.Unmarshal(imported.Body.Bytes(), &game))
+
+			exported := httptest.NewRecorder()
+			handler.ServeHTTP(exported, httptest.NewRequest(http.MethodGet, "/api/v1/games/"+game["id"].(string)+"/export.gcg", nil))
+			require.Equal(t, test.wantExportStatus, exported.Code, exported.Body.String())
+			if test.wantExportStatus == http.StatusOK {
+				assert.Contains(t, exported.Body.String(), "#player2 two two")
+			}
+		})
+	}
+}
+
+func TestServer_Handler_Transcript(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		query           string
+		wantStatus      int
+		wantContentType string
+	}{
+		{name: "writes a plain-text transcript", query: "?boardEvery=2", wantStatus: http.StatusOK, wantContentType: "text/plain; charset=utf-8"},
+		{name: "writes a JSON Lines transcript", query: "?format=jsonl", wantStatus: http.StatusOK, wantContentType: "application/jsonl"},
+		{name: "rejects an unknown format", query: "?format=pdf", wantStatus: http.StatusBadRequest},
+		{name: "rejects a negative board interval", query: "?boardEvery=-1", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			preset, exists := words.PresetByID("standard")
+			require.True(t, exists)
+
+			played := words.NewGame(preset.Config)
+			for _, name := range []string{"one", "two"} {
+				_, err := played.AddPlayer(name)
+				require.NoError(t, err)
+			}
+			require.NoError(t, played.Start())
+			for !played.Finished() {
+				require.NoError(t, played.PassTurn(played.CurrentPlayerID()))
+			}
+
+			var notation strings.Builder
+			require.NoError(t, played.WriteGCG(&notation))
+
+			handler := newTestServer(t).Handler()
+			imported := httptest.NewRecorder()
+			handler.ServeHTTP(imported, httptest.NewRequest(http.MethodPost, "/api/v1/games/import?preset=standard", strings.NewReader(notation.String())))
+			require.Equal(t, http.StatusCreated, imported.Code, imported.Body.String())
+
+			var game map[string]any
+			require.NoError(t, json.Unmarshal(imported.Body.Bytes(), &game))
+
+			transcript := httptest.NewRecorder()
+			handler.ServeHTTP(transcript, httptest.NewRequest(http.MethodGet, "/api/v1/games/"+game["id"].(string)+"/transcript"+test.query, nil))
+			require.Equal(t, test.wantStatus, transcript.Code, transcript.Body.String())
+			if test.wantStatus == http.StatusOK {
+				assert.Equal(t, test.wantContentType, transcript.Header().Get("Content-Type"))
+				assert.Contains(t, transcript.Body.String(), "two")
+			}
+		})
+	}
+}
+
+func TestServer_Handler_BoardImages(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		path            string
+		wantStatus      int
+		wantContentType string
+	}{
+		{name: "draws the board as SVG", path: "/board.svg", wantStatus: http.StatusOK, wantContentType: "image/svg+xml"},
+		{name: "draws a window of the board as PNG", path: "/board.png?minX=-3&minY=-3&maxX=3&maxY=3", wantStatus: http.StatusOK, wantContentType: "image/png"},
+		{name: "animates the moves as a GIF", path: "/replay.gif", wantStatus: http.StatusOK, wantContentType: "image/gif"},
+		{name: "rejects an inverted window", path: "/board.png?minX=3&maxX=-3", wantStatus: http.StatusBadRequest},
+		{name: "rejects a window too large to draw", path: "/board.svg?minX=-500&maxX=500", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newTestServer(t).Handler()
+			client := &apiClient{t: t, handler: handler}
+			created := client.do(http.MethodPost, "/api/v1/games", createGameBody(), "")
+
+			image := httptest.NewRecorder()
+			handler.ServeHTTP(image, httptest.NewRequest(http.MethodGet, "/api/v1/games/"+created["id"].(string)+test.path, nil))
+			require.Equal(t, test.wantStatus, image.Code, image.Body.String())
+			if test.wantStatus == http.StatusOK {
+				assert.Equal(t, test.wantContentType, image.Header().Get("Content-Type"))
+				assert.NotEmpty(t, image.Header().Get("ETag"))
+				assert.NotZero(t, image.Body.Len())
+			}
+		})
+	}
+}
+
+func TestServer_Handler_ShareLinks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		revoke     bool
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "shows the game without identities or racks", method: http.MethodGet, path: "/api/v1/shared/{token}", wantStatus: http.StatusOK, wantBody: `"name":"one"`},
+		{name: "shows the board", method: http.MethodGet, path: "/api/v1/shared/{token}/board", wantStatus: http.StatusOK, wantBody: `"letter":"A"`},
+		{name: "shows the history", method: http.MethodGet, path: "/api/v1/shared/{token}/history", wantStatus: http.StatusOK, wantBody: `"word":"AA"`},
+		{name: "draws the board", method: http.MethodGet, path: "/api/v1/shared/{token}/board.svg", wantStatus: http.StatusOK, wantBody: "<svg"},
+		{name: "refuses a revoked link", method: http.MethodGet, path: "/api/v1/shared/{token}", revoke: true, wantStatus: http.StatusNotFound},
+		{name: "refuses an unknown view", method: http.MethodGet, path: "/api/v1/shared/{token}/messages", wantStatus: http.StatusNotFound},
+		{name: "refuses to update the game", method: http.MethodPatch, path: "/api/v1/shared/{token}", wantStatus: http.StatusForbidden},
+		{name: "refuses to play a word", method: http.MethodPatch, path: "/api/v1/shared/{token}/board", wantStatus: http.StatusForbidden},
+		{name: "refuses to delete anything", method: http.MethodDelete, path: "/api/v1/shared/{token}", wantStatus: http.StatusForbidden},
+		{name: "does not stand in for the game ID", method: http.MethodGet, path: "/api/v1/games/{token}", wantStatus: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newTestServer(t).Handler()
+			client := &apiClient{t: t, handler: handler}
+
+			created := client.do(http.MethodPost, "/api/v1/games", createGameBody(), "")
+			gameID := created["id"].(string)
+			gamePath := "/api/v1/games/" + gameID
+
+			player := client.do(http.MethodPatch, gamePath, `{"operation":"JOIN_GAME","payload":{"playerName":"one"}}`, "")
+			playerID := player["playerId"].(string)
+			client.do(http.MethodPatch, gamePath, `{"operation":"START_GAME"}`, playerID)
+			client.do(http.MethodPatch, gamePath+"/board", `{"operation":"ADD_WORD","payload":{"x":0,"y":0,"direction":"HORIZONTAL","word":"AA"}}`, playerID)
+
+			link := client.do(http.MethodPost, gamePath+"/share-links", `{"lifetimeSeconds":3600}`, playerID)
+			token := link["token"].(string)
+			assert.Equal(t, "/api/v1/shared/"+token, link["path"])
+
+			if test.revoke {
+				revoked := httptest.NewRecorder()
+				request := httptest.NewRequest(http.MethodDelete, gamePath+"/share-links/"+link["id"].(string), nil)
+				request.AddCookie(&http.Cookie{Name: "playerId", Value: playerID})
+				handler.ServeHTTP(revoked, request)
+				require.Equal(t, http.StatusNoContent, revoked.Code, revoked.Body.String())
+			}
+
+			body := `{"operation":"ADD_WORD","payload":{"x":0,"y":1,"direction":"HORIZONTAL","word":"AA"}}`
+			response := httptest.NewRecorder()
+			handler.ServeHTTP(response, httptest.NewRequest(test.method, strings.ReplaceAll(test.path, "{token}", token), strings.NewReader(body)))
+			require.Equal(t, test.wantStatus, response.Code, response.Body.String())
+
+			assert.Contains(t, response.Body.String(), test.wantBody)
+			assert.NotContains(t, response.Body.String(), gameID)
+			assert.NotContains(t, response.Body.String(), playerID)
+			assert.NotContains(t, response.Body.String(), "rack")
+		})
+	}
+}
+
+func TestServer_Handler_SharedEvents(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		revoke     bool
+		wantStatus int
+		wantEvents []string
+	}{
+		{
+			name:       "streams play by seat without chat or spectators",
+			wantStatus: http.StatusOK,
+			wantEvents: []string{"event: GAME_STARTED", "event: WORD_PLAYED"},
+		},
+		{name: "refuses a revoked link", revoke: true, wantStatus: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newTestServer(t).Handler()
+			client := &apiClient{t: t, handler: handler}
+
+			created := client.do(http.MethodPost, "/api/v1/games", createGameBody(), "")
+			gamePath := "/api/v1/games/" + created["id"].(string)
+
+			player := client.do(http.MethodPatch, gamePath, `{"operation":"JOIN_GAME","payload":{"playerName":"one"}}`, "")
+			playerID := player["playerId"].(string)
+
+			link := client.do(http.MethodPost, gamePath+"/share-links", "", playerID)
+			if test.revoke {
+				revoked := httptest.NewRecorder()
+				request := httptest.NewRequest(http.MethodDelete, gamePath+"/share-links/"+link["id"].(string), nil)
+				request.AddCookie(&http.Cookie{Name: "playerId", Value: playerID})
+				handler.ServeHTTP(revoked, request)
+				require.Equal(t, http.StatusNoContent, revoked.Code, revoked.Body.String())
+			}
+
+			httpServer := httptest.NewServer(handler)
+			defer httpServer.Close()
+
+			request, err := http.NewRequestWithContext(t.Context(), http.MethodGet, httpServer.URL+link["path"].(string)+"/events", nil)
+			require.NoError(t, err)
+
+			response, err := httpServer.Client().Do(request)
+			require.NoError(t, err)
+			defer response.Body.Close()
+			require.Equal(t, test.wantStatus, response.StatusCode)
+			if test.wantStatus != http.StatusOK {
+				return
+			}
+
+			spectator := client.do(http.MethodPatch, gamePath, `{"operation":"SPECTATE_GAME","payload":{"spectatorName":"watcher"}}`, "")
+			client.do(http.MethodPatch, gamePath, `{"operation":"START_GAME"}`, playerID)
+			client.do(http.MethodPatch, gamePath, `{"operation":"SEND_MESSAGE","payload":{"text":"hello"}}`, playerID)
+			client.do(http.MethodPatch, gamePath+"/board", `{"operation":"ADD_WORD","payload":{"x":0,"y":0,"direction":"HORIZONTAL","word":"AA"}}`, playerID)
+
+			var (
+				events []string
+				stream strings.Builder
+			)
+			scanner := bufio.NewScanner(response.Body)
+			for len(events) < len(test.wantEvents) && scanner.Scan() {
+				line := scanner.Text()
+				stream.WriteString(line + "\n")
+				if strings.HasPrefix(line, "event: ") {
+					events = append(events, line)
+				}
+			}
+			require.True(t, scanner.Scan(), "the last event has data")
+			stream.WriteString(scanner.Text())
+
+			assert.Equal(t, test.wantEvents, events)
+			assert.Contains(t, stream.String(), `"seat":0`)
+			assert.Contains(t, stream.String(), `"word":"AA"`)
+			assert.NotContains(t, stream.String(), playerID)
+			assert.NotContains(t, stream.String(), spectator["spectatorId"].(string))
+		})
+	}
+}
+
+func TestServer_Handler_CreateShareLink(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		anonymous  bool
+		wantStatus int
+	}{
+		{name: "creates a link lasting the default lifetime", wantStatus: http.StatusCreated},
+		{name: "creates a link lasting the lifetime asked for", body: `{"lifetimeSeconds":60}`, wantStatus: http.StatusCreated},
+		{name: "refuses a lifetime over the limit", body: `{"lifetimeSeconds":99999999}`, wantStatus: http.StatusBadRequest},
+		{name: "refuses a malformed body", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "refuses someone without a player identity", anonymous: true, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newTestServer(t).Handler()
+			client := &apiClient{t: t, handler: handler}
+
+			created := client.do(http.MethodPost, "/api/v1/games", createGameBody(), "")
+			gamePath := "/api/v1/games/" + created["id"].(string)
+			player := client.do(http.MethodPatch, gamePath, `{"operation":"JOIN_GAME","payload":{"playerName":"one"}}`, "")
+
+			request := httptest.NewRequest(http.MethodPost, gamePath+"/share-links", strings.NewReader(test.body))
+			if !test.anonymous {
+				request.AddCookie(&http.Cookie{Name: "playerId", Value: player["playerId"].(string)})
+			}
+
+			response := httptest.NewRecorder()
+			handler.ServeHTTP(response, request)
+			require.Equal(t, test.wantStatus, response.Code, response.Body.String())
+
+			if test.wantStatus == http.StatusCreated {
+				listRequest := httptest.NewRequest(http.MethodGet, gamePath+"/share-links", nil)
+				listRequest.AddCookie(&http.Cookie{Name: "playerId", Value: player["playerId"].(string)})
+				listed := httptest.NewRecorder()
+				handler.ServeHTTP(listed, listRequest)
+				require.Equal(t, http.StatusOK, listed.Code, listed.Body.String())
+
+				var links []map[string]any
+				require.NoError(t, json.Unmarshal(listed.Body.Bytes(), &links))
+				require.Len(t, links, 1)
+				assert.Equal(t, false, links[0]["revoked"])
+				assert.NotContains(t, links[0], "token", "listings never reveal tokens")
+				assert.NotEmpty(t, links[0]["id"])
+			}
+		})
+	}
+}
+
+func TestServer_Handler_Conditional(t *testing.T) {
+	t.Parallel()
+
+	const (
+		spectate = `{"operation":"SPECTATE_GAME","payload":{"spectatorName":"watcher"}}`
+		join     = `{"operation":"JOIN_GAME","payload":{"playerName":"three"}}`
+	)
+
+	tests := []struct {
+		name       string
+		method     string
+		header     string
+		etag       func(current string) string
+		between    string
+		viewer     string
+		wantStatus int
+	}{
+		{name: "reports an unchanged game as not modified", method: http.MethodGet, header: "If-None-Match", etag: func(current string) string { return current }, wantStatus: http.StatusNotModified},
+		{name: "returns a changed game", method: http.MethodGet, header: "If-None-Match", etag: func(string) string { return `"0"` }, wantStatus: http.StatusOK},
+		{name: "returns a game that only gained a spectator", method: http.MethodGet, header: "If-None-Match", etag: func(current string) string { return current }, between: spectate, wantStatus: http.StatusOK},
+		{name: "returns another viewer's copy in full", method: http.MethodGet, header: "If-None-Match", etag: func(current string) string { return current }, viewer: "someone", wantStatus: http.StatusOK},
+		{name: "applies an update to the current version", method: http.MethodPatch, header: "If-Match", etag: func(current string) string { return current }, wantStatus: http.StatusCreated},
+		{name: "applies an update when only spectators changed", method: http.MethodPatch, header: "If-Match", etag: func(current string) string { return current }, between: spectate, wantStatus: http.StatusCreated},
+		{name: "refuses an update after play changed", method: http.MethodPatch, header: "If-Match", etag: func(current string) string { return current }, between: join, wantStatus: http.StatusPreconditionFailed},
+		{name: "refuses an update to a stale version", method: http.MethodPatch, header: "If-Match", etag: func(string) string { return `"1"` }, wantStatus: http.StatusPreconditionFailed},
+		{name: "applies an update to any match in a list", method: http.MethodPatch, header: "If-Match", etag: func(current string) string { return `"1-1-0", ` + current }, wantStatus: http.StatusCreated},
+		{name: "applies an update to any version", method: http.MethodPatch, header: "If-Match", etag: func(string) string { return "*" }, between: join, wantStatus: http.StatusCreated},
+		{name: "refuses an update to a weak tag", method: http.MethodPatch, header: "If-Match", etag: func(current string) string { return "W/" + current }, wantStatus: http.StatusPreconditionFailed},
+		{name: "refuses an update to a tag the server never issued", method: http.MethodPatch, header: "If-Match", etag: func(string) string { return `"2"` }, wantStatus: http.StatusPreconditionFailed},
+		{name: "rejects a malformed version", method: http.MethodPatch, header: "If-Match", etag: func(string) string { return "latest" }, wantStatus: http.StatusBadRequest},
+		{name: "rejects an unterminated tag", method: http.MethodPatch, header: "If-Match", etag: func(current string) string { return current + `, "2-` }, wantStatus: http.StatusBadRequest},
+		{name: "reports a weak copy of the tag as not modified", method: http.MethodGet, header: "If-None-Match", etag: func(current string) string { return `"0", W/` + current }, wantStatus: http.StatusNotModified},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newTestServer(t).Handler()
+			client := &apiClient{t: t, handler: handler}
+
+			created := client.do(http.MethodPost, "/api/v1/games", createGameBody(), "")
+			gamePath := "/api/v1/games/" + created["id"].(string)
+			client.do(http.MethodPatch, gamePath, `{"operation":"JOIN_GAME","payload":{"playerName":"one"}}`, "")
+
+			current := httptest.NewRecorder()
+			handler.ServeHTTP(current, httptest.NewRequest(http.MethodGet, gamePath, nil))
+			require.True(t, strings.HasPrefix(current.Header().Get("ETag"), `"2-2-`), current.Header().Get("ETag"))
+			assert.Equal(t, "Cookie", current.Header().Get("Vary"))
+
+			if test.between != "" {
+				client.do(http.MethodPatch, gamePath, test.between, "")
+			}
+
+			var body io.Reader
+			if test.method == http.MethodPatch {
+				body = strings.NewReader(`{"operation":"JOIN_GAME","payload":{"playerName":"two"}}`)
+			}
+
+			request := httptest.NewRequest(test.method, gamePath, body)
+			request.Header.Set(test.header, test.etag(current.Header().Get("ETag")))
+			if test.viewer != "" {
+				request.AddCookie(&http.Cookie{Name: "playerId", Value: test.viewer})
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, test.wantStatus, recorder.Code, recorder.Body.String())
+		})
+	}
+}
+
+func TestServer_Handler_StreamResume(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		wantFirst   []string
+	}{
+		{name: "replays events after Last-Event-ID", lastEventID: "1", wantFirst: []string{"id: 2", "event: PLAYER_JOINED"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newTestServer(t).Handler()
+			client := &apiClient{t: t, handler: handler}
+
+			created := client.do(http.MethodPost, "/api/v1/games", createGameBody(), "")
+			gamePath := "/api/v1/games/" + created["id"].(string)
+
+			joinBody := `{"operation":"JOIN_GAME","payload":{"playerName":"one"}}`
+			client.do(http.MethodPatch, gamePath, joinBody, "")
+			joinBody = `{"operation":"JOIN_GAME","payload":{"playerName":"two"}}`
+			client.do(http.MethodPatch, gamePath, joinBody, "")
+
+			httpServer := httptest.NewServer(handler)
+			defer httpServer.Close()
+
+			request, err := http.NewRequestWithContext(t.Context(), http.MethodGet, httpServer.URL+gamePath+"/events", nil)
+			require.NoError(t, err)
+			request.Header.Set("Last-Event-ID", test.lastEventID)
+
+			response, err := httpServer.Client().Do(request)
+			require.NoError(t, err)
+			defer response.Body.Close()
+
+			scanner := bufio.NewScanner(response.Body)
+			for _, want := range test.wantFirst {
+				require.True(t, scanner.Scan())
+				assert.Equal(t, want, scanner.Text())
+			}
+		})
+	}
+}
+
+func TestServer_Handler_Socket(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		commands   []string
+		wantStatus []int
+		// wantLetters expects the player's private GAME_STARTED event
+		wantLetters bool
+	}{
+		{
+			name:        "joins and then acts as the new player",
+			commands:    []string{`{"operation":"JOIN_GAME","payload":{"playerName":"one"}}`, `{"operation":"START_GAME"}`},
+			wantStatus:  []int{http.StatusCreated, http.StatusOK},
+			wantLetters: true,
+		},
+		{
+			name:       "applies the same identity checks as the routes",
+			commands:   []string{`{"operation":"START_GAME"}`},
+			wantStatus: []int{http.StatusBadRequest},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newTestServer(t).Handler()
+			client := &apiClient{t: t, handler: handler}
+
+			created := client.do(http.MethodPost, "/api/v1/games", createGameBody(), "")
+			gamePath := "/api/v1/games/" + created["id"].(string)
+
+			httpServer := httptest.NewServer(handler)
+			defer httpServer.Close()
+
+			conn, _, err := websocket.Dial(t.Context(), "ws"+strings.TrimPrefix(httpServer.URL, "http")+gamePath+"/ws", nil)
+			require.NoError(t, err)
+			defer conn.CloseNow()
+
+			var gotLetters bool
+			for index, command := range test.commands {
+				id := strconv.Itoa(index)
+				require.NoError(t, conn.Write(t.Context(), websocket.MessageText, []byte(`{"id":"`+id+`",`+command[1:])))
+
+				for {
+					var message socketMessage
+					require.NoError(t, wsjson.Read(t.Context(), conn, &message))
+
+					if message.Kind == "event" {
+						gotLetters = gotLetters || len(message.Event.Payload.Letters) > 0
+						continue
+					}
+
+					assert.Equal(t, id, message.ID)
+					assert.Equal(t, test.wantStatus[index], message.Status)
+					break
+				}
+			}
+
+			for test.wantLetters && !gotLetters {
+				var message socketMessage
+				require.NoError(t, wsjson.Read(t.Context(), conn, &message))
+				gotLetters = len(message.Event.Payload.Letters) > 0
+			}
+		})
+	}
+}
+
+// socketMessage is the subset of a game socket message the tests inspect.
+type socketMessage struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Status int    `json:"status"`
+	Event  struct {
+		Type    string `json:"type"`
+		Payload struct {
+			Letters []string `json:"letters"`
+		} `json:"payload"`
+	} `json:"event"`
+}
+
 // apiClient drives the handler with per-request player cookies.
 type apiClient struct {
-	t       *testing.T
-	handler http.Handler
+	t          *testing.T
+	handler    http.Handler
+	adminToken string
 }
 
 func (client *apiClient) do(method, path, body, playerID string) map[string]any {
@@ -118,6 +843,9 @@ func (client *apiClient) do(method, path, body, playerID string) map[string]any
 	if playerID != "" {
 		request.AddCookie(&http.Cookie{Name: "playerId", Value: playerID})
 	}
+	if client.adminToken != "" {
+		request.Header.Set("Authorization", "Bearer "+client.adminToken)
+	}
 
 	recorder := httptest.NewRecorder()
 	client.handler.ServeHTTP(recorder, request)
@@ -132,11 +860,20 @@ func (client *apiClient) do(method, path, body, playerID string) map[string]any
 func newTestServer(t *testing.T) *api.Server {
 	t.Helper()
 
-	service := words.NewService(store.NewFS(t.TempDir()), pubsub.NewGameBroker(), slog.New(slog.DiscardHandler))
+	service := words.NewService(store.NewFS(t.TempDir(), slog.New(slog.DiscardHandler)), pubsub.NewGameBroker(pubsub.Config{}), lock.NewLocal(), slog.New(slog.DiscardHandler))
 
 	return api.NewServer(service, slog.New(slog.DiscardHandler), api.Config{PublicDirectory: t.TempDir()})
 }
 
+// newAdminServer returns a test server accepting the given admin token.
+func newAdminServer(t *testing.T, adminToken string) *api.Server {
+	t.Helper()
+
+	service := words.NewService(store.NewFS(t.TempDir(), slog.New(slog.DiscardHandler)), pubsub.NewGameBroker(pubsub.Config{}), lock.NewLocal(), slog.New(slog.DiscardHandler))
+
+	return api.NewServer(service, slog.New(slog.DiscardHandler), api.Config{PublicDirectory: t.TempDir(), AdminToken: adminToken})
+}
+
 // createGameBody zeroes out every standard letter except A so racks and
 // plays are deterministic.
 func createGameBody() string {
@@ -0,0 +1,502 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/carterjs/words/internal/errcode"
+	"github.com/carterjs/words/internal/words"
+)
+
+type (
+	// shareLinkResponse describes a share link. Only the response creating
+	// the link carries its token and path; listings identify links by ID,
+	// which revokes them but does not open the game.
+	shareLinkResponse struct {
+		ID        string    `json:"id"`
+		Token     string    `json:"token,omitempty"`
+		Path      string    `json:"path,omitempty"`
+		CreatedAt time.Time `json:"createdAt"`
+		ExpiresAt time.Time `json:"expiresAt"`
+		Revoked   bool      `json:"revoked"`
+	}
+
+	// sharedGameResponse is the read-only view a share link opens. Players
+	// appear by seat and name only: their IDs identify them to the API, so
+	// they are never shared, and neither are their racks.
+	sharedGameResponse struct {
+		Version          uint64                 `json:"version"`
+		Started          bool                   `json:"started"`
+		Finished         bool                   `json:"finished"`
+		Round            int                    `json:"round"`
+		CurrentSeat      *int                   `json:"currentSeat,omitempty"`
+		LettersRemaining int                    `json:"lettersRemaining"`
+		Players          []sharedPlayerResponse `json:"players"`
+		LetterPoints     map[string]int         `json:"letterPoints"`
+	}
+
+	sharedPlayerResponse struct {
+		Seat   int    `json:"seat"`
+		Name   string `json:"name"`
+		Score  int    `json:"score"`
+		Winner bool   `json:"winner,omitempty"`
+	}
+
+	// sharedMoveResponse is one entry of a shared game's history. Exchanged
+	// letters stay private; only letters left on racks at the end are shown.
+	sharedMoveResponse struct {
+		Move        int                       `json:"move"`
+		Type        words.MoveType            `json:"type"`
+		Seat        int                       `json:"seat"`
+		Word        string                    `json:"word,omitempty"`
+		Position    *words.TranscriptPosition `json:"position,omitempty"`
+		WordsFormed []string                  `json:"wordsFormed,omitempty"`
+		Letters     []string                  `json:"letters,omitempty"`
+		Points      int                       `json:"points"`
+		Totals      []int                     `json:"totals"`
+	}
+
+	// The payloads of a shared event stream name players by seat, never by
+	// ID. Seats are missing for players the stream does not know.
+	sharedPlayerJoinedPayload struct {
+		Seat int    `json:"seat"`
+		Name string `json:"name"`
+	}
+
+	sharedTurnPayload struct {
+		Seat      *int            `json:"seat,omitempty"`
+		X         *int            `json:"x,omitempty"`
+		Y         *int            `json:"y,omitempty"`
+		Direction words.Direction `json:"direction,omitempty"`
+		Word      string          `json:"word,omitempty"`
+		Points    int             `json:"points"`
+		Count     int             `json:"count,omitempty"`
+		NextSeat  *int            `json:"nextSeat,omitempty"`
+		Round     int             `json:"round"`
+	}
+
+	sharedChallengePayload struct {
+		Upheld         *bool  `json:"upheld,omitempty"`
+		ChallengerSeat *int   `json:"challengerSeat,omitempty"`
+		MoverSeat      *int   `json:"moverSeat,omitempty"`
+		VoterSeat      *int   `json:"voterSeat,omitempty"`
+		VotesInvalid   int    `json:"votesInvalid"`
+		VotesValid     int    `json:"votesValid"`
+		VotesNeeded    int    `json:"votesNeeded,omitempty"`
+		EligibleVoters int    `json:"eligibleVoters,omitempty"`
+		RescindedWord  string `json:"rescindedWord,omitempty"`
+	}
+
+	sharedGameEndedPayload struct {
+		WinnerSeats []int `json:"winnerSeats"`
+		Scores      []int `json:"scores"`
+	}
+)
+
+func (server *Server) handleCreateShareLink() http.HandlerFunc {
+	type requestBody struct {
+		LifetimeSeconds int `json:"lifetimeSeconds"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		playerID, identified := playerIDFromRequest(r)
+		if !identified {
+			server.respondWithCode(w, errcode.MissingPlayer)
+			return
+		}
+
+		// the body is optional; without one the link lasts the default lifetime
+		body, err := parseRequestBody[requestBody](r)
+		if err != nil && !errors.Is(err, io.EOF) {
+			server.respondWithCode(w, errcode.BadRequest)
+			return
+		}
+
+		link, err := server.service.CreateShareLink(r.Context(), r.PathValue("gameId"), playerID, time.Duration(body.LifetimeSeconds)*time.Second)
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		server.respondWithJSON(w, http.StatusCreated, constructShareLinkResponse(link))
+	}
+}
+
+func (server *Server) handleGetShareLinks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		playerID, identified := playerIDFromRequest(r)
+		if !identified {
+			server.respondWithCode(w, errcode.MissingPlayer)
+			return
+		}
+
+		links, err := server.service.ShareLinks(r.Context(), r.PathValue("gameId"), playerID)
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		responses := make([]shareLinkResponse, 0, len(links))
+		for _, link := range links {
+			responses = append(responses, constructShareLinkResponse(link))
+		}
+
+		server.respondWithJSON(w, http.StatusOK, responses)
+	}
+}
+
+func (server *Server) handleRevokeShareLink() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		playerID, identified := playerIDFromRequest(r)
+		if !identified {
+			server.respondWithCode(w, errcode.MissingPlayer)
+			return
+		}
+
+		if err := server.service.RevokeShareLink(r.Context(), r.PathValue("gameId"), playerID, r.PathValue("linkId")); err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func constructShareLinkResponse(link words.ShareLink) shareLinkResponse {
+	response := shareLinkResponse{
+		ID:        link.ID,
+		Token:     link.Token,
+		CreatedAt: link.CreatedAt,
+		ExpiresAt: link.ExpiresAt,
+		Revoked:   link.Revoked,
+	}
+	if link.Token != "" {
+		response.Path = "/api/v1/shared/" + link.Token
+	}
+
+	return response
+}
+
+// gameFromShareLink loads the game the request's share token opens.
+func (server *Server) gameFromShareLink(r *http.Request) (*words.Game, error) {
+	return server.service.SharedGame(r.Context(), r.PathValue("token"))
+}
+
+func (server *Server) handleGetSharedGame() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		game, err := server.gameFromShareLink(r)
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		if notModified(w, r, game) {
+			return
+		}
+
+		server.respondWithJSON(w, http.StatusOK, constructSharedGameResponse(game))
+	}
+}
+
+func (server *Server) handleGetSharedBoard() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		game, err := server.gameFromShareLink(r)
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		if notModified(w, r, game) {
+			return
+		}
+
+		area := parseExtents(r, extentsCovering(game.Board().Bounds()))
+
+		server.respondWithJSON(w, http.StatusOK, boardResponse{
+			Cells: boardCells(game.Board(), area),
+		})
+	}
+}
+
+func (server *Server) handleGetSharedHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		game, err := server.gameFromShareLink(r)
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		if notModified(w, r, game) {
+			return
+		}
+
+		entries, err := game.Transcript()
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		players := game.Players()
+		responses := make([]sharedMoveResponse, 0, len(entries))
+		for _, entry := range entries {
+			response := sharedMoveResponse{
+				Move:        entry.Move,
+				Type:        entry.Type,
+				Seat:        seatOf(players, entry.PlayerID),
+				Word:        entry.Word,
+				Position:    entry.Position,
+				WordsFormed: entry.WordsFormed,
+				Points:      entry.Points,
+				Totals:      make([]int, len(players)),
+			}
+			if entry.Type == words.MoveTypeEndRack {
+				response.Letters = entry.Letters
+			}
+			for seat, player := range players {
+				response.Totals[seat] = entry.Totals[player.ID()]
+			}
+
+			responses = append(responses, response)
+		}
+
+		server.respondWithJSON(w, http.StatusOK, responses)
+	}
+}
+
+// handleStreamSharedEvents streams a shared game's public events by seat.
+func (server *Server) handleStreamSharedEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lastEventID, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+
+		game, subscription, err := server.service.SubscribeShared(r.Context(), r.PathValue("token"), lastEventID)
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+		defer subscription.Close()
+
+		streamEvents(w, r, subscription, newSharedEventRedactor(game).redact)
+	}
+}
+
+// handleSharedFallback answers every request under /api/v1/shared/ that no
+// read-only route matched: lookups of anything else are not found, and
+// every change is refused, since share links never modify a game.
+func (server *Server) handleSharedFallback() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			server.respondWithCode(w, errcode.ShareLinkNotFound)
+			return
+		}
+
+		server.respondWithCode(w, errcode.ShareLinkReadOnly)
+	}
+}
+
+func constructSharedGameResponse(game *words.Game) sharedGameResponse {
+	players := game.Players()
+
+	response := sharedGameResponse{
+		Version:          game.Version(),
+		Started:          game.Started(),
+		Finished:         game.Finished(),
+		Round:            game.Round(),
+		LettersRemaining: game.LettersRemaining(),
+		Players:          make([]sharedPlayerResponse, 0, len(players)),
+		LetterPoints:     make(map[string]int, len(game.Config().LetterPoints)),
+	}
+
+	if game.Started() && !game.Finished() {
+		seat := seatOf(players, game.CurrentPlayerID())
+		response.CurrentSeat = &seat
+	}
+
+	for seat, player := range players {
+		response.Players = append(response.Players, sharedPlayerResponse{
+			Seat:   seat,
+			Name:   player.Name(),
+			Score:  player.Score(),
+			Winner: slices.Contains(game.WinnerIDs(), player.ID()),
+		})
+	}
+
+	for letter, points := range game.Config().LetterPoints {
+		response.LetterPoints[string(letter)] = points
+	}
+
+	return response
+}
+
+// seatOf returns the player's position in play order, or -1.
+func seatOf(players []words.Player, playerID string) int {
+	return slices.IndexFunc(players, func(player words.Player) bool {
+		return player.ID() == playerID
+	})
+}
+
+// sharedEventRedactor rewrites a game's events for a share link's holder,
+// replacing player IDs with seats. It learns the seats of players who join
+// after the stream opens from their PLAYER_JOINED events.
+type sharedEventRedactor struct {
+	seats map[string]int
+}
+
+func newSharedEventRedactor(game *words.Game) *sharedEventRedactor {
+	redactor := &sharedEventRedactor{seats: make(map[string]int)}
+	for seat, player := range game.Players() {
+		redactor.seats[player.ID()] = seat
+	}
+
+	return redactor
+}
+
+// redact returns the event as a share link shows it, reporting false for
+// events a link does not show, or cannot be shown safely.
+func (redactor *sharedEventRedactor) redact(event words.Event) (words.Event, bool) {
+	var (
+		payload any
+		err     error
+	)
+
+	switch event.Type {
+	case words.EventTypeResyncRequired:
+		return event, true
+	case words.EventTypeGameStarted:
+		// the public event carries no letters; anything else stays out
+		event.Payload = nil
+		return event, true
+	case words.EventTypePlayerJoined:
+		var joined words.PlayerJoinedPayload
+		if err = json.Unmarshal(event.Payload, &joined); err == nil {
+			seat, known := redactor.seats[joined.PlayerID]
+			if !known {
+				seat = len(redactor.seats)
+				redactor.seats[joined.PlayerID] = seat
+			}
+			payload = sharedPlayerJoinedPayload{Seat: seat, Name: joined.PlayerName}
+		}
+	case words.EventTypeWordPlayed:
+		var played words.WordPlayedPayload
+		if err = json.Unmarshal(event.Payload, &played); err == nil {
+			payload = sharedTurnPayload{
+				Seat:      redactor.seat(played.PlayerID),
+				X:         &played.X,
+				Y:         &played.Y,
+				Direction: played.Direction,
+				Word:      played.Word,
+				Points:    played.Points,
+				NextSeat:  redactor.seat(played.NextPlayerID),
+				Round:     played.Round,
+			}
+		}
+	case words.EventTypeTurnPassed:
+		var passed words.TurnPassedPayload
+		if err = json.Unmarshal(event.Payload, &passed); err == nil {
+			payload = sharedTurnPayload{
+				Seat:     redactor.seat(passed.PlayerID),
+				NextSeat: redactor.seat(passed.NextPlayerID),
+				Round:    passed.Round,
+			}
+		}
+	case words.EventTypeLettersExchanged:
+		var exchanged words.LettersExchangedPayload
+		if err = json.Unmarshal(event.Payload, &exchanged); err == nil {
+			payload = sharedTurnPayload{
+				Seat:     redactor.seat(exchanged.PlayerID),
+				Count:    exchanged.Count,
+				NextSeat: redactor.seat(exchanged.NextPlayerID),
+				Round:    exchanged.Round,
+			}
+		}
+	case words.EventTypeChallengeStarted:
+		var started words.ChallengeStartedPayload
+		if err = json.Unmarshal(event.Payload, &started); err == nil {
+			payload = sharedChallengePayload{
+				ChallengerSeat: redactor.seat(started.ChallengerID),
+				MoverSeat:      redactor.seat(started.MoverID),
+				VotesInvalid:   started.VotesInvalid,
+				VotesValid:     started.VotesValid,
+				VotesNeeded:    started.VotesNeeded,
+				EligibleVoters: started.EligibleVoters,
+			}
+		}
+	case words.EventTypeChallengeVoteCast:
+		var voted words.ChallengeVoteCastPayload
+		if err = json.Unmarshal(event.Payload, &voted); err == nil {
+			payload = sharedChallengePayload{
+				VoterSeat:    redactor.seat(voted.PlayerID),
+				VotesInvalid: voted.VotesInvalid,
+				VotesValid:   voted.VotesValid,
+				VotesNeeded:  voted.VotesNeeded,
+			}
+		}
+	case words.EventTypeChallengeResolved:
+		var resolved words.ChallengeResolvedPayload
+		if err = json.Unmarshal(event.Payload, &resolved); err == nil {
+			payload = sharedChallengePayload{
+				Upheld:         &resolved.Upheld,
+				ChallengerSeat: redactor.seat(resolved.ChallengerID),
+				MoverSeat:      redactor.seat(resolved.MoverID),
+				VotesInvalid:   resolved.VotesInvalid,
+				VotesValid:     resolved.VotesValid,
+				RescindedWord:  resolved.RescindedWord,
+			}
+		}
+	case words.EventTypeGameEnded:
+		var ended words.GameEndedPayload
+		if err = json.Unmarshal(event.Payload, &ended); err == nil {
+			payload = redactor.gameEnded(ended)
+		}
+	default:
+		// chat, spectators, moderation and racks are not shared
+		return words.Event{}, false
+	}
+
+	if err != nil {
+		return words.Event{}, false
+	}
+
+	if event.Payload, err = json.Marshal(payload); err != nil {
+		return words.Event{}, false
+	}
+
+	return event, true
+}
+
+// seat returns the player's seat, or nil for an unknown player.
+func (redactor *sharedEventRedactor) seat(playerID string) *int {
+	seat, known := redactor.seats[playerID]
+	if !known {
+		return nil
+	}
+
+	return &seat
+}
+
+// gameEnded lists the final scores by seat, leaving out unknown players.
+func (redactor *sharedEventRedactor) gameEnded(ended words.GameEndedPayload) sharedGameEndedPayload {
+	payload := sharedGameEndedPayload{
+		WinnerSeats: []int{},
+		Scores:      make([]int, len(redactor.seats)),
+	}
+
+	for _, winnerID := range ended.WinnerIDs {
+		if seat := redactor.seat(winnerID); seat != nil {
+			payload.WinnerSeats = append(payload.WinnerSeats, *seat)
+		}
+	}
+	slices.Sort(payload.WinnerSeats)
+
+	for playerID, score := range ended.Scores {
+		if seat := redactor.seat(playerID); seat != nil {
+			payload.Scores[*seat] = score
+		}
+	}
+
+	return payload
+}
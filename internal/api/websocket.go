@@ -0,0 +1,371 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/carterjs/words/internal/words"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+const (
+	// pongTimeout is how long a ping may go unanswered before the connection
+	// is considered dead.
+	pongTimeout = 10 * time.Second
+	// socketWriteTimeout bounds a single write to a slow client.
+	socketWriteTimeout = 10 * time.Second
+)
+
+type (
+	// socketCommand is a client request on a game socket. Operation and
+	// Payload are exactly what the PATCH routes accept; ID is echoed back on
+	// the response so clients can match them up.
+	socketCommand struct {
+		ID        string          `json:"id"`
+		Operation string          `json:"operation"`
+		Payload   json.RawMessage `json:"payload"`
+	}
+
+	// socketMessage is anything the server sends on a game socket: either an
+	// event or the response to a command.
+	socketMessage struct {
+		Kind   string          `json:"kind"`
+		Event  *words.Event    `json:"event,omitempty"`
+		ID     string          `json:"id,omitempty"`
+		Status int             `json:"status,omitempty"`
+		Body   json.RawMessage `json:"body,omitempty"`
+	}
+)
+
+const (
+	socketMessageEvent    = "event"
+	socketMessageResponse = "response"
+)
+
+// handleGameSocket serves a game over a WebSocket. It streams the same
+// events as handleStreamGameEvents and runs commands through the same
+// handlers as the PATCH routes, so identity checks and error responses are
+// identical. Browsers cannot set Last-Event-ID on a socket, so resuming
+// clients pass it as the lastEventId query parameter instead.
+func (server *Server) handleGameSocket() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gameID := r.PathValue("gameId")
+		playerID, _ := playerIDFromRequest(r)
+		lastEventID, _ := strconv.ParseUint(r.URL.Query().Get("lastEventId"), 10, 64)
+
+		if lastEventID == 0 {
+			// start from the game's current position, so switching streams
+			// after a JOIN_GAME can replay whatever the first one had not sent
+			game, err := server.service.GameByID(r.Context(), gameID)
+			if err != nil {
+				server.respondWithError(w, err)
+				return
+			}
+			lastEventID = game.LastEventID()
+		}
+
+		subscription, err := server.service.Subscribe(r.Context(), gameID, playerID, lastEventID)
+		if err != nil {
+			server.respondWithError(w, err)
+			return
+		}
+
+		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+			OriginPatterns: server.allowedOriginHosts(),
+		})
+		if err != nil {
+			subscription.Close()
+			server.logger.Debug("accepting websocket", "error", err)
+			return
+		}
+
+		session := &socketSession{
+			server:      server,
+			conn:        conn,
+			gameID:      gameID,
+			cookies:     r.Cookies(),
+			lastEventID: lastEventID,
+		}
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		session.stream(ctx, subscription)
+		defer session.stopStreaming()
+
+		go session.keepAlive(ctx, cancel)
+
+		status, reason := session.serveCommands(ctx)
+		conn.Close(status, reason)
+	}
+}
+
+// allowedOriginHosts converts the configured origin to the host pattern the
+// websocket library checks Origin headers against.
+func (server *Server) allowedOriginHosts() []string {
+	origin, err := url.Parse(server.config.AllowedOrigin)
+	if err != nil || origin.Host == "" {
+		return nil
+	}
+
+	return []string{origin.Host}
+}
+
+// socketSession is one client's connection to a game.
+type socketSession struct {
+	server *Server
+	conn   *websocket.Conn
+	gameID string
+
+	mutex        sync.Mutex
+	cookies      []*http.Cookie
+	subscription words.Subscription
+	stopPump     context.CancelFunc
+	pumpDone     chan struct{}
+	lastEventID  uint64
+}
+
+// stream starts forwarding the subscription's events to the client. Any
+// previous stream must already be stopped.
+func (session *socketSession) stream(ctx context.Context, subscription words.Subscription) {
+	pumpCtx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	session.mutex.Lock()
+	session.subscription = subscription
+	session.stopPump = stop
+	session.pumpDone = done
+	session.mutex.Unlock()
+
+	go func() {
+		defer close(done)
+
+		for {
+			event, err := subscription.Next(pumpCtx)
+			if err != nil {
+				return
+			}
+
+			if event.ID != 0 {
+				session.mutex.Lock()
+				session.lastEventID = event.ID
+				session.mutex.Unlock()
+			}
+
+			// write with the session's context: cancelling a write midway
+			// closes the connection, and stopping the pump shouldn't
+			if err := session.write(ctx, socketMessage{Kind: socketMessageEvent, Event: &event}); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+// stopStreaming stops the event pump and waits for it, so a replacement
+// subscription never interleaves with the old one.
+func (session *socketSession) stopStreaming() {
+	session.mutex.Lock()
+	subscription, stop, done := session.subscription, session.stopPump, session.pumpDone
+	session.subscription, session.stopPump, session.pumpDone = nil, nil, nil
+	session.mutex.Unlock()
+
+	if subscription == nil {
+		return
+	}
+
+	stop()
+	subscription.Close()
+	<-done
+}
+
+// keepAlive pings the client until the context ends, cancelling the session
+// if a ping goes unanswered.
+func (session *socketSession) keepAlive(ctx context.Context, cancel context.CancelFunc) {
+	ticker := time.NewTicker(keepaliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pingCtx, pingCancel := context.WithTimeout(ctx, pongTimeout)
+		err := session.conn.Ping(pingCtx)
+		pingCancel()
+
+		if err != nil {
+			cancel()
+			return
+		}
+	}
+}
+
+// serveCommands reads and answers commands until the client disconnects or
+// the context ends, returning how to close the connection.
+func (session *socketSession) serveCommands(ctx context.Context) (websocket.StatusCode, string) {
+	for {
+		var command socketCommand
+		if err := wsjson.Read(ctx, session.conn, &command); err != nil {
+			if websocket.CloseStatus(err) == websocket.StatusNormalClosure || errors.Is(err, context.Canceled) {
+				return websocket.StatusNormalClosure, ""
+			}
+
+			return websocket.StatusPolicyViolation, "unreadable command"
+		}
+
+		response, err := session.execute(ctx, command)
+		if err != nil {
+			session.server.logger.Error("resubscribing websocket", "error", err)
+			return websocket.StatusInternalError, "resubscribe failed"
+		}
+
+		if err := session.write(ctx, response); err != nil {
+			return websocket.StatusInternalError, "write failed"
+		}
+	}
+}
+
+// execute runs the command through the matching PATCH handler as if it had
+// arrived over HTTP with the session's cookies.
+func (session *socketSession) execute(ctx context.Context, command socketCommand) (socketMessage, error) {
+	body, err := json.Marshal(struct {
+		Operation string          `json:"operation"`
+		Payload   json.RawMessage `json:"payload"`
+	}{
+		Operation: command.Operation,
+		Payload:   command.Payload,
+	})
+	if err != nil {
+		return socketMessage{Kind: socketMessageResponse, ID: command.ID, Status: http.StatusBadRequest}, nil
+	}
+
+	path := "/api/v1/games/" + session.gameID
+	handler := session.server.handleUpdateGame()
+	if command.Operation == "ADD_WORD" {
+		path += "/board"
+		handler = session.server.handleUpdateBoard()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPatch, path, bytes.NewReader(body))
+	if err != nil {
+		return socketMessage{Kind: socketMessageResponse, ID: command.ID, Status: http.StatusInternalServerError}, nil
+	}
+	request.SetPathValue("gameId", session.gameID)
+
+	session.mutex.Lock()
+	for _, cookie := range session.cookies {
+		request.AddCookie(cookie)
+	}
+	session.mutex.Unlock()
+
+	recorder := newCommandRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if err := session.adoptCookies(ctx, recorder.Header()); err != nil {
+		return socketMessage{}, err
+	}
+
+	return socketMessage{
+		Kind:   socketMessageResponse,
+		ID:     command.ID,
+		Status: recorder.status,
+		Body:   bytes.TrimSpace(recorder.body.Bytes()),
+	}, nil
+}
+
+// adoptCookies keeps identities handed out by commands, such as JOIN_GAME,
+// for the rest of the session. A new player identity also switches the
+// event stream over to include that player's private events.
+func (session *socketSession) adoptCookies(ctx context.Context, header http.Header) error {
+	issued := (&http.Response{Header: header}).Cookies()
+	if len(issued) == 0 {
+		return nil
+	}
+
+	session.mutex.Lock()
+	var newPlayerID string
+	for _, cookie := range issued {
+		replaced := false
+		for index, existing := range session.cookies {
+			if existing.Name == cookie.Name {
+				session.cookies[index] = cookie
+				replaced = true
+			}
+		}
+		if !replaced {
+			session.cookies = append(session.cookies, cookie)
+		}
+
+		if cookie.Name == playerIDCookie {
+			newPlayerID = cookie.Value
+		}
+	}
+	session.mutex.Unlock()
+
+	if newPlayerID == "" {
+		return nil
+	}
+
+	// stop first so the last delivered ID is final, then replay from it
+	session.stopStreaming()
+
+	session.mutex.Lock()
+	lastEventID := session.lastEventID
+	session.mutex.Unlock()
+
+	subscription, err := session.server.service.Subscribe(ctx, session.gameID, newPlayerID, lastEventID)
+	if err != nil {
+		return fmt.Errorf("subscribing as %s: %w", newPlayerID, err)
+	}
+
+	session.stream(ctx, subscription)
+
+	return nil
+}
+
+func (session *socketSession) write(ctx context.Context, message socketMessage) error {
+	writeCtx, cancel := context.WithTimeout(ctx, socketWriteTimeout)
+	defer cancel()
+
+	return wsjson.Write(writeCtx, session.conn, message)
+}
+
+// commandRecorder captures a handler's response to a socket command.
+type commandRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newCommandRecorder() *commandRecorder {
+	return &commandRecorder{
+		header: make(http.Header),
+		status: http.StatusOK,
+	}
+}
+
+// Header implements http.ResponseWriter.
+func (recorder *commandRecorder) Header() http.Header {
+	return recorder.header
+}
+
+// Write implements http.ResponseWriter.
+func (recorder *commandRecorder) Write(data []byte) (int, error) {
+	return recorder.body.Write(data)
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (recorder *commandRecorder) WriteHeader(status int) {
+	recorder.status = status
+}
@@ -0,0 +1,177 @@
+// Package crypt seals data at rest with AES-GCM under a ring of named keys,
+// so keys can be rotated: data is always sealed with the current key and
+// opened with whichever key it names in its header.
+package crypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnknownKey reports sealed data naming a key the keyring does not
+	// hold.
+	ErrUnknownKey = errors.New("sealed with an unknown key")
+	// ErrNotSealed reports data that was not sealed by a keyring.
+	ErrNotSealed = errors.New("data is not sealed")
+	// ErrTampered reports sealed data that fails authentication: it was
+	// changed, or is being opened with the wrong associated data.
+	ErrTampered = errors.New("sealed data failed authentication")
+)
+
+// magic starts every sealed blob. It cannot begin a gzip stream or a JSON
+// document, so sealed and plain data can sit side by side.
+var magic = []byte("WGS\x01")
+
+// maxKeyIDLength is the longest key ID a header can carry.
+const maxKeyIDLength = 255
+
+// Keyring holds the keys data may be sealed with. The current key seals; any
+// key opens what it sealed.
+type Keyring struct {
+	current string
+	ciphers map[string]cipher.AEAD
+}
+
+// NewKeyring returns a keyring sealing with the key named current. Keys must
+// be 16, 24 or 32 bytes long, selecting AES-128, AES-192 or AES-256.
+func NewKeyring(current string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("current key %q is not in the keyring", current)
+	}
+
+	keyring := &Keyring{
+		current: current,
+		ciphers: make(map[string]cipher.AEAD, len(keys)),
+	}
+
+	for keyID, key := range keys {
+		if keyID == "" || len(keyID) > maxKeyIDLength {
+			return nil, fmt.Errorf("key ID %q must be 1 to %d bytes", keyID, maxKeyIDLength)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("creating cipher for key %q: %w", keyID, err)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("creating GCM for key %q: %w", keyID, err)
+		}
+
+		keyring.ciphers[keyID] = aead
+	}
+
+	return keyring, nil
+}
+
+// ParseKeyring reads a keyring from a comma-separated list of id:key pairs
+// with base64-encoded keys, such as "2024b:…,2024a:…". The first key is the
+// current one; the rest are kept only to open data they sealed.
+func ParseKeyring(spec string) (*Keyring, error) {
+	var (
+		current string
+		keys    = make(map[string][]byte)
+	)
+
+	for position, entry := range strings.Split(spec, ",") {
+		// report malformed entries by position, since they may hold a key
+		keyID, encoded, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found {
+			return nil, fmt.Errorf("key %d is not of the form id:key", position+1)
+		}
+
+		if _, duplicate := keys[keyID]; duplicate {
+			return nil, fmt.Errorf("key %q is listed twice", keyID)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("decoding key %q: %w", keyID, err)
+		}
+
+		if current == "" {
+			current = keyID
+		}
+		keys[keyID] = key
+	}
+
+	return NewKeyring(current, keys)
+}
+
+// CurrentKeyID returns the ID of the key new data is sealed with.
+func (keyring *Keyring) CurrentKeyID() string {
+	return keyring.current
+}
+
+// Seal encrypts the plaintext under the current key, binding it to the
+// associated data, which must be given again to open it. The result carries
+// the key's ID in its header.
+func (keyring *Keyring) Seal(plaintext, associated []byte) ([]byte, error) {
+	aead := keyring.ciphers[keyring.current]
+
+	header := append(append(bytes.Clone(magic), byte(len(keyring.current))), keyring.current...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+
+	sealed := append(bytes.Clone(header), nonce...)
+	return aead.Seal(sealed, nonce, plaintext, append(header, associated...)), nil
+}
+
+// Open decrypts data sealed by a keyring holding the same key, given the
+// same associated data.
+func (keyring *Keyring) Open(sealed, associated []byte) ([]byte, error) {
+	keyID, ok := KeyID(sealed)
+	if !ok {
+		return nil, ErrNotSealed
+	}
+
+	aead, ok := keyring.ciphers[keyID]
+	if !ok {
+		return nil, fmt.Errorf("opening data sealed with %q: %w", keyID, ErrUnknownKey)
+	}
+
+	headerLength := len(magic) + 1 + len(keyID)
+	header, body := sealed[:headerLength], sealed[headerLength:]
+	if len(body) < aead.NonceSize() {
+		return nil, ErrTampered
+	}
+
+	nonce, ciphertext := body[:aead.NonceSize()], body[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, append(bytes.Clone(header), associated...))
+	if err != nil {
+		return nil, ErrTampered
+	}
+
+	return plaintext, nil
+}
+
+// IsSealed reports whether the data was sealed by a keyring.
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+// KeyID returns the ID of the key the data was sealed with, reporting false
+// if it was not sealed.
+func KeyID(data []byte) (string, bool) {
+	if !IsSealed(data) || len(data) < len(magic)+1 {
+		return "", false
+	}
+
+	length := int(data[len(magic)])
+	if len(data) < len(magic)+1+length {
+		return "", false
+	}
+
+	return string(data[len(magic)+1 : len(magic)+1+length]), true
+}
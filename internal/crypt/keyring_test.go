@@ -0,0 +1,141 @@
+package crypt_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/carterjs/words/internal/crypt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring_Open(t *testing.T) {
+	t.Parallel()
+
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 16)
+
+	tests := []struct {
+		name       string
+		sealKeys   map[string][]byte
+		openKeys   map[string][]byte
+		associated string
+		tamper     func(sealed []byte) []byte
+		wantErr    error
+	}{
+		{name: "opens what it sealed", associated: "game"},
+		{
+			name:     "opens data sealed with a rotated-out key",
+			sealKeys: map[string][]byte{"old": oldKey},
+			openKeys: map[string][]byte{"new": newKey, "old": oldKey},
+		},
+		{
+			name:     "rejects data sealed with an unknown key",
+			openKeys: map[string][]byte{"new": newKey},
+			wantErr:  crypt.ErrUnknownKey,
+		},
+		{name: "rejects other associated data", associated: "other game", wantErr: crypt.ErrTampered},
+		{
+			name:    "rejects changed ciphertext",
+			tamper:  func(sealed []byte) []byte { sealed[len(sealed)-1] ^= 1; return sealed },
+			wantErr: crypt.ErrTampered,
+		},
+		{
+			name:    "rejects truncated data",
+			tamper:  func(sealed []byte) []byte { return sealed[:10] },
+			wantErr: crypt.ErrTampered,
+		},
+		{
+			name:    "rejects plain data",
+			tamper:  func([]byte) []byte { return []byte(`{"id": "game"}`) },
+			wantErr: crypt.ErrNotSealed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			sealKeys := test.sealKeys
+			if sealKeys == nil {
+				sealKeys = map[string][]byte{"old": oldKey}
+			}
+			openKeys := test.openKeys
+			if openKeys == nil {
+				openKeys = sealKeys
+			}
+
+			sealing, err := crypt.NewKeyring("old", sealKeys)
+			require.NoError(t, err)
+			var current string
+			for keyID := range openKeys {
+				current = keyID
+			}
+			opening, err := crypt.NewKeyring(current, openKeys)
+			require.NoError(t, err)
+
+			sealed, err := sealing.Seal([]byte("secret rack"), []byte("game"))
+			require.NoError(t, err)
+			assert.NotContains(t, string(sealed), "secret rack")
+
+			keyID, ok := crypt.KeyID(sealed)
+			require.True(t, ok)
+			assert.Equal(t, "old", keyID)
+
+			if test.tamper != nil {
+				sealed = test.tamper(sealed)
+			}
+
+			associated := test.associated
+			if associated == "" {
+				associated = "game"
+			}
+
+			opened, err := opening.Open(sealed, []byte(associated))
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "secret rack", string(opened))
+		})
+	}
+}
+
+func TestParseKeyring(t *testing.T) {
+	t.Parallel()
+
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+
+	tests := []struct {
+		name        string
+		spec        string
+		wantCurrent string
+		wantErr     bool
+	}{
+		{name: "seals with the first key", spec: "new:" + key + ", old:" + key, wantCurrent: "new"},
+		{name: "rejects an entry without an ID", spec: key, wantErr: true},
+		{name: "rejects a repeated ID", spec: "a:" + key + ",a:" + key, wantErr: true},
+		{name: "rejects a malformed key", spec: "a:not base64", wantErr: true},
+		{name: "rejects a key of the wrong size", spec: "a:" + base64.StdEncoding.EncodeToString([]byte("short")), wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyring, err := crypt.ParseKeyring(test.spec)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.wantCurrent, keyring.CurrentKeyID())
+		})
+	}
+}
@@ -23,6 +23,13 @@ const (
 	ClassConflict Class = "conflict"
 	// ClassUnauthenticated marks requests with no player identity.
 	ClassUnauthenticated Class = "unauthenticated"
+	// ClassForbidden marks requests from someone not allowed to make them.
+	ClassForbidden Class = "forbidden"
+	// ClassRateLimited marks requests refused for arriving too quickly.
+	ClassRateLimited Class = "rate_limited"
+	// ClassPreconditionFailed marks conditional requests whose condition no
+	// longer holds.
+	ClassPreconditionFailed Class = "precondition_failed"
 	// ClassInternal marks unexpected failures on our side.
 	ClassInternal Class = "internal"
 )
@@ -98,8 +105,54 @@ var (
 	BadRequest = define("bad_request", ClassInvalid, "the request could not be parsed")
 	// UnknownOperation reports an update operation the API does not know.
 	UnknownOperation = define("unknown_operation", ClassInvalid, "unknown operation")
+	// InvalidName reports a player or spectator name that fails validation.
+	InvalidName = define("invalid_name", ClassInvalid, "names must be 1 to 40 characters with no control characters")
+	// TooManySpectators reports a game with no room for another spectator.
+	TooManySpectators = define("too_many_spectators", ClassConflict, "the game has as many spectators as it allows")
 	// MissingPlayer reports a request that requires a player identity.
 	MissingPlayer = define("missing_player", ClassUnauthenticated, "the request has no player identity")
+	// MissingParticipant reports a request that requires a player or spectator identity.
+	MissingParticipant = define("missing_participant", ClassUnauthenticated, "the request has no player or spectator identity")
+	// NotParticipant reports someone who is neither playing nor spectating.
+	NotParticipant = define("not_participant", ClassNotFound, "you are not a player or spectator in this game")
+	// NotHost reports a moderation action by someone other than the host.
+	NotHost = define("not_host", ClassForbidden, "only the host can do that")
+	// Muted reports a chat message from a muted participant.
+	Muted = define("muted", ClassForbidden, "the host has muted you")
+	// EmptyMessage reports a chat message with no text.
+	EmptyMessage = define("empty_message", ClassInvalid, "the message is empty")
+	// MessageTooLong reports a chat message over the length limit.
+	MessageTooLong = define("message_too_long", ClassInvalid, "the message is too long")
+	// RateLimited reports a participant sending messages too quickly.
+	RateLimited = define("rate_limited", ClassRateLimited, "you are sending messages too quickly")
+	// VersionConflict reports a save that lost a race with another save.
+	VersionConflict = define("version_conflict", ClassConflict, "the game was changed by another request; try again")
+	// VersionMismatch reports a conditional update against an outdated version.
+	VersionMismatch = define("version_mismatch", ClassPreconditionFailed, "the game has changed since you last loaded it")
+	// InvalidCursor reports a listing cursor that no page issued.
+	InvalidCursor = define("invalid_cursor", ClassInvalid, "the cursor does not point into a listing")
+	// UnknownFormat reports an export or import in an unsupported format.
+	UnknownFormat = define("unknown_format", ClassInvalid, "the format must be jsonl or tar")
+	// MalformedTransfer reports an import that could not be read.
+	MalformedTransfer = define("malformed_transfer", ClassInvalid, "the import could not be read in its format")
+	// InconsistentGame reports a stored game that breaks its invariants.
+	InconsistentGame = define("inconsistent_game", ClassInternal, "the game's stored state is inconsistent")
+	// GameNotFinished reports an action that waits for the game to end.
+	GameNotFinished = define("game_not_finished", ClassConflict, "the game is not over yet")
+	// NoMoveHistory reports a game played before moves were recorded.
+	NoMoveHistory = define("no_move_history", ClassConflict, "the game was played before moves were recorded")
+	// MalformedNotation reports GCG notation that could not be replayed.
+	MalformedNotation = define("malformed_notation", ClassInvalid, "the notation could not be read or replayed")
+	// TooManyImports reports a client importing games too quickly.
+	TooManyImports = define("too_many_imports", ClassRateLimited, "you are importing games too quickly")
+	// ShareLinkNotFound reports a share token that does not open a game.
+	ShareLinkNotFound = define("share_link_not_found", ClassNotFound, "the share link does not exist, has expired or was revoked")
+	// InvalidShareLinkLifetime reports a share link asked to last too long.
+	InvalidShareLinkLifetime = define("invalid_share_link_lifetime", ClassInvalid, "share links can last at most 30 days")
+	// ShareLinkReadOnly reports an attempt to change a game through a share link.
+	ShareLinkReadOnly = define("share_link_read_only", ClassForbidden, "share links only allow viewing the game")
+	// NotAdmin reports an admin request without a valid admin token.
+	NotAdmin = define("not_admin", ClassUnauthenticated, "the request has no valid admin token")
 )
 
 // Class returns the code's category.
@@ -137,26 +190,45 @@ func FromError(err error) Code {
 }
 
 var sentinelCodes = map[error]Code{
-	words.ErrGameNotFound:           GameNotFound,
-	words.ErrPresetNotFound:         PresetNotFound,
-	words.ErrPlayerNotFound:         PlayerNotFound,
-	words.ErrGameNotStarted:         GameNotStarted,
-	words.ErrGameStarted:            GameAlreadyStarted,
-	words.ErrGameFinished:           GameFinished,
-	words.ErrNotYourTurn:            NotYourTurn,
-	words.ErrChallengePending:       ChallengePending,
-	words.ErrNoPendingChallenge:     NoPendingChallenge,
-	words.ErrNothingToChallenge:     NothingToChallenge,
-	words.ErrCannotChallengeOwnWord: CannotChallengeOwnWord,
-	words.ErrCannotVoteOnOwnWord:    CannotVoteOnOwnWord,
-	words.ErrAlreadyVoted:           AlreadyVoted,
-	words.ErrInvalidVote:            InvalidVote,
-	words.ErrNotEnoughPlayers:       NotEnoughPlayers,
-	words.ErrCannotPlayWord:         CannotPlayWord,
-	words.ErrWordNotConnected:       WordNotConnected,
-	words.ErrFirstWordNotCentered:   FirstWordNotCentered,
-	words.ErrIncomplete:             WordIncomplete,
-	words.ErrUnchanged:              WordUnchanged,
-	words.ErrMissingLetters:         MissingLetters,
-	words.ErrNotEnoughLettersInPool: NotEnoughLettersInPool,
+	words.ErrGameNotFound:             GameNotFound,
+	words.ErrPresetNotFound:           PresetNotFound,
+	words.ErrPlayerNotFound:           PlayerNotFound,
+	words.ErrGameNotStarted:           GameNotStarted,
+	words.ErrGameStarted:              GameAlreadyStarted,
+	words.ErrGameFinished:             GameFinished,
+	words.ErrNotYourTurn:              NotYourTurn,
+	words.ErrChallengePending:         ChallengePending,
+	words.ErrNoPendingChallenge:       NoPendingChallenge,
+	words.ErrNothingToChallenge:       NothingToChallenge,
+	words.ErrCannotChallengeOwnWord:   CannotChallengeOwnWord,
+	words.ErrCannotVoteOnOwnWord:      CannotVoteOnOwnWord,
+	words.ErrAlreadyVoted:             AlreadyVoted,
+	words.ErrInvalidVote:              InvalidVote,
+	words.ErrNotEnoughPlayers:         NotEnoughPlayers,
+	words.ErrCannotPlayWord:           CannotPlayWord,
+	words.ErrWordNotConnected:         WordNotConnected,
+	words.ErrFirstWordNotCentered:     FirstWordNotCentered,
+	words.ErrIncomplete:               WordIncomplete,
+	words.ErrUnchanged:                WordUnchanged,
+	words.ErrMissingLetters:           MissingLetters,
+	words.ErrNotEnoughLettersInPool:   NotEnoughLettersInPool,
+	words.ErrInvalidName:              InvalidName,
+	words.ErrTooManySpectators:        TooManySpectators,
+	words.ErrNotParticipant:           NotParticipant,
+	words.ErrNotHost:                  NotHost,
+	words.ErrMuted:                    Muted,
+	words.ErrEmptyMessage:             EmptyMessage,
+	words.ErrMessageTooLong:           MessageTooLong,
+	words.ErrRateLimited:              RateLimited,
+	words.ErrVersionConflict:          VersionConflict,
+	words.ErrVersionMismatch:          VersionMismatch,
+	words.ErrInvalidCursor:            InvalidCursor,
+	words.ErrUnknownFormat:            UnknownFormat,
+	words.ErrMalformedTransfer:        MalformedTransfer,
+	words.ErrInconsistentGame:         InconsistentGame,
+	words.ErrGameNotFinished:          GameNotFinished,
+	words.ErrNoMoveHistory:            NoMoveHistory,
+	words.ErrShareLinkNotFound:        ShareLinkNotFound,
+	words.ErrInvalidShareLinkLifetime: InvalidShareLinkLifetime,
+	words.ErrMalformedNotation:        MalformedNotation,
 }
@@ -0,0 +1,77 @@
+// Package lock provides per-key mutual exclusion satisfying the words
+// service's Locker contract.
+package lock
+
+import (
+	"context"
+	"sync"
+)
+
+// Local is an in-process locker. Keys are only tracked while someone holds
+// or waits for them, so locking many distinct keys does not leak memory.
+type Local struct {
+	mutex sync.Mutex
+	locks map[string]*entry
+}
+
+// entry is one key's lock. token holds a value while the lock is held, which
+// lets waiters give up when their context ends.
+type entry struct {
+	refs  int
+	token chan struct{}
+}
+
+// NewLocal returns a locker with no keys held.
+func NewLocal() *Local {
+	return &Local{
+		locks: make(map[string]*entry),
+	}
+}
+
+// Lock blocks until the key is free or the context ends, and returns the
+// function releasing it.
+func (local *Local) Lock(ctx context.Context, key string) (func(), error) {
+	local.mutex.Lock()
+	lock, exists := local.locks[key]
+	if !exists {
+		lock = &entry{token: make(chan struct{}, 1)}
+		local.locks[key] = lock
+	}
+	lock.refs++
+	local.mutex.Unlock()
+
+	select {
+	case lock.token <- struct{}{}:
+	case <-ctx.Done():
+		local.release(key, lock)
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-lock.token
+			local.release(key, lock)
+		})
+	}, nil
+}
+
+// Len returns how many keys are currently held or waited on.
+func (local *Local) Len() int {
+	local.mutex.Lock()
+	defer local.mutex.Unlock()
+
+	return len(local.locks)
+}
+
+// release drops a holder's or waiter's reference, evicting the key once
+// nobody needs it.
+func (local *Local) release(key string, lock *entry) {
+	local.mutex.Lock()
+	defer local.mutex.Unlock()
+
+	lock.refs--
+	if lock.refs == 0 {
+		delete(local.locks, key)
+	}
+}
@@ -0,0 +1,50 @@
+package lock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/carterjs/words/internal/lock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal_Lock(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		firstKey string
+		key      string
+		wantErr  error
+	}{
+		{name: "waits for a held key", firstKey: "a", key: "a", wantErr: context.DeadlineExceeded},
+		{name: "does not block other keys", firstKey: "a", key: "b"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			local := lock.NewLocal()
+
+			unlock, err := local.Lock(t.Context(), test.firstKey)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+			defer cancel()
+
+			unlockSecond, err := local.Lock(ctx, test.key)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+			} else {
+				require.NoError(t, err)
+				unlockSecond()
+			}
+
+			unlock()
+			assert.Zero(t, local.Len(), "idle keys are evicted")
+		})
+	}
+}
@@ -2,10 +2,18 @@ package pubsub
 
 import (
 	"context"
+	"errors"
 
 	"github.com/carterjs/words/internal/words"
 )
 
+// Config tunes a game broker. Zero fields fall back to defaults.
+type Config struct {
+	// BufferSize bounds how many undelivered events each subscriber holds
+	// before it is told to resync.
+	BufferSize int
+}
+
 // GameBroker adapts the generic local broker to the words service's Broker
 // contract.
 type GameBroker struct {
@@ -13,9 +21,12 @@ type GameBroker struct {
 }
 
 // NewGameBroker returns an in-process broker for game events.
-func NewGameBroker() *GameBroker {
+func NewGameBroker(config Config) *GameBroker {
 	return &GameBroker{
-		local: NewLocal[string, words.Event](),
+		local: NewLocal[string](LocalConfig[words.Event]{
+			BufferSize: config.BufferSize,
+			Coalesce:   supersedes,
+		}),
 	}
 }
 
@@ -26,5 +37,32 @@ func (broker *GameBroker) Publish(ctx context.Context, channel string, event wor
 
 // Subscribe returns a subscription covering all the given channels.
 func (broker *GameBroker) Subscribe(ctx context.Context, channels ...string) words.Subscription {
-	return broker.local.Subscribe(channels...)
+	return &gameSubscription{subscription: broker.local.Subscribe(channels...)}
+}
+
+// supersedes reports whether next makes a queued event on the same channel
+// redundant. Racks are sent whole, so only the latest one matters.
+func supersedes(queued, next words.Event) bool {
+	return queued.Type == words.EventTypeRackUpdated && next.Type == words.EventTypeRackUpdated
+}
+
+// gameSubscription turns a lagging subscription into a RESYNC_REQUIRED event
+// so subscribers can recover without knowing about the broker.
+type gameSubscription struct {
+	subscription *Subscription[string, words.Event]
+}
+
+// Next returns the next event, or a resync event after events were dropped.
+func (subscription *gameSubscription) Next(ctx context.Context) (words.Event, error) {
+	event, err := subscription.subscription.Next(ctx)
+	if errors.Is(err, ErrSubscriptionLagged) {
+		return words.Event{Type: words.EventTypeResyncRequired}, nil
+	}
+
+	return event, err
+}
+
+// Close removes the subscription from the broker.
+func (subscription *gameSubscription) Close() {
+	subscription.subscription.Close()
 }
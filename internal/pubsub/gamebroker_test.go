@@ -31,7 +31,7 @@ func TestGameBroker_Publish(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			broker := pubsub.NewGameBroker()
+			broker := pubsub.NewGameBroker(pubsub.Config{})
 			subscription := broker.Subscribe(t.Context(), test.subscribeTo...)
 			defer subscription.Close()
 
@@ -43,3 +43,47 @@ func TestGameBroker_Publish(t *testing.T) {
 		})
 	}
 }
+
+func TestGameBroker_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	rack := words.Event{Type: words.EventTypeRackUpdated, Payload: json.RawMessage(`{"letters":[]}`)}
+	played := words.Event{Type: words.EventTypeWordPlayed}
+
+	tests := []struct {
+		name      string
+		publish   []words.Event
+		wantTypes []words.EventType
+	}{
+		{
+			name:      "keeps only the latest queued rack",
+			publish:   []words.Event{rack, played, rack},
+			wantTypes: []words.EventType{words.EventTypeWordPlayed, words.EventTypeRackUpdated},
+		},
+		{
+			name:      "asks a lagging subscriber to resync",
+			publish:   []words.Event{played, played, played},
+			wantTypes: []words.EventType{words.EventTypeResyncRequired},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			broker := pubsub.NewGameBroker(pubsub.Config{BufferSize: 2})
+			subscription := broker.Subscribe(t.Context(), "game:1")
+			defer subscription.Close()
+
+			for _, event := range test.publish {
+				broker.Publish(t.Context(), "game:1", event)
+			}
+
+			for _, want := range test.wantTypes {
+				received, err := subscription.Next(t.Context())
+				require.NoError(t, err)
+				assert.Equal(t, want, received.Type)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package pubsub
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/carterjs/words/internal/words"
+)
+
+// Frame operations exchanged between a hub and its remote brokers.
+const (
+	opHello       = "hello"
+	opWelcome     = "welcome"
+	opSubscribe   = "subscribe"
+	opUnsubscribe = "unsubscribe"
+	opPublish     = "publish"
+	opAck         = "ack"
+)
+
+const (
+	// peerOutboxSize bounds how many frames a hub queues for one peer before
+	// treating it as stalled and disconnecting it.
+	peerOutboxSize = 1024
+	// handshakeTimeout bounds how long either end of a new connection waits
+	// for the other to introduce itself.
+	handshakeTimeout = 5 * time.Second
+)
+
+// ErrHubSecretRequired reports a hub or remote broker set up without the
+// shared secret peers authenticate with.
+var ErrHubSecretRequired = errors.New("hub secret required")
+
+// frame is one newline-delimited JSON message on a hub connection. Seq
+// correlates a subscribe with its ack. Secret is only sent in the hello
+// that opens a connection.
+type frame struct {
+	Op      string       `json:"op"`
+	Seq     uint64       `json:"seq,omitempty"`
+	Channel string       `json:"channel,omitempty"`
+	Event   *words.Event `json:"event,omitempty"`
+	Secret  string       `json:"secret,omitempty"`
+}
+
+// Hub relays events between remote brokers over TCP, so server instances
+// behind a load balancer share one set of channels. Each peer only receives
+// the channels it has subscribed to, and only once it has proven it knows
+// the hub's shared secret. Frames travel unencrypted, so the hub belongs on
+// a private network.
+type Hub struct {
+	listener net.Listener
+	secret   string
+	logger   *slog.Logger
+
+	mutex    sync.RWMutex
+	channels map[string]map[*hubPeer]struct{}
+	peers    map[*hubPeer]struct{}
+	closed   bool
+}
+
+// ListenHub starts listening for peers on the address. Call Serve to accept
+// them. Peers must open with the secret, which cannot be empty.
+func ListenHub(address, secret string, logger *slog.Logger) (*Hub, error) {
+	if secret == "" {
+		return nil, ErrHubSecretRequired
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("listening for peers: %w", err)
+	}
+
+	return &Hub{
+		listener: listener,
+		secret:   secret,
+		logger:   logger,
+		channels: make(map[string]map[*hubPeer]struct{}),
+		peers:    make(map[*hubPeer]struct{}),
+	}, nil
+}
+
+// Addr returns the address the hub is listening on.
+func (hub *Hub) Addr() net.Addr {
+	return hub.listener.Addr()
+}
+
+// Serve accepts peers until the hub is closed.
+func (hub *Hub) Serve() error {
+	for {
+		conn, err := hub.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return fmt.Errorf("accepting peer: %w", err)
+		}
+
+		go hub.admit(conn)
+	}
+}
+
+// admit checks a new connection's hello and starts serving it as a peer,
+// or hangs up on it.
+func (hub *Hub) admit(conn net.Conn) {
+	decoder := json.NewDecoder(bufio.NewReader(conn))
+
+	if err := hub.handshake(conn, decoder); err != nil {
+		hub.logger.Warn("rejecting hub peer", "remoteAddress", conn.RemoteAddr().String(), "error", err)
+		conn.Close()
+		return
+	}
+
+	peer := &hubPeer{
+		conn:     conn,
+		outbox:   make(chan frame, peerOutboxSize),
+		channels: make(map[string]struct{}),
+	}
+	if !hub.addPeer(peer) {
+		conn.Close()
+		return
+	}
+
+	go peer.writeFrames()
+	hub.serve(peer, decoder)
+}
+
+// handshake reads the peer's hello and welcomes it if it carries the
+// hub's secret.
+func (hub *Hub) handshake(conn net.Conn, decoder *json.Decoder) error {
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return err
+	}
+
+	var hello frame
+	if err := decoder.Decode(&hello); err != nil {
+		return fmt.Errorf("reading hello: %w", err)
+	}
+
+	if hello.Op != opHello || subtle.ConstantTimeCompare([]byte(hello.Secret), []byte(hub.secret)) != 1 {
+		return errors.New("wrong secret")
+	}
+
+	if err := json.NewEncoder(conn).Encode(frame{Op: opWelcome}); err != nil {
+		return fmt.Errorf("writing welcome: %w", err)
+	}
+
+	return conn.SetDeadline(time.Time{})
+}
+
+// Close stops accepting peers and disconnects the connected ones.
+func (hub *Hub) Close() error {
+	hub.mutex.Lock()
+	hub.closed = true
+	peers := hub.peers
+	hub.peers = make(map[*hubPeer]struct{})
+	hub.mutex.Unlock()
+
+	for peer := range peers {
+		peer.conn.Close()
+	}
+
+	return hub.listener.Close()
+}
+
+func (hub *Hub) addPeer(peer *hubPeer) bool {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	if hub.closed {
+		return false
+	}
+
+	hub.peers[peer] = struct{}{}
+	return true
+}
+
+// serve handles one peer's frames until it disconnects.
+func (hub *Hub) serve(peer *hubPeer, decoder *json.Decoder) {
+	defer hub.removePeer(peer)
+
+	for {
+		var message frame
+		if err := decoder.Decode(&message); err != nil {
+			return
+		}
+
+		switch message.Op {
+		case opSubscribe:
+			hub.subscribe(peer, message.Channel)
+			peer.send(frame{Op: opAck, Seq: message.Seq})
+		case opUnsubscribe:
+			hub.unsubscribe(peer, message.Channel)
+		case opPublish:
+			hub.publish(message)
+		default:
+			hub.logger.Warn("ignoring unknown hub frame", "op", message.Op)
+		}
+	}
+}
+
+func (hub *Hub) subscribe(peer *hubPeer, channel string) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	peers, exists := hub.channels[channel]
+	if !exists {
+		peers = make(map[*hubPeer]struct{})
+		hub.channels[channel] = peers
+	}
+	peers[peer] = struct{}{}
+	peer.channels[channel] = struct{}{}
+}
+
+func (hub *Hub) unsubscribe(peer *hubPeer, channel string) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	hub.detach(peer, channel)
+	delete(peer.channels, channel)
+}
+
+// publish forwards the frame to every peer subscribed to its channel,
+// including the one that sent it.
+func (hub *Hub) publish(message frame) {
+	hub.mutex.RLock()
+	defer hub.mutex.RUnlock()
+
+	for peer := range hub.channels[message.Channel] {
+		peer.send(message)
+	}
+}
+
+func (hub *Hub) removePeer(peer *hubPeer) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	for channel := range peer.channels {
+		hub.detach(peer, channel)
+	}
+	delete(hub.peers, peer)
+
+	peer.close()
+}
+
+// detach removes the peer from the channel. The caller must hold the lock.
+func (hub *Hub) detach(peer *hubPeer, channel string) {
+	peers, exists := hub.channels[channel]
+	if !exists {
+		return
+	}
+
+	delete(peers, peer)
+	if len(peers) == 0 {
+		delete(hub.channels, channel)
+	}
+}
+
+// hubPeer is one remote broker connected to the hub.
+type hubPeer struct {
+	conn     net.Conn
+	outbox   chan frame
+	channels map[string]struct{}
+
+	closeOnce sync.Once
+}
+
+// send queues the frame without blocking. A peer too far behind to take it
+// is disconnected; its broker reconnects and tells subscribers to resync.
+func (peer *hubPeer) send(message frame) {
+	select {
+	case peer.outbox <- message:
+	default:
+		peer.conn.Close()
+	}
+}
+
+// writeFrames writes queued frames to the peer until its outbox is closed.
+// A write that fails or outlasts writeTimeout closes the connection, which
+// ends serving the peer, and stops the writer.
+func (peer *hubPeer) writeFrames() {
+	encoder := json.NewEncoder(peer.conn)
+	for message := range peer.outbox {
+		err := peer.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err == nil {
+			err = encoder.Encode(message)
+		}
+		if err != nil {
+			peer.conn.Close()
+			return
+		}
+	}
+}
+
+func (peer *hubPeer) close() {
+	peer.closeOnce.Do(func() {
+		peer.conn.Close()
+		close(peer.outbox)
+	})
+}
@@ -1,109 +1,248 @@
-// Package pubsub provides in-process event fan-out: a generic broker plus an
-// adapter satisfying the words service's Broker contract.
+// Package pubsub provides event fan-out: a generic in-process broker, an
+// adapter satisfying the words service's Broker contract, and a TCP hub with
+// a remote broker for running several instances side by side.
 package pubsub
 
 import (
 	"context"
 	"errors"
+	"hash/maphash"
 	"sync"
 )
 
-// ErrSubscriptionClosed reports that Next was called on a closed subscription.
-var ErrSubscriptionClosed = errors.New("subscription closed")
+var (
+	// ErrSubscriptionClosed reports that Next was called on a closed subscription.
+	ErrSubscriptionClosed = errors.New("subscription closed")
+	// ErrSubscriptionLagged reports that the subscriber fell so far behind that
+	// values were dropped. The subscription stays open; values published
+	// after the drop follow.
+	ErrSubscriptionLagged = errors.New("subscription lagged and dropped values")
+)
+
+// defaultBufferSize bounds how many undelivered values a subscriber can lag
+// behind before its backlog is dropped.
+const defaultBufferSize = 16
+
+// LocalConfig tunes a local broker. Zero fields fall back to defaults.
+type LocalConfig[V any] struct {
+	// BufferSize bounds how many undelivered values each subscription holds.
+	BufferSize int
+	// Coalesce reports whether next supersedes a value already queued on the
+	// same key, in which case the queued value is discarded. Nil disables
+	// coalescing.
+	Coalesce func(queued, next V) bool
+}
 
-// subscriptionBufferSize bounds how many undelivered events a subscriber can
-// lag behind before further events are dropped for it.
-const subscriptionBufferSize = 16
+// shardCount is how many independently locked partitions a broker's index
+// is split into, so publishes and subscriptions on unrelated keys rarely
+// contend.
+const shardCount = 64
 
 // Local is an in-process broker delivering values published on keys to the
-// subscriptions listening on them.
+// subscriptions listening on them. Subscriptions are indexed by key, so a
+// publish only touches the key's own audience.
 type Local[K comparable, V any] struct {
-	mutex         sync.RWMutex
-	subscriptions map[*Subscription[V]][]K
+	config LocalConfig[V]
+	seed   maphash.Seed
+	shards [shardCount]shard[K, V]
+}
+
+// shard indexes the subscribers of the keys that hash to it.
+type shard[K comparable, V any] struct {
+	mutex       sync.RWMutex
+	subscribers map[K]map[*Subscription[K, V]]struct{}
 }
 
 // NewLocal returns an empty local broker.
-func NewLocal[K comparable, V any]() *Local[K, V] {
-	return &Local[K, V]{
-		subscriptions: make(map[*Subscription[V]][]K),
+func NewLocal[K comparable, V any](config LocalConfig[V]) *Local[K, V] {
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaultBufferSize
+	}
+
+	local := &Local[K, V]{
+		config: config,
+		seed:   maphash.MakeSeed(),
 	}
+
+	for index := range local.shards {
+		local.shards[index].subscribers = make(map[K]map[*Subscription[K, V]]struct{})
+	}
+
+	return local
 }
 
 // Publish delivers the value to every subscription listening on the key.
-// Delivery never blocks: a subscriber that has fallen more than the buffer
-// size behind misses the value.
+// Delivery never blocks: a subscriber whose buffer is full loses its whole
+// backlog and is told so by ErrSubscriptionLagged.
 func (local *Local[K, V]) Publish(ctx context.Context, key K, value V) {
-	local.mutex.RLock()
-	defer local.mutex.RUnlock()
+	shard := local.shardFor(key)
 
-	for subscription, keys := range local.subscriptions {
-		for _, subscribedKey := range keys {
-			if subscribedKey != key {
-				continue
-			}
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
 
-			select {
-			case subscription.values <- value:
-			case <-subscription.done:
-			case <-ctx.Done():
-				return
-			default:
-			}
-
-			break
-		}
+	for subscription := range shard.subscribers[key] {
+		subscription.deliver(key, value)
 	}
 }
 
-// Subscribe registers a new subscription for the given keys. The caller must
-// Close it when done.
-func (local *Local[K, V]) Subscribe(keys ...K) *Subscription[V] {
-	subscription := &Subscription[V]{
-		values: make(chan V, subscriptionBufferSize),
-		done:   make(chan struct{}),
+// Subscribe registers a new subscription for the given keys. A key listed
+// more than once is only delivered once. The caller must Close it when done.
+func (local *Local[K, V]) Subscribe(keys ...K) *Subscription[K, V] {
+	subscription := &Subscription[K, V]{
+		bufferSize: local.config.BufferSize,
+		coalesce:   local.config.Coalesce,
+		ready:      make(chan struct{}, 1),
+		done:       make(chan struct{}),
 	}
 
 	subscription.closeFunc = func() {
-		local.remove(subscription)
+		local.remove(subscription, keys)
 		close(subscription.done)
 	}
 
-	local.mutex.Lock()
-	defer local.mutex.Unlock()
-	local.subscriptions[subscription] = keys
+	for _, key := range keys {
+		shard := local.shardFor(key)
+
+		shard.mutex.Lock()
+		subscribers, exists := shard.subscribers[key]
+		if !exists {
+			subscribers = make(map[*Subscription[K, V]]struct{})
+			shard.subscribers[key] = subscribers
+		}
+		subscribers[subscription] = struct{}{}
+		shard.mutex.Unlock()
+	}
 
 	return subscription
 }
 
-func (local *Local[K, V]) remove(subscription *Subscription[V]) {
-	local.mutex.Lock()
-	defer local.mutex.Unlock()
+func (local *Local[K, V]) remove(subscription *Subscription[K, V], keys []K) {
+	for _, key := range keys {
+		shard := local.shardFor(key)
+
+		shard.mutex.Lock()
+		if subscribers, exists := shard.subscribers[key]; exists {
+			delete(subscribers, subscription)
+			if len(subscribers) == 0 {
+				delete(shard.subscribers, key)
+			}
+		}
+		shard.mutex.Unlock()
+	}
+}
 
-	delete(local.subscriptions, subscription)
+func (local *Local[K, V]) shardFor(key K) *shard[K, V] {
+	return &local.shards[maphash.Comparable(local.seed, key)%shardCount]
 }
 
 // Subscription is one subscriber's stream of published values.
-type Subscription[V any] struct {
-	values    chan V
+type Subscription[K comparable, V any] struct {
+	bufferSize int
+	coalesce   func(queued, next V) bool
+
+	mutex   sync.Mutex
+	queue   []queued[K, V]
+	lagged  bool
+	dropped int
+
+	// ready holds a token whenever values may be waiting in the queue.
+	ready     chan struct{}
 	done      chan struct{}
 	closeOnce sync.Once
 	closeFunc func()
 }
 
-// Next blocks until a value is delivered, the subscription is closed, or the
-// context ends.
-func (subscription *Subscription[V]) Next(ctx context.Context) (V, error) {
+type queued[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// deliver queues the value without blocking, coalescing it with a value it
+// supersedes or dropping the backlog if the buffer is full.
+func (subscription *Subscription[K, V]) deliver(key K, value V) {
+	subscription.mutex.Lock()
+	defer subscription.mutex.Unlock()
+
 	select {
-	case value := <-subscription.values:
-		return value, nil
 	case <-subscription.done:
-		return *new(V), ErrSubscriptionClosed
-	case <-ctx.Done():
-		return *new(V), ctx.Err()
+		return
+	default:
 	}
+
+	if subscription.coalesce != nil {
+		for index, entry := range subscription.queue {
+			if entry.key == key && subscription.coalesce(entry.value, value) {
+				subscription.queue = append(subscription.queue[:index], subscription.queue[index+1:]...)
+				break
+			}
+		}
+	}
+
+	if len(subscription.queue) >= subscription.bufferSize {
+		// a partial backlog is useless to a subscriber that has to resync
+		// anyway, so drop all of it rather than just the newest value
+		subscription.dropped += len(subscription.queue) + 1
+		subscription.queue = nil
+		subscription.lagged = true
+	} else {
+		subscription.queue = append(subscription.queue, queued[K, V]{key: key, value: value})
+	}
+
+	select {
+	case subscription.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Next blocks until a value is delivered, the subscription is closed, or the
+// context ends. After values were dropped it reports ErrSubscriptionLagged
+// once, ahead of anything published since.
+func (subscription *Subscription[K, V]) Next(ctx context.Context) (V, error) {
+	for {
+		if value, available, err := subscription.take(); available {
+			return value, err
+		}
+
+		select {
+		case <-subscription.ready:
+		case <-subscription.done:
+			return *new(V), ErrSubscriptionClosed
+		case <-ctx.Done():
+			return *new(V), ctx.Err()
+		}
+	}
+}
+
+// take pops the next value or lag report, reporting false if there is
+// nothing to take.
+func (subscription *Subscription[K, V]) take() (V, bool, error) {
+	subscription.mutex.Lock()
+	defer subscription.mutex.Unlock()
+
+	if subscription.lagged {
+		subscription.lagged = false
+		return *new(V), true, ErrSubscriptionLagged
+	}
+
+	if len(subscription.queue) == 0 {
+		return *new(V), false, nil
+	}
+
+	entry := subscription.queue[0]
+	subscription.queue = subscription.queue[1:]
+
+	return entry.value, true, nil
+}
+
+// Dropped returns how many values the subscription has lost to lagging.
+func (subscription *Subscription[K, V]) Dropped() int {
+	subscription.mutex.Lock()
+	defer subscription.mutex.Unlock()
+
+	return subscription.dropped
 }
 
 // Close removes the subscription from its broker.
-func (subscription *Subscription[V]) Close() {
+func (subscription *Subscription[K, V]) Close() {
 	subscription.closeOnce.Do(subscription.closeFunc)
 }
@@ -1,7 +1,10 @@
 package pubsub_test
 
 import (
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/carterjs/words/internal/pubsub"
 	"github.com/stretchr/testify/assert"
@@ -25,7 +28,7 @@ func TestLocal_Publish(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			local := pubsub.NewLocal[string, string]()
+			local := pubsub.NewLocal[string](pubsub.LocalConfig[string]{})
 			subscription := local.Subscribe(test.subscribeKey)
 			defer subscription.Close()
 
@@ -53,7 +56,7 @@ func TestSubscription_Close(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			local := pubsub.NewLocal[string, string]()
+			local := pubsub.NewLocal[string](pubsub.LocalConfig[string]{})
 			subscription := local.Subscribe("a")
 
 			subscription.Close()
@@ -67,3 +70,141 @@ func TestSubscription_Close(t *testing.T) {
 		})
 	}
 }
+
+func TestSubscription_Next(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		bufferSize  int
+		coalesce    func(queued, next string) bool
+		publish     []string
+		wantValues  []string
+		wantLagged  bool
+		wantDropped int
+	}{
+		{name: "delivers values in order", bufferSize: 4, publish: []string{"a", "b"}, wantValues: []string{"a", "b"}},
+		{name: "reports a lag after overflowing", bufferSize: 2, publish: []string{"a", "b", "c"}, wantLagged: true, wantDropped: 3},
+		{
+			name:       "coalesces superseded values",
+			bufferSize: 4,
+			coalesce:   func(queued, next string) bool { return queued[0] == next[0] },
+			publish:    []string{"r1", "x", "r2"},
+			wantValues: []string{"x", "r2"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			local := pubsub.NewLocal[string](pubsub.LocalConfig[string]{
+				BufferSize: test.bufferSize,
+				Coalesce:   test.coalesce,
+			})
+			subscription := local.Subscribe("a")
+			defer subscription.Close()
+
+			for _, value := range test.publish {
+				local.Publish(t.Context(), "a", value)
+			}
+
+			if test.wantLagged {
+				_, err := subscription.Next(t.Context())
+				assert.ErrorIs(t, err, pubsub.ErrSubscriptionLagged)
+				assert.Equal(t, test.wantDropped, subscription.Dropped())
+
+				// the subscription recovers for values published afterwards
+				local.Publish(t.Context(), "a", "after")
+				test.wantValues = []string{"after"}
+			}
+
+			for _, want := range test.wantValues {
+				value, err := subscription.Next(t.Context())
+				require.NoError(t, err)
+				assert.Equal(t, want, value)
+			}
+		})
+	}
+}
+
+func TestLocal_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		keys        []string
+		publishKeys []string
+		want        []string
+	}{
+		{name: "listens on every key", keys: []string{"a", "b", "c"}, publishKeys: []string{"c", "a"}, want: []string{"value:c", "value:a"}},
+		{name: "delivers once for a repeated key", keys: []string{"a", "a"}, publishKeys: []string{"a", "b"}, want: []string{"value:a"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			local := pubsub.NewLocal[string](pubsub.LocalConfig[string]{})
+			subscription := local.Subscribe(test.keys...)
+			defer subscription.Close()
+
+			for _, key := range test.publishKeys {
+				local.Publish(t.Context(), key, "value:"+key)
+			}
+
+			for _, want := range test.want {
+				value, err := subscription.Next(t.Context())
+				require.NoError(t, err)
+				assert.Equal(t, want, value)
+			}
+
+			ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+			defer cancel()
+			_, err := subscription.Next(ctx)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		})
+	}
+}
+
+// BenchmarkLocal_Publish publishes to one game's audience while the broker
+// holds a growing number of idle subscribers on other games. Publish cost
+// should stay flat as the idle count grows.
+func BenchmarkLocal_Publish(b *testing.B) {
+	const (
+		audience       = 4
+		playersPerGame = 4
+	)
+
+	for _, idle := range []int{0, 1_000, 100_000} {
+		b.Run(fmt.Sprintf("idle=%d", idle), func(b *testing.B) {
+			local := pubsub.NewLocal[string](pubsub.LocalConfig[string]{})
+
+			// idle subscribers spread across games, each on its game channel
+			// and its own player channel like the words service subscribes
+			for index := range idle {
+				game := fmt.Sprintf("game:%d", index/playersPerGame)
+				subscription := local.Subscribe(game, fmt.Sprintf("%s:player:%d", game, index))
+				b.Cleanup(subscription.Close)
+			}
+
+			active := make([]*pubsub.Subscription[string, string], audience)
+			for index := range active {
+				active[index] = local.Subscribe("game:active")
+				b.Cleanup(active[index].Close)
+			}
+
+			ctx := context.Background()
+
+			b.ResetTimer()
+			for range b.N {
+				local.Publish(ctx, "game:active", "value")
+				for _, subscription := range active {
+					if _, err := subscription.Next(ctx); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
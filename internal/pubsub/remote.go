@@ -0,0 +1,396 @@
+package pubsub
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/carterjs/words/internal/words"
+)
+
+const (
+	// subscribeTimeout bounds how long Subscribe waits for the hub to confirm
+	// a new channel before handing back the subscription anyway.
+	subscribeTimeout = 5 * time.Second
+	// redialInterval is how long a remote broker waits between attempts to
+	// reach a hub it lost.
+	redialInterval = time.Second
+	// remoteOutboxSize bounds how many frames a remote broker queues for the
+	// hub before treating the connection as stalled.
+	remoteOutboxSize = 1024
+	// writeTimeout bounds how long writing one frame to the hub may take.
+	writeTimeout = 5 * time.Second
+)
+
+// Remote is a broker for game events whose publishes go through a hub, so
+// subscribers on every instance connected to it see them. Delivery to this
+// instance's subscribers happens through an embedded local broker, with the
+// same buffering, coalescing and lag reporting as GameBroker.
+type Remote struct {
+	address string
+	secret  string
+	logger  *slog.Logger
+	local   *Local[string, words.Event]
+
+	mutex    sync.Mutex
+	conn     net.Conn
+	outbox   chan frame
+	seq      uint64
+	channels map[string]*remoteChannel
+	pending  map[uint64]*remoteChannel
+	closed   bool
+	// dropped counts events that never reached the hub since the last
+	// connection was restored.
+	dropped int
+}
+
+// remoteChannel tracks one channel's local subscribers. ready is closed once
+// the hub has confirmed the subscription.
+type remoteChannel struct {
+	name  string
+	refs  int
+	ready chan struct{}
+	acked bool
+}
+
+// DialRemote connects to the hub at the address, introducing itself with
+// the hub's secret. If the connection later drops, the broker keeps
+// redialing and tells subscribers to resync once it is back.
+func DialRemote(ctx context.Context, address, secret string, config Config, logger *slog.Logger) (*Remote, error) {
+	if secret == "" {
+		return nil, ErrHubSecretRequired
+	}
+
+	remote := &Remote{
+		address: address,
+		secret:  secret,
+		logger:  logger,
+		local: NewLocal[string](LocalConfig[words.Event]{
+			BufferSize: config.BufferSize,
+			Coalesce:   supersedes,
+		}),
+		channels: make(map[string]*remoteChannel),
+		pending:  make(map[uint64]*remoteChannel),
+	}
+
+	conn, decoder, err := remote.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	remote.mutex.Lock()
+	remote.attach(conn)
+	remote.mutex.Unlock()
+
+	go remote.receive(conn, decoder)
+
+	return remote, nil
+}
+
+// Publish queues the event for the hub, which delivers it to every instance
+// subscribed to the channel, this one included. It never waits on the
+// network. Events published while the hub is unreachable, or while it is too
+// far behind to take them, are lost and counted; subscribers are told to
+// resync on reconnect.
+func (remote *Remote) Publish(ctx context.Context, channel string, event words.Event) {
+	remote.mutex.Lock()
+	defer remote.mutex.Unlock()
+
+	if err := remote.send(frame{Op: opPublish, Channel: channel, Event: &event}); err != nil {
+		remote.dropped++
+		remote.logger.Debug("publishing to hub", "channel", channel, "error", err)
+	}
+}
+
+// Subscribe returns a subscription covering all the given channels. It
+// waits for the hub to confirm channels this instance wasn't already
+// subscribed to, so events published anywhere afterwards are delivered.
+func (remote *Remote) Subscribe(ctx context.Context, channels ...string) words.Subscription {
+	// subscribe locally first so nothing relayed after the hub's confirmation
+	// can slip past
+	subscription := remote.local.Subscribe(channels...)
+
+	remote.mutex.Lock()
+	var waiting []*remoteChannel
+	for _, name := range channels {
+		channel, exists := remote.channels[name]
+		if !exists {
+			channel = &remoteChannel{name: name, ready: make(chan struct{})}
+			remote.channels[name] = channel
+
+			if err := remote.requestSubscription(channel); err != nil {
+				remote.logger.Error("subscribing through hub", "channel", name, "error", err)
+			}
+		}
+
+		channel.refs++
+		waiting = append(waiting, channel)
+	}
+	remote.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, subscribeTimeout)
+	defer cancel()
+
+	for _, channel := range waiting {
+		select {
+		case <-channel.ready:
+		case <-ctx.Done():
+			remote.logger.Warn("hub did not confirm subscription", "channel", channel.name)
+		}
+	}
+
+	return &remoteSubscription{
+		gameSubscription: gameSubscription{subscription: subscription},
+		remote:           remote,
+		channels:         channels,
+	}
+}
+
+// Close disconnects from the hub and stops redialing.
+func (remote *Remote) Close() error {
+	remote.mutex.Lock()
+	defer remote.mutex.Unlock()
+
+	remote.closed = true
+	if remote.conn == nil {
+		return nil
+	}
+
+	return remote.conn.Close()
+}
+
+// release drops a closed subscription's claim on its channels, leaving the
+// hub channels nobody here listens to anymore.
+func (remote *Remote) release(channels []string) {
+	remote.mutex.Lock()
+	defer remote.mutex.Unlock()
+
+	for _, name := range channels {
+		channel, exists := remote.channels[name]
+		if !exists {
+			continue
+		}
+
+		channel.refs--
+		if channel.refs > 0 {
+			continue
+		}
+
+		delete(remote.channels, name)
+		if err := remote.send(frame{Op: opUnsubscribe, Channel: name}); err != nil {
+			remote.logger.Debug("unsubscribing through hub", "channel", name, "error", err)
+		}
+	}
+}
+
+// requestSubscription asks the hub for the channel. The caller must hold the
+// lock.
+func (remote *Remote) requestSubscription(channel *remoteChannel) error {
+	remote.seq++
+	remote.pending[remote.seq] = channel
+
+	return remote.send(frame{Op: opSubscribe, Seq: remote.seq, Channel: channel.name})
+}
+
+// send queues a frame for the hub without blocking. A connection too far
+// behind to take it is dropped, so the broker reconnects and subscribers
+// resync. The caller must hold the lock.
+func (remote *Remote) send(message frame) error {
+	if remote.outbox == nil {
+		return fmt.Errorf("hub %s unreachable", remote.address)
+	}
+
+	select {
+	case remote.outbox <- message:
+		return nil
+	default:
+		remote.conn.Close()
+		return fmt.Errorf("hub %s stalled", remote.address)
+	}
+}
+
+// attach starts using a fresh connection. The caller must hold the lock.
+func (remote *Remote) attach(conn net.Conn) {
+	remote.conn = conn
+	remote.outbox = make(chan frame, remoteOutboxSize)
+
+	go writeFrames(conn, remote.outbox)
+}
+
+// detach stops using the current connection, ending its writer. The caller
+// must hold the lock.
+func (remote *Remote) detach() {
+	if remote.outbox != nil {
+		close(remote.outbox)
+	}
+
+	remote.conn, remote.outbox = nil, nil
+}
+
+// writeFrames writes queued frames to the hub until the outbox is closed.
+// A write that fails or outlasts writeTimeout closes the connection, which
+// the receiving side notices and reconnects from, and stops the writer.
+func writeFrames(conn net.Conn, outbox <-chan frame) {
+	encoder := json.NewEncoder(conn)
+	for message := range outbox {
+		err := conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err == nil {
+			err = encoder.Encode(message)
+		}
+		if err != nil {
+			conn.Close()
+			return
+		}
+	}
+}
+
+// dial connects to the hub and completes the handshake, returning the
+// decoder to keep reading the connection with.
+func (remote *Remote) dial(ctx context.Context) (net.Conn, *json.Decoder, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", remote.address)
+	if err != nil {
+		return nil, nil, fmt.Errorf("dialing hub: %w", err)
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(conn))
+	if err := remote.handshake(conn, decoder); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("greeting hub: %w", err)
+	}
+
+	return conn, decoder, nil
+}
+
+// handshake sends the hub the secret and waits to be welcomed. A hub that
+// does not recognize the secret hangs up instead.
+func (remote *Remote) handshake(conn net.Conn, decoder *json.Decoder) error {
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return err
+	}
+
+	if err := json.NewEncoder(conn).Encode(frame{Op: opHello, Secret: remote.secret}); err != nil {
+		return err
+	}
+
+	var welcome frame
+	if err := decoder.Decode(&welcome); err != nil {
+		return fmt.Errorf("hub rejected the connection: %w", err)
+	}
+
+	if welcome.Op != opWelcome {
+		return fmt.Errorf("unexpected %q frame", welcome.Op)
+	}
+
+	return conn.SetDeadline(time.Time{})
+}
+
+// receive relays frames from the hub to local subscribers until the
+// connection drops, then reconnects.
+func (remote *Remote) receive(conn net.Conn, decoder *json.Decoder) {
+	for {
+		var message frame
+		if err := decoder.Decode(&message); err != nil {
+			break
+		}
+
+		switch message.Op {
+		case opAck:
+			remote.acknowledge(message.Seq)
+		case opPublish:
+			if message.Event != nil {
+				remote.local.Publish(context.Background(), message.Channel, *message.Event)
+			}
+		}
+	}
+
+	conn.Close()
+	remote.reconnect()
+}
+
+func (remote *Remote) acknowledge(seq uint64) {
+	remote.mutex.Lock()
+	defer remote.mutex.Unlock()
+
+	channel, exists := remote.pending[seq]
+	if !exists {
+		return
+	}
+
+	delete(remote.pending, seq)
+	if !channel.acked {
+		channel.acked = true
+		close(channel.ready)
+	}
+}
+
+// reconnect redials the hub until it answers or the broker is closed, then
+// restores this instance's channels. Anything published in between is gone,
+// so every subscriber is told to resync.
+func (remote *Remote) reconnect() {
+	remote.mutex.Lock()
+	remote.detach()
+	remote.mutex.Unlock()
+
+	for {
+		remote.mutex.Lock()
+		closed := remote.closed
+		remote.mutex.Unlock()
+
+		if closed {
+			return
+		}
+
+		conn, decoder, err := remote.dial(context.Background())
+		if err == nil {
+			remote.restore(conn)
+			go remote.receive(conn, decoder)
+			return
+		}
+
+		remote.logger.Warn("hub unreachable", "address", remote.address, "error", err)
+		time.Sleep(redialInterval)
+	}
+}
+
+func (remote *Remote) restore(conn net.Conn) {
+	remote.mutex.Lock()
+	defer remote.mutex.Unlock()
+
+	remote.attach(conn)
+	clear(remote.pending)
+
+	if remote.dropped > 0 {
+		remote.logger.Warn("events lost while the hub was unreachable", "address", remote.address, "dropped", remote.dropped)
+		remote.dropped = 0
+	}
+
+	for name, channel := range remote.channels {
+		if err := remote.requestSubscription(channel); err != nil {
+			remote.logger.Error("restoring hub subscription", "channel", name, "error", err)
+		}
+
+		remote.local.Publish(context.Background(), name, words.Event{Type: words.EventTypeResyncRequired})
+	}
+}
+
+// remoteSubscription releases its channels on the hub when closed.
+type remoteSubscription struct {
+	gameSubscription
+	remote    *Remote
+	channels  []string
+	closeOnce sync.Once
+}
+
+// Close removes the subscription from the broker.
+func (subscription *remoteSubscription) Close() {
+	subscription.closeOnce.Do(func() {
+		subscription.gameSubscription.Close()
+		subscription.remote.release(subscription.channels)
+	})
+}
@@ -0,0 +1,203 @@
+package pubsub_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carterjs/words/internal/pubsub"
+	"github.com/carterjs/words/internal/words"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemote_Publish(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		subscribeTo    []string
+		publishChannel string
+		wantDelivered  bool
+	}{
+		{name: "delivers across instances", subscribeTo: []string{"game:1"}, publishChannel: "game:1", wantDelivered: true},
+		{name: "delivers private player channels", subscribeTo: []string{"game:1", "game:1:player:2"}, publishChannel: "game:1:player:2", wantDelivered: true},
+		{name: "skips other players' channels", subscribeTo: []string{"game:1", "game:1:player:2"}, publishChannel: "game:1:player:3"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			address := startHub(t)
+			publisher := dialRemote(t, address)
+			subscriber := dialRemote(t, address)
+
+			subscription := subscriber.Subscribe(t.Context(), test.subscribeTo...)
+			defer subscription.Close()
+
+			event := words.Event{ID: 1, Type: words.EventTypeTurnPassed}
+			publisher.Publish(t.Context(), test.publishChannel, event)
+
+			ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+			defer cancel()
+
+			received, err := subscription.Next(ctx)
+			if !test.wantDelivered {
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, event.ID, received.ID)
+			assert.Equal(t, event.Type, received.Type)
+		})
+	}
+}
+
+func TestRemote_Publish_StalledHub(t *testing.T) {
+	t.Parallel()
+
+	// a hub that welcomes the remote and then never reads another frame
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		t.Cleanup(func() { conn.Close() })
+
+		if _, err := bufio.NewReader(conn).ReadString('\n'); err != nil {
+			return
+		}
+		fmt.Fprintln(conn, `{"op":"welcome"}`)
+	}()
+
+	remote := dialRemote(t, listener.Addr().String())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		payload := json.RawMessage(`"` + strings.Repeat("x", 1024) + `"`)
+		for id := range uint64(10_000) {
+			remote.Publish(t.Context(), "game:1", words.Event{ID: id + 1, Type: words.EventTypeTurnPassed, Payload: payload})
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publishing blocked on a stalled hub")
+	}
+}
+
+func TestRemote_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+	}{
+		{name: "asks subscribers to resync after the hub connection drops"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			hub, err := pubsub.ListenHub("127.0.0.1:0", testHubSecret, slog.New(slog.DiscardHandler))
+			require.NoError(t, err)
+			go hub.Serve()
+
+			address := hub.Addr().String()
+			remote := dialRemote(t, address)
+
+			subscription := remote.Subscribe(t.Context(), "game:1")
+			defer subscription.Close()
+
+			// restart the hub on the same address to sever the connection
+			require.NoError(t, hub.Close())
+			replacement, err := pubsub.ListenHub(address, testHubSecret, slog.New(slog.DiscardHandler))
+			require.NoError(t, err)
+			go replacement.Serve()
+			t.Cleanup(func() { replacement.Close() })
+
+			ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+			defer cancel()
+
+			received, err := subscription.Next(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, words.EventTypeResyncRequired, received.Type)
+
+			// the channel is restored, so publishes flow again
+			remote.Publish(t.Context(), "game:1", words.Event{Type: words.EventTypeTurnPassed})
+			received, err = subscription.Next(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, words.EventTypeTurnPassed, received.Type)
+		})
+	}
+}
+
+func TestDialRemote(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr bool
+	}{
+		{name: "joins a hub with its secret", secret: testHubSecret},
+		{name: "is turned away with the wrong secret", secret: "guess", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			address := startHub(t)
+
+			remote, err := pubsub.DialRemote(t.Context(), address, test.secret, pubsub.Config{}, slog.New(slog.DiscardHandler))
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NoError(t, remote.Close())
+		})
+	}
+}
+
+// testHubSecret is the secret test hubs and their remotes share.
+const testHubSecret = "test-secret"
+
+func startHub(t *testing.T) string {
+	t.Helper()
+
+	hub, err := pubsub.ListenHub("127.0.0.1:0", testHubSecret, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	t.Cleanup(func() { hub.Close() })
+
+	go hub.Serve()
+
+	return hub.Addr().String()
+}
+
+func dialRemote(t *testing.T, address string) *pubsub.Remote {
+	t.Helper()
+
+	remote, err := pubsub.DialRemote(t.Context(), address, testHubSecret, pubsub.Config{}, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	t.Cleanup(func() { remote.Close() })
+
+	return remote
+}
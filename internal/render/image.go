@@ -0,0 +1,152 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"sync"
+
+	"github.com/carterjs/words/internal/words"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// fonts parses the embedded Go fonts once. Parsed fonts are safe to share,
+// but the faces drawn with them are not, so each image gets its own.
+var fonts = sync.OnceValues(func() (map[bool]*opentype.Font, error) {
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("parsing regular font: %w", err)
+	}
+
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("parsing bold font: %w", err)
+	}
+
+	return map[bool]*opentype.Font{false: regular, true: bold}, nil
+})
+
+// faces holds the sized font faces one drawing uses.
+type faces struct {
+	letter   font.Face
+	points   font.Face
+	modifier font.Face
+	label    font.Face
+}
+
+func newFaces() (*faces, error) {
+	parsed, err := fonts()
+	if err != nil {
+		return nil, err
+	}
+
+	sized := func(bold bool, size float64) (font.Face, error) {
+		return opentype.NewFace(parsed[bold], &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	}
+
+	var drawing faces
+	for _, face := range []struct {
+		target *font.Face
+		bold   bool
+		size   float64
+	}{
+		{&drawing.letter, true, letterFontSize},
+		{&drawing.points, false, pointsFontSize},
+		{&drawing.modifier, false, modifierFontSize},
+		{&drawing.label, false, labelFontSize},
+	} {
+		if *face.target, err = sized(face.bold, face.size); err != nil {
+			return nil, fmt.Errorf("sizing font: %w", err)
+		}
+	}
+
+	return &drawing, nil
+}
+
+// PNG writes the window of the board as a PNG image, using config for letter
+// values.
+func PNG(w io.Writer, board *words.Board, config words.Config, window Window) error {
+	if err := window.Validate(); err != nil {
+		return err
+	}
+
+	drawing, err := newFaces()
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(w, drawing.board(board, config, window)); err != nil {
+		return fmt.Errorf("encoding image: %w", err)
+	}
+
+	return nil
+}
+
+// board draws the window of the board onto a new image.
+func (drawing *faces) board(board *words.Board, config words.Config, window Window) *image.RGBA {
+	width, height := window.size()
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(canvas, canvas.Bounds(), backgroundColor)
+
+	for column := window.MinX; column <= window.MaxX; column++ {
+		x, _ := window.origin(column, window.MinY)
+		drawText(canvas, drawing.label, labelInkColor, x+cellSize/2, labelSize/2, fmt.Sprint(column), alignCenter)
+	}
+	for row := window.MinY; row <= window.MaxY; row++ {
+		_, y := window.origin(window.MinX, row)
+		drawText(canvas, drawing.label, labelInkColor, labelSize/2, y+cellSize/2, fmt.Sprint(row), alignCenter)
+	}
+
+	side := cellSize - cellGap
+	for _, current := range cells(board, config, window) {
+		x, y := window.origin(current.column, current.row)
+		fillRect(canvas, image.Rect(x, y, x+side, y+side), current.fill())
+
+		switch {
+		case current.occupied:
+			drawText(canvas, drawing.letter, current.ink(), x+side/2, y+side/2, string(current.letter), alignCenter)
+			if !current.blank {
+				drawText(canvas, drawing.points, current.ink(), x+side-3, y+side-pointsFontSize/2-2, fmt.Sprint(current.points), alignEnd)
+			}
+		case current.modifier != "":
+			drawText(canvas, drawing.modifier, current.ink(), x+side/2, y+side/2, string(current.modifier), alignCenter)
+		}
+	}
+
+	return canvas
+}
+
+func fillRect(canvas draw.Image, rect image.Rectangle, fill color.Color) {
+	draw.Draw(canvas, rect, image.NewUniform(fill), image.Point{}, draw.Src)
+}
+
+type alignment int
+
+const (
+	alignCenter alignment = iota
+	alignEnd
+)
+
+// drawText draws text centred vertically on y and, by alignment, centred on
+// or ending at x.
+func drawText(canvas draw.Image, face font.Face, ink color.Color, x, y int, text string, align alignment) {
+	drawer := font.Drawer{Dst: canvas, Src: image.NewUniform(ink), Face: face}
+
+	width := drawer.MeasureString(text)
+	metrics := face.Metrics()
+
+	dotX := fixed.I(x) - width/2
+	if align == alignEnd {
+		dotX = fixed.I(x) - width
+	}
+
+	drawer.Dot = fixed.Point26_6{X: dotX, Y: fixed.I(y) + metrics.CapHeight/2}
+	drawer.DrawString(text)
+}
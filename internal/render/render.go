@@ -0,0 +1,175 @@
+// Package render draws boards as SVG documents and PNG images, and a game's
+// moves as an animated GIF, so positions can be shared without the web
+// client. Every renderer draws the same layout: a window of cells with
+// column and row numbers along the top and left, modifiers on empty cells,
+// and placed tiles showing their letter and its value.
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+
+	"github.com/carterjs/words/internal/words"
+)
+
+// MaxWindowSide is the most cells a window may span in either direction.
+const MaxWindowSide = 100
+
+// ErrInvalidWindow is returned for windows that are empty, inverted or
+// wider than MaxWindowSide.
+var ErrInvalidWindow = errors.New("invalid window")
+
+const (
+	// cellSize is the width and height of one cell, in pixels.
+	cellSize = 36
+	// cellGap separates neighbouring cells, showing the background as grid
+	// lines.
+	cellGap = 2
+	// labelSize is the width of the row numbers and the height of the
+	// column numbers.
+	labelSize = 28
+
+	letterFontSize   = 20
+	pointsFontSize   = 10
+	modifierFontSize = 11
+	labelFontSize    = 11
+)
+
+// Window is the rectangle of cells to draw, inclusive on every side.
+type Window struct {
+	MinX int
+	MinY int
+	MaxX int
+	MaxY int
+}
+
+// Validate reports ErrInvalidWindow when the window spans no cells or more
+// than MaxWindowSide in either direction.
+func (window Window) Validate() error {
+	if window.columns() < 1 || window.rows() < 1 || window.columns() > MaxWindowSide || window.rows() > MaxWindowSide {
+		return fmt.Errorf("%w: %d,%d to %d,%d", ErrInvalidWindow, window.MinX, window.MinY, window.MaxX, window.MaxY)
+	}
+
+	return nil
+}
+
+func (window Window) columns() int {
+	return window.MaxX - window.MinX + 1
+}
+
+func (window Window) rows() int {
+	return window.MaxY - window.MinY + 1
+}
+
+// size returns the width and height of the drawing in pixels.
+func (window Window) size() (int, int) {
+	return labelSize + window.columns()*cellSize, labelSize + window.rows()*cellSize
+}
+
+// origin returns the top-left pixel of the cell at the given column and row.
+func (window Window) origin(column, row int) (int, int) {
+	return labelSize + (column-window.MinX)*cellSize + cellGap/2, labelSize + (row-window.MinY)*cellSize + cellGap/2
+}
+
+// cell is everything drawn in one square of the board.
+type cell struct {
+	column   int
+	row      int
+	letter   rune
+	occupied bool
+	blank    bool
+	points   int
+	modifier words.Modifier
+}
+
+// cells lists every cell in the window, row by row.
+func cells(board *words.Board, config words.Config, window Window) []cell {
+	listed := make([]cell, 0, window.columns()*window.rows())
+
+	for row := window.MinY; row <= window.MaxY; row++ {
+		for column := window.MinX; column <= window.MaxX; column++ {
+			point := words.NewPoint(column, row)
+			current := cell{column: column, row: row}
+
+			current.letter, current.occupied = board.Letter(point)
+			if current.occupied {
+				current.blank = board.Blank(point)
+				if !current.blank {
+					current.points = config.LetterPoints[current.letter]
+				}
+			}
+
+			current.modifier, _ = board.Modifier(point)
+
+			listed = append(listed, current)
+		}
+	}
+
+	return listed
+}
+
+// fill returns the colour of the cell's square.
+func (current cell) fill() color.RGBA {
+	if current.occupied {
+		return tileColor
+	}
+
+	if fill, hasModifier := modifierColors[current.modifier]; hasModifier {
+		return fill
+	}
+
+	if current.column == 0 && current.row == 0 {
+		return centerColor
+	}
+
+	return emptyColor
+}
+
+// ink returns the colour of the cell's text.
+func (current cell) ink() color.RGBA {
+	switch {
+	case current.blank:
+		return blankInkColor
+	case current.occupied:
+		return tileInkColor
+	case current.modifier == words.ModifierTripleLetter || current.modifier == words.ModifierTripleWord:
+		return lightInkColor
+	default:
+		return darkInkColor
+	}
+}
+
+var (
+	backgroundColor = color.RGBA{R: 0xd8, G: 0xd2, B: 0xc4, A: 0xff}
+	emptyColor      = color.RGBA{R: 0xf4, G: 0xef, B: 0xe3, A: 0xff}
+	centerColor     = color.RGBA{R: 0xe6, G: 0xd9, B: 0xb8, A: 0xff}
+	tileColor       = color.RGBA{R: 0xf7, G: 0xd5, B: 0x8b, A: 0xff}
+	tileInkColor    = color.RGBA{R: 0x2b, G: 0x21, B: 0x18, A: 0xff}
+	blankInkColor   = color.RGBA{R: 0x9a, G: 0x6b, B: 0x2f, A: 0xff}
+	darkInkColor    = color.RGBA{R: 0x4a, G: 0x45, B: 0x3c, A: 0xff}
+	lightInkColor   = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	labelInkColor   = color.RGBA{R: 0x6b, G: 0x66, B: 0x5c, A: 0xff}
+
+	modifierColors = map[words.Modifier]color.RGBA{
+		words.ModifierDoubleLetter: {R: 0xbc, G: 0xdf, B: 0xf1, A: 0xff},
+		words.ModifierTripleLetter: {R: 0x4f, G: 0x9f, B: 0xd1, A: 0xff},
+		words.ModifierDoubleWord:   {R: 0xf4, G: 0xb6, B: 0xb6, A: 0xff},
+		words.ModifierTripleWord:   {R: 0xd9, G: 0x53, B: 0x4f, A: 0xff},
+	}
+)
+
+// solidColors lists every solid colour the renderers use.
+func solidColors() color.Palette {
+	colors := color.Palette{
+		backgroundColor, emptyColor, centerColor, tileColor, tileInkColor,
+		blankInkColor, darkInkColor, lightInkColor, labelInkColor,
+	}
+	for _, modifier := range []words.Modifier{
+		words.ModifierDoubleLetter, words.ModifierTripleLetter, words.ModifierDoubleWord, words.ModifierTripleWord,
+	} {
+		colors = append(colors, modifierColors[modifier])
+	}
+
+	return colors
+}
@@ -0,0 +1,258 @@
+package render_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"image/gif"
+	"image/png"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/carterjs/words/internal/pattern"
+	"github.com/carterjs/words/internal/render"
+	"github.com/carterjs/words/internal/words"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindow_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		window  render.Window
+		wantErr error
+	}{
+		{name: "accepts a single cell", window: render.Window{}},
+		{name: "accepts the widest window", window: render.Window{MinX: -50, MaxX: 49, MinY: -50, MaxY: 49}},
+		{name: "rejects an inverted window", window: render.Window{MinX: 1, MaxX: 0}, wantErr: render.ErrInvalidWindow},
+		{name: "rejects a window too wide", window: render.Window{MinX: -50, MaxX: 50}, wantErr: render.ErrInvalidWindow},
+		{name: "rejects a window too tall", window: render.Window{MinY: 0, MaxY: render.MaxWindowSide}, wantErr: render.ErrInvalidWindow},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := test.window.Validate()
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSVG(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		blanks      bool
+		window      render.Window
+		wantText    []string
+		notWantText []string
+		wantErr     error
+	}{
+		{
+			name:     "draws letters with their values",
+			window:   render.Window{MinX: -1, MinY: -1, MaxX: 3, MaxY: 3},
+			wantText: []string{">A</text>", ">2</text>", ">-1</text>", ">TW</text>"},
+		},
+		{
+			name:        "draws blanks without values",
+			blanks:      true,
+			window:      render.Window{MinX: 0, MinY: -1, MaxX: 1, MaxY: 0},
+			wantText:    []string{">A</text>"},
+			notWantText: []string{">2</text>", ">TW</text>"},
+		},
+		{
+			name:        "draws only the window",
+			window:      render.Window{MinX: 5, MinY: 5, MaxX: 6, MaxY: 6},
+			wantText:    []string{">5</text>", ">6</text>"},
+			notWantText: []string{">A</text>", ">TW</text>"},
+		},
+		{
+			name:    "refuses an invalid window",
+			window:  render.Window{MinX: 1, MaxX: 0},
+			wantErr: render.ErrInvalidWindow,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			game := newRenderedGame(t, test.blanks)
+
+			var document bytes.Buffer
+			err := render.SVG(&document, game.Board(), game.Config(), test.window)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			decoder := xml.NewDecoder(bytes.NewReader(document.Bytes()))
+			for {
+				_, err := decoder.Token()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+			}
+
+			for _, text := range test.wantText {
+				assert.Contains(t, document.String(), text)
+			}
+			for _, text := range test.notWantText {
+				assert.NotContains(t, document.String(), text)
+			}
+		})
+	}
+}
+
+func TestPNG(t *testing.T) {
+	t.Parallel()
+
+	game := newRenderedGame(t, false)
+	window := render.Window{MinX: -1, MinY: -1, MaxX: 3, MaxY: 3}
+
+	var encoded bytes.Buffer
+	require.NoError(t, render.PNG(&encoded, game.Board(), game.Config(), window))
+
+	image, err := png.Decode(&encoded)
+	require.NoError(t, err)
+
+	bounds := image.Bounds()
+	assert.Greater(t, bounds.Dx(), 0)
+	assert.Equal(t, bounds.Dx(), bounds.Dy(), "a square window draws a square image")
+
+	wider := window
+	wider.MaxX++
+	encoded.Reset()
+	require.NoError(t, render.PNG(&encoded, game.Board(), game.Config(), wider))
+	widerImage, err := png.Decode(&encoded)
+	require.NoError(t, err)
+	assert.Greater(t, widerImage.Bounds().Dx(), bounds.Dx())
+	assert.Equal(t, bounds.Dy(), widerImage.Bounds().Dy())
+
+	assert.ErrorIs(t, render.PNG(io.Discard, game.Board(), game.Config(), render.Window{MinY: 1}), render.ErrInvalidWindow)
+}
+
+func TestReplayGIF(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		game       func(t *testing.T) *words.Game
+		wantFrames int
+		wantErr    error
+	}{
+		{
+			name: "draws an empty board and every play",
+			game: func(t *testing.T) *words.Game {
+				return newRenderedGame(t, false)
+			},
+			wantFrames: 3,
+		},
+		{
+			name: "draws an empty board for a game not yet played",
+			game: func(t *testing.T) *words.Game {
+				return newGame(t, renderConfig(false))
+			},
+			wantFrames: 1,
+		},
+		{
+			name: "refuses a game without a move history",
+			game: func(t *testing.T) *words.Game {
+				state := newRenderedGame(t, false).State()
+				state.Moves = nil
+
+				game, err := words.NewGameFromState(state)
+				require.NoError(t, err)
+
+				return game
+			},
+			wantErr: words.ErrNoMoveHistory,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var encoded bytes.Buffer
+			err := render.ReplayGIF(&encoded, test.game(t), render.Window{MinX: -2, MinY: -2, MaxX: 2, MaxY: 2}, 500*time.Millisecond)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			animation, err := gif.DecodeAll(&encoded)
+			require.NoError(t, err)
+			require.Len(t, animation.Image, test.wantFrames)
+			for _, delay := range animation.Delay[:test.wantFrames-1] {
+				assert.Equal(t, 50, delay)
+			}
+			assert.Equal(t, 200, animation.Delay[test.wantFrames-1], "the last frame is held")
+		})
+	}
+}
+
+// newRenderedGame returns a game with AA played across the centre and AA
+// played down into it, and a triple word modifier at 3,3. With blanks,
+// every letter is played from a blank.
+func newRenderedGame(t *testing.T, blanks bool) *words.Game {
+	t.Helper()
+
+	game := newGame(t, renderConfig(blanks))
+	for _, word := range []words.Word{
+		words.NewWord(words.NewPoint(0, 0), words.DirectionHorizontal, "AA"),
+		words.NewWord(words.NewPoint(0, -1), words.DirectionVertical, "AA"),
+	} {
+		if blanks {
+			word = word.WithBlanks(word.Start(), words.NewPoint(1, 0))
+		}
+
+		_, err := game.PlayWord(game.CurrentPlayerID(), word)
+		require.NoError(t, err)
+	}
+
+	return game
+}
+
+func newGame(t *testing.T, config words.Config) *words.Game {
+	t.Helper()
+
+	game := words.NewGame(config)
+	for _, name := range []string{"one", "two"} {
+		_, err := game.AddPlayer(name)
+		require.NoError(t, err)
+	}
+	require.NoError(t, game.Start())
+
+	return game
+}
+
+// renderConfig deals only As, worth 2 points, or only blanks, so every rack
+// can make the plays newRenderedGame needs.
+func renderConfig(blanks bool) words.Config {
+	distribution := map[rune]int{'A': 16}
+	if blanks {
+		distribution = map[rune]int{words.BlankLetter: 16}
+	}
+
+	return words.Config{
+		LetterDistribution: distribution,
+		LetterPoints:       map[rune]int{'A': 2, words.BlankLetter: 0},
+		RackSize:           3,
+		Modifiers: pattern.Group[words.Modifier]{
+			{Value: words.ModifierTripleWord, Grids: []pattern.Grid{{X: 3, Y: 3, Width: 100, Height: 100}}},
+		},
+	}
+}
@@ -0,0 +1,69 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+
+	"github.com/carterjs/words/internal/words"
+)
+
+// finalFrameHold is how many frame delays the last frame stays on screen
+// before the replay loops.
+const finalFrameHold = 4
+
+// ReplayGIF writes the window of the game's board as an animated GIF: an
+// empty board, then one frame for every move that changed it, each shown for
+// delay. Games played before moves were recorded are reported as
+// words.ErrNoMoveHistory.
+func ReplayGIF(w io.Writer, game *words.Game, window Window, delay time.Duration) error {
+	if err := window.Validate(); err != nil {
+		return err
+	}
+
+	drawing, err := newFaces()
+	if err != nil {
+		return err
+	}
+
+	// solid colours come first so fills keep their exact colour, and the
+	// rest of the palette approximates anti-aliased text edges
+	colors := append(solidColors(), palette.Plan9...)[:256]
+
+	config := game.Config()
+	centiseconds := max(1, int(delay/(10*time.Millisecond)))
+
+	animation := &gif.GIF{}
+	addFrame := func(board *words.Board) {
+		rendered := drawing.board(board, config, window)
+		frame := image.NewPaletted(rendered.Bounds(), colors)
+		draw.Draw(frame, frame.Bounds(), rendered, image.Point{}, draw.Src)
+
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, centiseconds)
+	}
+
+	addFrame(words.NewBoard(config))
+	err = game.ReplayMoves(func(entry words.TranscriptEntry, board *words.Board) error {
+		if entry.Type == words.MoveTypePlay || entry.Type == words.MoveTypeWithdrawn {
+			addFrame(board)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("replaying moves: %w", err)
+	}
+
+	animation.Delay[len(animation.Delay)-1] *= finalFrameHold
+
+	if err := gif.EncodeAll(w, animation); err != nil {
+		return fmt.Errorf("encoding animation: %w", err)
+	}
+
+	return nil
+}
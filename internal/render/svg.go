@@ -0,0 +1,70 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+
+	"github.com/carterjs/words/internal/words"
+)
+
+// SVG writes the window of the board as a standalone SVG document, using
+// config for letter values.
+func SVG(w io.Writer, board *words.Board, config words.Config, window Window) error {
+	if err := window.Validate(); err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(w)
+	width, height := window.size()
+
+	fmt.Fprintf(writer, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(writer, `<rect width="%d" height="%d" fill="%s"/>`+"\n", width, height, hex(backgroundColor))
+
+	for column := window.MinX; column <= window.MaxX; column++ {
+		x, _ := window.origin(column, window.MinY)
+		writeSVGText(writer, x+cellSize/2, labelSize/2, labelFontSize, labelInkColor, "middle", false, fmt.Sprint(column))
+	}
+	for row := window.MinY; row <= window.MaxY; row++ {
+		_, y := window.origin(window.MinX, row)
+		writeSVGText(writer, labelSize/2, y+cellSize/2, labelFontSize, labelInkColor, "middle", false, fmt.Sprint(row))
+	}
+
+	side := cellSize - cellGap
+	for _, current := range cells(board, config, window) {
+		x, y := window.origin(current.column, current.row)
+		fmt.Fprintf(writer, `<rect x="%d" y="%d" width="%d" height="%d" rx="3" fill="%s"/>`+"\n", x, y, side, side, hex(current.fill()))
+
+		switch {
+		case current.occupied:
+			writeSVGText(writer, x+side/2, y+side/2, letterFontSize, current.ink(), "middle", true, string(current.letter))
+			if !current.blank {
+				writeSVGText(writer, x+side-3, y+side-pointsFontSize/2-2, pointsFontSize, current.ink(), "end", false, fmt.Sprint(current.points))
+			}
+		case current.modifier != "":
+			writeSVGText(writer, x+side/2, y+side/2, modifierFontSize, current.ink(), "middle", false, string(current.modifier))
+		}
+	}
+
+	writer.WriteString("</svg>\n")
+
+	return writer.Flush()
+}
+
+// writeSVGText writes text centred vertically on y and anchored on x.
+func writeSVGText(writer *bufio.Writer, x, y, size int, ink color.RGBA, anchor string, bold bool, text string) {
+	weight := "normal"
+	if bold {
+		weight = "bold"
+	}
+
+	fmt.Fprintf(writer, `<text x="%d" y="%d" font-size="%d" font-weight="%s" fill="%s" text-anchor="%s" dominant-baseline="central">%s</text>`+"\n",
+		x, y, size, weight, hex(ink), anchor, html.EscapeString(text))
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
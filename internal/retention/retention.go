@@ -0,0 +1,175 @@
+// Package retention decides how long stored games are kept: lobbies, games
+// in play, and finished games each expire after their own idle limit.
+// Expired lobbies and running games are deleted; expired finished games are
+// moved to an archive.
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/carterjs/words/internal/words"
+)
+
+// batchSize is how many games are listed, archived or deleted at once.
+const batchSize = 100
+
+// Store is where the games a policy governs are kept.
+type Store interface {
+	GameByID(ctx context.Context, gameID string) (*words.Game, error)
+	ListGames(ctx context.Context, query words.GameQuery) (words.GamePage, error)
+	DeleteGames(ctx context.Context, gameIDs ...string) error
+}
+
+// Archive keeps finished games taken out of a store.
+type Archive interface {
+	ArchiveGames(ctx context.Context, states ...words.GameState) error
+}
+
+// Policy sets how long a game of each status may go without a save before
+// it expires. A zero limit keeps such games forever.
+type Policy struct {
+	LobbyMaxIdle    time.Duration
+	RunningMaxIdle  time.Duration
+	FinishedMaxIdle time.Duration
+}
+
+// maxIdle returns the limit for games of the status.
+func (policy Policy) maxIdle(status words.GameStatus) time.Duration {
+	switch status {
+	case words.GameStatusLobby:
+		return policy.LobbyMaxIdle
+	case words.GameStatusRunning:
+		return policy.RunningMaxIdle
+	case words.GameStatusFinished:
+		return policy.FinishedMaxIdle
+	default:
+		return 0
+	}
+}
+
+// Report lists the games a pass deleted and archived, or in a dry run would
+// have.
+type Report struct {
+	DryRun   bool                `json:"dryRun"`
+	Deleted  []words.GameSummary `json:"deleted"`
+	Archived []words.GameSummary `json:"archived"`
+}
+
+// Enforcer applies a policy to a store.
+type Enforcer struct {
+	store   Store
+	archive Archive
+	policy  Policy
+}
+
+// NewEnforcer returns an enforcer of the policy over the store, moving
+// expired finished games to the archive.
+func NewEnforcer(store Store, archive Archive, policy Policy) *Enforcer {
+	return &Enforcer{
+		store:   store,
+		archive: archive,
+		policy:  policy,
+	}
+}
+
+// Enforce deletes and archives every game that has expired as of now. In a
+// dry run it changes nothing and only reports what it would do. A game is
+// archived before it is deleted, so a failure part way may leave a game in
+// both places but never in neither.
+func (enforcer *Enforcer) Enforce(ctx context.Context, now time.Time, dryRun bool) (Report, error) {
+	report := Report{DryRun: dryRun}
+
+	for _, status := range []words.GameStatus{words.GameStatusLobby, words.GameStatusRunning, words.GameStatusFinished} {
+		expired, err := enforcer.expired(ctx, status, now)
+		if err != nil {
+			return report, err
+		}
+
+		for start := 0; start < len(expired); start += batchSize {
+			batch := expired[start:min(start+batchSize, len(expired))]
+
+			if !dryRun {
+				if err := enforcer.retire(ctx, status, batch); err != nil {
+					return report, err
+				}
+			}
+
+			if status == words.GameStatusFinished {
+				report.Archived = append(report.Archived, batch...)
+			} else {
+				report.Deleted = append(report.Deleted, batch...)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// expired lists the games of the status idle past the policy's limit.
+func (enforcer *Enforcer) expired(ctx context.Context, status words.GameStatus, now time.Time) ([]words.GameSummary, error) {
+	maxIdle := enforcer.policy.maxIdle(status)
+	if maxIdle <= 0 {
+		return nil, nil
+	}
+
+	query := words.GameQuery{
+		Status:        status,
+		UpdatedBefore: now.Add(-maxIdle),
+		Limit:         batchSize,
+	}
+
+	var expired []words.GameSummary
+	for {
+		page, err := enforcer.store.ListGames(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("listing expired games: %w", err)
+		}
+
+		expired = append(expired, page.Games...)
+
+		if page.NextCursor == "" {
+			return expired, nil
+		}
+
+		cursor, err := words.ParseGameCursor(page.NextCursor)
+		if err != nil {
+			return nil, fmt.Errorf("paging expired games: %w", err)
+		}
+		query.After = &cursor
+	}
+}
+
+// retire takes the games out of the store, archiving finished ones first.
+func (enforcer *Enforcer) retire(ctx context.Context, status words.GameStatus, summaries []words.GameSummary) error {
+	gameIDs := make([]string, 0, len(summaries))
+	for _, summary := range summaries {
+		gameIDs = append(gameIDs, summary.ID)
+	}
+
+	if status == words.GameStatusFinished {
+		states := make([]words.GameState, 0, len(gameIDs))
+		for _, gameID := range gameIDs {
+			game, err := enforcer.store.GameByID(ctx, gameID)
+			if errors.Is(err, words.ErrGameNotFound) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("loading game to archive: %w", err)
+			}
+			states = append(states, game.State())
+		}
+
+		if err := enforcer.archive.ArchiveGames(ctx, states...); err != nil {
+			return fmt.Errorf("archiving games: %w", err)
+		}
+	}
+
+	if err := enforcer.store.DeleteGames(ctx, gameIDs...); err != nil {
+		return fmt.Errorf("deleting games: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package retention_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/carterjs/words/internal/retention"
+	"github.com/carterjs/words/internal/store"
+	"github.com/carterjs/words/internal/words"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnforcer_Enforce(t *testing.T) {
+	t.Parallel()
+
+	policy := retention.Policy{
+		LobbyMaxIdle:    time.Hour,
+		RunningMaxIdle:  24 * time.Hour,
+		FinishedMaxIdle: 48 * time.Hour,
+	}
+
+	tests := []struct {
+		name         string
+		policy       retention.Policy
+		idle         time.Duration
+		dryRun       bool
+		wantDeleted  []words.GameStatus
+		wantArchived bool
+	}{
+		{name: "keeps games within every limit", policy: policy, idle: time.Minute},
+		{name: "deletes an expired lobby", policy: policy, idle: 2 * time.Hour, wantDeleted: []words.GameStatus{words.GameStatusLobby}},
+		{
+			name:        "deletes an expired running game",
+			policy:      policy,
+			idle:        25 * time.Hour,
+			wantDeleted: []words.GameStatus{words.GameStatusLobby, words.GameStatusRunning},
+		},
+		{
+			name:         "archives an expired finished game",
+			policy:       policy,
+			idle:         49 * time.Hour,
+			wantDeleted:  []words.GameStatus{words.GameStatusLobby, words.GameStatusRunning},
+			wantArchived: true,
+		},
+		{
+			name:         "only reports in a dry run",
+			policy:       policy,
+			idle:         49 * time.Hour,
+			dryRun:       true,
+			wantDeleted:  []words.GameStatus{words.GameStatusLobby, words.GameStatusRunning},
+			wantArchived: true,
+		},
+		{name: "keeps games forever without limits", idle: 365 * 24 * time.Hour},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			gameStore := store.NewFS(t.TempDir(), slog.New(slog.DiscardHandler))
+			archive := store.NewArchive(t.TempDir(), nil)
+
+			games := map[words.GameStatus]*words.Game{
+				words.GameStatusLobby:    newGame(t, words.GameStatusLobby),
+				words.GameStatusRunning:  newGame(t, words.GameStatusRunning),
+				words.GameStatusFinished: newGame(t, words.GameStatusFinished),
+			}
+			for _, game := range games {
+				require.NoError(t, gameStore.SaveGame(t.Context(), game))
+			}
+
+			enforcer := retention.NewEnforcer(gameStore, archive, test.policy)
+
+			report, err := enforcer.Enforce(t.Context(), time.Now().Add(test.idle), test.dryRun)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.dryRun, report.DryRun)
+
+			var deleted []words.GameStatus
+			for _, summary := range report.Deleted {
+				deleted = append(deleted, summary.Status)
+			}
+			assert.Equal(t, test.wantDeleted, deleted)
+
+			if test.wantArchived {
+				require.Len(t, report.Archived, 1)
+				assert.Equal(t, games[words.GameStatusFinished].ID(), report.Archived[0].ID)
+			} else {
+				assert.Empty(t, report.Archived)
+			}
+
+			archived, err := archive.ArchivedGames(t.Context())
+			require.NoError(t, err)
+			assert.Equal(t, test.wantArchived && !test.dryRun, len(archived) == 1)
+
+			removed := len(report.Deleted) + len(report.Archived)
+			if test.dryRun {
+				removed = 0
+			}
+			page, err := gameStore.ListGames(t.Context(), words.GameQuery{})
+			require.NoError(t, err)
+			assert.Len(t, page.Games, len(games)-removed)
+		})
+	}
+}
+
+// newGame builds a game with the status at version 1, ready to save.
+func newGame(t *testing.T, status words.GameStatus) *words.Game {
+	t.Helper()
+
+	game := words.NewGame(words.Config{
+		LetterDistribution: map[rune]int{'A': 10},
+		LetterPoints:       map[rune]int{'A': 1},
+		RackSize:           3,
+	})
+
+	_, err := game.AddPlayer("player-0")
+	require.NoError(t, err)
+
+	if status != words.GameStatusLobby {
+		require.NoError(t, game.Start())
+	}
+
+	state := game.State()
+	state.Version = 1
+	state.Finished = status == words.GameStatusFinished
+
+	versioned, err := words.NewGameFromState(state)
+	require.NoError(t, err)
+
+	return versioned
+}
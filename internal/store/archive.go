@@ -0,0 +1,191 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carterjs/words/internal/crypt"
+	"github.com/carterjs/words/internal/words"
+)
+
+// archiveFileSuffix is the extension of archive files.
+const archiveFileSuffix = ".jsonl.gz"
+
+// Archive keeps games taken out of play, compacted, in one gzipped file of
+// JSON lines per month they were archived in, so they stay readable without
+// cluttering a store. Files are sealed like an encrypted FS's snapshots if
+// there is a keyring.
+type Archive struct {
+	directory string
+	keyring   *crypt.Keyring
+	mutex     sync.Mutex
+}
+
+// NewArchive returns an archive writing to the given directory, sealing its
+// files with the keyring if it is not nil.
+func NewArchive(directory string, keyring *crypt.Keyring) *Archive {
+	return &Archive{
+		directory: directory,
+		keyring:   keyring,
+	}
+}
+
+// ArchiveGames adds the games' compacted snapshots to this month's file,
+// replacing any earlier copy of the same game there.
+func (archive *Archive) ArchiveGames(ctx context.Context, states ...words.GameState) error {
+	if len(states) == 0 {
+		return nil
+	}
+
+	archive.mutex.Lock()
+	defer archive.mutex.Unlock()
+
+	if err := os.MkdirAll(archive.directory, directoryPermissions); err != nil {
+		return fmt.Errorf("creating archive directory: %w", err)
+	}
+
+	month := time.Now().UTC().Format("2006-01")
+	path := archive.file(month)
+
+	archived, err := archive.read(month)
+	if err != nil {
+		return err
+	}
+
+	replaced := make(map[string]bool, len(states))
+	for _, state := range states {
+		replaced[state.ID] = true
+	}
+
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	for _, state := range archived {
+		if replaced[state.ID] {
+			continue
+		}
+		if err := encoder.Encode(state); err != nil {
+			return fmt.Errorf("encoding archived game: %w", err)
+		}
+	}
+	for _, state := range states {
+		if err := encoder.Encode(state.Compacted()); err != nil {
+			return fmt.Errorf("encoding archived game: %w", err)
+		}
+	}
+
+	return writeCompressed(path, data.Bytes(), archive.sealer(month))
+}
+
+// ArchivedGames returns every archived game's snapshot, oldest month first.
+func (archive *Archive) ArchivedGames(ctx context.Context) ([]words.GameState, error) {
+	archive.mutex.Lock()
+	defer archive.mutex.Unlock()
+
+	months, err := archive.months()
+	if err != nil {
+		return nil, err
+	}
+
+	var states []words.GameState
+	for _, month := range months {
+		archived, err := archive.read(month)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, archived...)
+	}
+
+	return states, nil
+}
+
+// RotateKeys reseals every archive file not already sealed with the
+// keyring's current key, returning how many it rewrote.
+func (archive *Archive) RotateKeys(ctx context.Context) (int, error) {
+	if archive.keyring == nil {
+		return 0, errors.New("rotating keys without a keyring")
+	}
+
+	archive.mutex.Lock()
+	defer archive.mutex.Unlock()
+
+	months, err := archive.months()
+	if err != nil {
+		return 0, err
+	}
+
+	var rotated int
+	for _, month := range months {
+		resealed, err := reseal(archive.file(month), archive.sealer(month))
+		if err != nil {
+			return rotated, fmt.Errorf("rotating archive %s: %w", month, err)
+		}
+		if resealed {
+			rotated++
+		}
+	}
+
+	return rotated, nil
+}
+
+// months lists the months with an archive file, oldest first. The caller
+// holds the mutex.
+func (archive *Archive) months() ([]string, error) {
+	entries, err := os.ReadDir(archive.directory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("reading archive directory: %w", err)
+	}
+
+	var months []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), archiveFileSuffix) {
+			months = append(months, strings.TrimSuffix(entry.Name(), archiveFileSuffix))
+		}
+	}
+	sort.Strings(months)
+
+	return months, nil
+}
+
+// read decodes one month's archived games. The caller holds the mutex.
+func (archive *Archive) read(month string) ([]words.GameState, error) {
+	data, err := readCompressed(archive.file(month), archive.sealer(month))
+	if errors.Is(err, words.ErrGameNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading archive %s: %w", month, err)
+	}
+
+	var states []words.GameState
+	for line := range bytes.Lines(data) {
+		state, err := words.DecodeGameState(line)
+		if err != nil {
+			return nil, fmt.Errorf("decoding archive %s: %w", month, err)
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+func (archive *Archive) file(month string) string {
+	return filepath.Join(archive.directory, month+archiveFileSuffix)
+}
+
+// sealer binds each month's file to its name, so files cannot be swapped.
+func (archive *Archive) sealer(month string) sealer {
+	return sealer{keyring: archive.keyring, binding: "archive/" + month}
+}
@@ -0,0 +1,79 @@
+package store_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/carterjs/words/internal/crypt"
+	"github.com/carterjs/words/internal/store"
+	"github.com/carterjs/words/internal/words"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive_ArchivedGames(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		encrypted bool
+	}{
+		{name: "archives games in plain files"},
+		{name: "archives games in sealed files", encrypted: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			directory := t.TempDir()
+
+			var keyring *crypt.Keyring
+			if test.encrypted {
+				keyring = newKeyring(t, "current")
+			}
+			archive := store.NewArchive(directory, keyring)
+
+			first, second := newFinishedGame(t), newFinishedGame(t)
+			require.NoError(t, archive.ArchiveGames(t.Context(), first.State(), second.State()))
+
+			// archiving a game again replaces its earlier copy
+			require.NoError(t, archive.ArchiveGames(t.Context(), withVersion(t, first, 2).State()))
+
+			archived, err := archive.ArchivedGames(t.Context())
+
+			require.NoError(t, err)
+			require.Len(t, archived, 2)
+			assertSameState(t, second.State().Compacted(), archived[0])
+			assertSameState(t, withVersion(t, first, 2).State().Compacted(), archived[1])
+
+			for _, state := range archived {
+				assert.Zero(t, state.PoolIndex)
+				_, err := words.NewGameFromState(state)
+				assert.NoError(t, err)
+			}
+
+			files, err := filepath.Glob(filepath.Join(directory, "*.jsonl.gz"))
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+			data, err := os.ReadFile(files[0])
+			require.NoError(t, err)
+			assert.Equal(t, test.encrypted, crypt.IsSealed(data))
+		})
+	}
+}
+
+// assertSameState compares states as they would be stored, since a stored
+// state does not keep the difference between empty and missing lists.
+func assertSameState(t *testing.T, want, got words.GameState) {
+	t.Helper()
+
+	wantJSON, err := json.Marshal(want)
+	require.NoError(t, err)
+	gotJSON, err := json.Marshal(got)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, string(wantJSON), string(gotJSON))
+}
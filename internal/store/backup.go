@@ -0,0 +1,112 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/carterjs/words/internal/words"
+)
+
+const (
+	// backupsDirectory holds the previous snapshots of every game, one
+	// directory per game.
+	backupsDirectory = "backups"
+	// snapshotBackups is how many previous snapshots of each game are kept.
+	snapshotBackups = 3
+)
+
+// errNoBackup reports a damaged game with no intact backup to fall back on.
+var errNoBackup = errors.New("no intact backup")
+
+func (fileStore *FS) backupDirectory(gameID string) string {
+	return filepath.Join(fileStore.directory, backupsDirectory, gameID)
+}
+
+func (fileStore *FS) backupFile(gameID string, version uint64) string {
+	return filepath.Join(fileStore.backupDirectory(gameID), strconv.FormatUint(version, 10)+gameFileSuffix)
+}
+
+// backUp keeps the game's current snapshot, at the given version, as a
+// backup. The backup is a hard link, so it costs no copy.
+func (fileStore *FS) backUp(gameID string, version uint64) error {
+	if err := os.MkdirAll(fileStore.backupDirectory(gameID), directoryPermissions); err != nil {
+		return fmt.Errorf("creating backup directory: %w", err)
+	}
+
+	path := fileStore.backupFile(gameID, version)
+
+	// a save that failed after backing up may have left this one behind
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("replacing backup: %w", err)
+	}
+
+	if err := os.Link(fileStore.gameFile(gameID), path); err != nil {
+		return fmt.Errorf("backing up game: %w", err)
+	}
+
+	return nil
+}
+
+// pruneBackups deletes all but the newest snapshotBackups backups of the
+// game.
+func (fileStore *FS) pruneBackups(gameID string) error {
+	versions, err := fileStore.backupVersions(gameID)
+	if err != nil {
+		return err
+	}
+
+	for _, version := range versions[min(len(versions), snapshotBackups):] {
+		if err := os.Remove(fileStore.backupFile(gameID, version)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("pruning backup: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// readBackup reads the game's newest intact backup, returning its version.
+func (fileStore *FS) readBackup(gameID string) (words.GameState, uint64, error) {
+	versions, err := fileStore.backupVersions(gameID)
+	if err != nil {
+		return words.GameState{}, 0, err
+	}
+
+	for _, version := range versions {
+		state, err := readState(fileStore.backupFile(gameID, version), fileStore.sealer(gameID))
+		if err == nil {
+			return state, version, nil
+		}
+	}
+
+	return words.GameState{}, 0, errNoBackup
+}
+
+// backupVersions returns the versions of the game's backups, newest first.
+func (fileStore *FS) backupVersions(gameID string) ([]uint64, error) {
+	entries, err := os.ReadDir(fileStore.backupDirectory(gameID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("reading backup directory: %w", err)
+	}
+
+	var versions []uint64
+	for _, entry := range entries {
+		version, err := strconv.ParseUint(strings.TrimSuffix(entry.Name(), gameFileSuffix), 10, 64)
+		if err == nil && strings.HasSuffix(entry.Name(), gameFileSuffix) {
+			versions = append(versions, version)
+		}
+	}
+
+	slices.Sort(versions)
+	slices.Reverse(versions)
+
+	return versions, nil
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/carterjs/words/internal/words"
+)
+
+// Cache keeps the most recently used games of another store in memory,
+// reading through to it on a miss and writing through to it on every save.
+// Games are copied on the way in and out, so callers cannot change a cached
+// game behind the cache's back. Saves made elsewhere are not seen, so a
+// Cache suits a store this process is the only writer to; a save rejected
+// for a version conflict drops the stale entry.
+type Cache struct {
+	store    words.Store
+	capacity int
+
+	mutex     sync.Mutex
+	entries   map[string]*list.Element
+	recency   *list.List
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// NewCache returns a cache holding up to capacity games in front of store.
+func NewCache(store words.Store, capacity int) *Cache {
+	return &Cache{
+		store:    store,
+		capacity: max(capacity, 1),
+		entries:  make(map[string]*list.Element),
+		recency:  list.New(),
+	}
+}
+
+// CacheStats counts how a Cache has been used.
+type CacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Size      int    `json:"size"`
+}
+
+// HitRate returns the fraction of lookups served from memory, or zero if
+// there have been none.
+func (stats CacheStats) HitRate() float64 {
+	lookups := stats.Hits + stats.Misses
+	if lookups == 0 {
+		return 0
+	}
+
+	return float64(stats.Hits) / float64(lookups)
+}
+
+// SaveGame saves the game to the underlying store and caches a copy of it.
+func (cache *Cache) SaveGame(ctx context.Context, game *words.Game) error {
+	if err := cache.store.SaveGame(ctx, game); err != nil {
+		cache.Invalidate(game.ID())
+		return err
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.put(game.Clone())
+
+	return nil
+}
+
+// GameByID returns a copy of the cached game, loading it from the underlying
+// store if it is not cached.
+func (cache *Cache) GameByID(ctx context.Context, gameID string) (*words.Game, error) {
+	cache.mutex.Lock()
+	if element, ok := cache.entries[gameID]; ok {
+		cache.hits++
+		cache.recency.MoveToFront(element)
+		game := element.Value.(*words.Game).Clone()
+		cache.mutex.Unlock()
+
+		return game, nil
+	}
+	cache.misses++
+	cache.mutex.Unlock()
+
+	game, err := cache.store.GameByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	// a save may have cached a newer version while this one was loading
+	if element, ok := cache.entries[gameID]; !ok || element.Value.(*words.Game).Version() < game.Version() {
+		cache.put(game.Clone())
+	}
+
+	return game, nil
+}
+
+// ListGames lists games straight from the underlying store.
+func (cache *Cache) ListGames(ctx context.Context, query words.GameQuery) (words.GamePage, error) {
+	return cache.store.ListGames(ctx, query)
+}
+
+// Invalidate drops the games from the cache, so they are next read from the
+// underlying store.
+func (cache *Cache) Invalidate(gameIDs ...string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for _, gameID := range gameIDs {
+		if element, ok := cache.entries[gameID]; ok {
+			cache.recency.Remove(element)
+			delete(cache.entries, gameID)
+		}
+	}
+}
+
+// Stats returns the cache's counters so far.
+func (cache *Cache) Stats() CacheStats {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return CacheStats{
+		Hits:      cache.hits,
+		Misses:    cache.misses,
+		Evictions: cache.evictions,
+		Size:      len(cache.entries),
+	}
+}
+
+// put caches the game as the most recently used, evicting the least
+// recently used game if the cache is full. The caller holds the mutex.
+func (cache *Cache) put(game *words.Game) {
+	if element, ok := cache.entries[game.ID()]; ok {
+		element.Value = game
+		cache.recency.MoveToFront(element)
+		return
+	}
+
+	cache.entries[game.ID()] = cache.recency.PushFront(game)
+
+	if cache.recency.Len() > cache.capacity {
+		oldest := cache.recency.Back()
+		cache.recency.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*words.Game).ID())
+		cache.evictions++
+	}
+}
@@ -0,0 +1,157 @@
+package store_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/carterjs/words/internal/store"
+	"github.com/carterjs/words/internal/words"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_GameByID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		capacity   int
+		saveDirect bool
+		saveOthers int
+		wantStats  store.CacheStats
+	}{
+		{
+			name:      "serves a saved game from memory",
+			capacity:  2,
+			wantStats: store.CacheStats{Hits: 2, Size: 1},
+		},
+		{
+			name:       "reads a game saved elsewhere through once",
+			capacity:   2,
+			saveDirect: true,
+			wantStats:  store.CacheStats{Hits: 1, Misses: 1, Size: 1},
+		},
+		{
+			name:       "evicts the least recently used game",
+			capacity:   2,
+			saveOthers: 2,
+			wantStats:  store.CacheStats{Hits: 1, Misses: 1, Evictions: 2, Size: 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			fileStore := store.NewFS(t.TempDir(), slog.New(slog.DiscardHandler))
+			cache := store.NewCache(fileStore, test.capacity)
+
+			game := newSavableGame(t)
+			if test.saveDirect {
+				require.NoError(t, fileStore.SaveGame(t.Context(), game))
+			} else {
+				require.NoError(t, cache.SaveGame(t.Context(), game))
+			}
+
+			for range test.saveOthers {
+				require.NoError(t, cache.SaveGame(t.Context(), newSavableGame(t)))
+			}
+
+			for range 2 {
+				loaded, err := cache.GameByID(t.Context(), game.ID())
+				require.NoError(t, err)
+				assert.Equal(t, game.State(), loaded.State())
+			}
+
+			assert.Equal(t, test.wantStats, cache.Stats())
+		})
+	}
+}
+
+func TestCache_GameByID_Copies(t *testing.T) {
+	t.Parallel()
+
+	cache := store.NewCache(store.NewFS(t.TempDir(), slog.New(slog.DiscardHandler)), 1)
+
+	game := newSavableGame(t)
+	require.NoError(t, cache.SaveGame(t.Context(), game))
+	want := game.State()
+
+	// neither the saved game nor a loaded one may reach into the cache
+	require.NoError(t, game.PassTurn(game.CurrentPlayerID()))
+	loaded, err := cache.GameByID(t.Context(), game.ID())
+	require.NoError(t, err)
+	require.NoError(t, loaded.ExchangeLetters(loaded.CurrentPlayerID(), []rune{'A'}))
+
+	reloaded, err := cache.GameByID(t.Context(), game.ID())
+	require.NoError(t, err)
+	assert.Equal(t, want, reloaded.State())
+}
+
+func TestCacheStats_HitRate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		stats store.CacheStats
+		want  float64
+	}{
+		{name: "is zero before any lookup"},
+		{name: "divides hits by lookups", stats: store.CacheStats{Hits: 3, Misses: 1}, want: 0.75},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.InDelta(t, test.want, test.stats.HitRate(), 1e-9)
+		})
+	}
+}
+
+func BenchmarkCache_GameByID(b *testing.B) {
+	stores := map[string]func(fileStore *store.FS) words.Store{
+		"fs": func(fileStore *store.FS) words.Store {
+			return fileStore
+		},
+		"cache": func(fileStore *store.FS) words.Store {
+			return store.NewCache(fileStore, 16)
+		},
+	}
+
+	for name, wrap := range stores {
+		b.Run(name, func(b *testing.B) {
+			gameStore := wrap(store.NewFS(b.TempDir(), slog.New(slog.DiscardHandler)))
+
+			game := newCrowdedGame(b, 200)
+			require.NoError(b, gameStore.SaveGame(b.Context(), game))
+
+			for b.Loop() {
+				if _, err := gameStore.GameByID(b.Context(), game.ID()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// newCrowdedGame builds a started game at version 1 whose board holds the
+// given number of words, each extending the last by one letter.
+func newCrowdedGame(tb testing.TB, placed int) *words.Game {
+	tb.Helper()
+
+	state := newSavableGame(tb).State()
+	state.Words = nil
+	for length := 1; length <= placed; length++ {
+		state.Words = append(state.Words, words.PlacedWordState{
+			Direction: words.DirectionHorizontal,
+			Letters:   strings.Repeat("A", length),
+		})
+	}
+
+	game, err := words.NewGameFromState(state)
+	require.NoError(tb, err)
+
+	return game
+}
@@ -0,0 +1,174 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/carterjs/words/internal/words"
+)
+
+// copyBatchSize is how many games a copy lists at once.
+const copyBatchSize = 100
+
+// Restorer is a store games can be copied into as they are, keeping their
+// versions and when they were last saved.
+type Restorer interface {
+	words.Store
+	RestoreGame(ctx context.Context, game *words.Game, updatedAt time.Time) error
+}
+
+// CopyReport lists what a copy did with each game in the source store.
+type CopyReport struct {
+	// Copied lists the IDs of games copied and verified in this run.
+	Copied []string `json:"copied"`
+	// Resumed lists the IDs of games an earlier run already copied.
+	Resumed []string `json:"resumed"`
+	// Failed lists the games that could not be copied.
+	Failed []CopyFailure `json:"failed"`
+}
+
+// CopyFailure explains why one game was not copied.
+type CopyFailure struct {
+	GameID string `json:"gameId"`
+	Reason string `json:"reason"`
+}
+
+// CopyGames copies every game in source to target, then reads each back
+// from target and checks it rebuilds to the same GameState. A game that
+// cannot be read, written or verified is reported as failed without
+// stopping the copy. Progress is recorded in the file at progressPath, if
+// there is one, so a copy that stops part way resumes where it left off.
+// A game found in target already is verified rather than written again, so
+// resuming without the file is safe, only slower. Run it while nothing is
+// saving games to either store.
+func CopyGames(ctx context.Context, source words.Store, target Restorer, progressPath string) (CopyReport, error) {
+	var report CopyReport
+
+	progress, err := openCopyProgress(progressPath)
+	if err != nil {
+		return report, err
+	}
+	defer progress.close()
+
+	query := words.GameQuery{Limit: copyBatchSize}
+	for {
+		page, err := source.ListGames(ctx, query)
+		if err != nil {
+			return report, fmt.Errorf("listing games to copy: %w", err)
+		}
+
+		for _, summary := range page.Games {
+			if progress.done[summary.ID] {
+				report.Resumed = append(report.Resumed, summary.ID)
+				continue
+			}
+
+			if err := copyGame(ctx, source, target, summary); err != nil {
+				report.Failed = append(report.Failed, CopyFailure{GameID: summary.ID, Reason: err.Error()})
+				continue
+			}
+
+			if err := progress.record(summary.ID); err != nil {
+				return report, err
+			}
+			report.Copied = append(report.Copied, summary.ID)
+		}
+
+		if page.NextCursor == "" {
+			return report, nil
+		}
+
+		cursor, err := words.ParseGameCursor(page.NextCursor)
+		if err != nil {
+			return report, fmt.Errorf("paging games to copy: %w", err)
+		}
+		query.After = &cursor
+	}
+}
+
+// copyGame restores one game into target and verifies the copy.
+func copyGame(ctx context.Context, source words.Store, target Restorer, summary words.GameSummary) error {
+	game, err := source.GameByID(ctx, summary.ID)
+	if err != nil {
+		return fmt.Errorf("reading game: %w", err)
+	}
+
+	// a run that stopped before recording its progress may have copied it
+	err = target.RestoreGame(ctx, game, summary.UpdatedAt)
+	if err != nil && !errors.Is(err, words.ErrVersionConflict) {
+		return fmt.Errorf("writing game: %w", err)
+	}
+
+	copied, err := target.GameByID(ctx, summary.ID)
+	if err != nil {
+		return fmt.Errorf("reading copy: %w", err)
+	}
+
+	if err := compareStates(copied.State(), game.State()); err != nil {
+		return fmt.Errorf("verifying copy: copy differs from the original at version %d, copied at version %d", game.Version(), copied.Version())
+	}
+
+	return nil
+}
+
+// copyProgress is the set of games a copy has finished, kept in a file of
+// one game ID per line.
+type copyProgress struct {
+	done map[string]bool
+	file *os.File
+}
+
+// openCopyProgress reads the games an earlier run finished from the file at
+// path, creating it if need be, and opens it for recording more. An empty
+// path keeps no progress.
+func openCopyProgress(path string) (*copyProgress, error) {
+	progress := &copyProgress{done: make(map[string]bool)}
+	if path == "" {
+		return progress, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening copy progress: %w", err)
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if gameID := strings.TrimSpace(scanner.Text()); gameID != "" {
+			progress.done[gameID] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("reading copy progress: %w", err)
+	}
+
+	progress.file = file
+
+	return progress, nil
+}
+
+// record marks the game finished.
+func (progress *copyProgress) record(gameID string) error {
+	progress.done[gameID] = true
+	if progress.file == nil {
+		return nil
+	}
+
+	if _, err := progress.file.WriteString(gameID + "\n"); err != nil {
+		return fmt.Errorf("recording copy progress: %w", err)
+	}
+
+	return nil
+}
+
+func (progress *copyProgress) close() {
+	if progress.file != nil {
+		progress.file.Close()
+	}
+}
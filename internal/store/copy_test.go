@@ -0,0 +1,144 @@
+package store_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/carterjs/words/internal/store"
+	"github.com/carterjs/words/internal/words"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyGames(t *testing.T) {
+	t.Parallel()
+
+	targets := map[string]func(t *testing.T) store.Restorer{
+		"fs": func(t *testing.T) store.Restorer {
+			return store.NewFS(t.TempDir(), slog.New(slog.DiscardHandler))
+		},
+		"sqlite": func(t *testing.T) store.Restorer {
+			return openSQLite(t)
+		},
+		"journal": func(t *testing.T) store.Restorer {
+			return store.NewJournal(t.TempDir())
+		},
+	}
+
+	for backend, open := range targets {
+		t.Run(backend, func(t *testing.T) {
+			t.Parallel()
+
+			source := store.NewFS(t.TempDir(), slog.New(slog.DiscardHandler))
+			var gameIDs []string
+			for version := range uint64(3) {
+				game := newSavableGame(t)
+				for saved := uint64(1); saved <= version+1; saved++ {
+					require.NoError(t, source.SaveGame(t.Context(), withVersion(t, game, saved)))
+				}
+				gameIDs = append(gameIDs, game.ID())
+			}
+
+			target := open(t)
+			report, err := store.CopyGames(t.Context(), source, target, "")
+			require.NoError(t, err)
+			assert.ElementsMatch(t, gameIDs, report.Copied)
+			assert.Empty(t, report.Failed)
+
+			for _, gameID := range gameIDs {
+				original, err := source.GameByID(t.Context(), gameID)
+				require.NoError(t, err)
+				copied, err := target.GameByID(t.Context(), gameID)
+				require.NoError(t, err)
+
+				assertSameState(t, original.State(), copied.State())
+			}
+
+			sourcePage, err := source.ListGames(t.Context(), words.GameQuery{})
+			require.NoError(t, err)
+			targetPage, err := target.ListGames(t.Context(), words.GameQuery{})
+			require.NoError(t, err)
+			assert.Equal(t, sourcePage, targetPage)
+		})
+	}
+}
+
+func TestCopyGames_Resume(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		keepProgress bool
+		wantCopied   int
+		wantResumed  int
+	}{
+		{name: "skips games recorded as copied", keepProgress: true, wantCopied: 1, wantResumed: 2},
+		{name: "verifies games already copied without progress", wantCopied: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			source := store.NewFS(t.TempDir(), slog.New(slog.DiscardHandler))
+			target := store.NewFS(t.TempDir(), slog.New(slog.DiscardHandler))
+			progressPath := filepath.Join(t.TempDir(), "copy.progress")
+
+			for range 2 {
+				require.NoError(t, source.SaveGame(t.Context(), newSavableGame(t)))
+			}
+
+			report, err := store.CopyGames(t.Context(), source, target, progressPath)
+			require.NoError(t, err)
+			require.Len(t, report.Copied, 2)
+
+			// a game saved since the copy stopped
+			require.NoError(t, source.SaveGame(t.Context(), newSavableGame(t)))
+
+			if !test.keepProgress {
+				require.NoError(t, os.Remove(progressPath))
+			}
+
+			report, err = store.CopyGames(t.Context(), source, target, progressPath)
+			require.NoError(t, err)
+			assert.Len(t, report.Copied, test.wantCopied)
+			assert.Len(t, report.Resumed, test.wantResumed)
+			assert.Empty(t, report.Failed)
+		})
+	}
+}
+
+func TestCopyGames_Failure(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+	}{
+		{name: "reports a copy that differs from the original"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			source := store.NewFS(t.TempDir(), slog.New(slog.DiscardHandler))
+			target := store.NewFS(t.TempDir(), slog.New(slog.DiscardHandler))
+
+			game := newSavableGame(t)
+			require.NoError(t, source.SaveGame(t.Context(), game))
+			require.NoError(t, source.SaveGame(t.Context(), withVersion(t, game, 2)))
+			require.NoError(t, target.SaveGame(t.Context(), game))
+
+			kept := newSavableGame(t)
+			require.NoError(t, source.SaveGame(t.Context(), kept))
+
+			report, err := store.CopyGames(t.Context(), source, target, "")
+			require.NoError(t, err)
+			assert.Equal(t, []string{kept.ID()}, report.Copied)
+			require.Len(t, report.Failed, 1)
+			assert.Equal(t, game.ID(), report.Failed[0].GameID)
+		})
+	}
+}
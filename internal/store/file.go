@@ -1,85 +1,211 @@
-// Package store persists games to the local filesystem as gzipped JSON
-// snapshots, satisfying the words service's Store contract.
+// Package store persists games, satisfying the words service's Store
+// contract: FS keeps gzipped JSON snapshots on the local filesystem, SQLite
+// keeps normalized, queryable rows in an embedded database, and Journal keeps
+// a replayable log of every game's actions. Cache keeps recently used games
+// of any of them in memory, and CopyGames moves games from one to another.
 package store
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/carterjs/words/internal/crypt"
+	"github.com/carterjs/words/internal/lock"
 	"github.com/carterjs/words/internal/words"
 )
 
 // directoryPermissions is the mode for the games directory.
 const directoryPermissions = 0o755
 
-// FS stores each game as a gzipped JSON snapshot in a directory.
+// FS stores each game as a gzipped JSON snapshot in a directory, alongside
+// an index of their summaries for listing and a file per share link. The
+// last few snapshots of each game are kept as backups to fall back on if the
+// current one is damaged. Snapshots, summaries and share links may all be
+// encrypted at rest. It also leases per-game locks through lock files in the
+// same directory.
 type FS struct {
-	directory string
+	directory  string
+	keyring    *crypt.Keyring
+	locks      *lock.Local
+	saves      *lock.Local
+	index      *gameIndex
+	shareLinks *shareLinkFiles
+	logger     *slog.Logger
+	validate   bool
+
+	// damaged holds the IDs of games whose snapshots this store has found
+	// damaged since it last saved them
+	damaged sync.Map
+}
+
+// NewFS returns a store writing games to the given directory, logging any
+// recovery from a backup.
+func NewFS(directory string, logger *slog.Logger) *FS {
+	return NewEncryptedFS(directory, nil, logger)
 }
 
-// NewFS returns a store writing games to the given directory.
-func NewFS(directory string) *FS {
-	return &FS{
+// NewEncryptedFS returns a store writing games to the given directory
+// sealed with the keyring's current key. It still reads snapshots written
+// unencrypted or under the keyring's older keys, sealing them with the
+// current key when they are next saved. The index of game summaries and
+// the share links are sealed the same way. A nil keyring writes them all
+// plain.
+func NewEncryptedFS(directory string, keyring *crypt.Keyring, logger *slog.Logger) *FS {
+	fileStore := &FS{
 		directory: directory,
+		keyring:   keyring,
+		locks:     lock.NewLocal(),
+		saves:     lock.NewLocal(),
+		logger:    logger,
 	}
+	fileStore.index = newGameIndex(directory, keyring, fileStore.scanSummaries)
+	fileStore.shareLinks = newShareLinkFiles(directory, keyring)
+
+	return fileStore
 }
 
-// SaveGame writes the game's snapshot to disk, replacing any previous one.
+// ValidateOnLoad makes GameByID check every game it loads with
+// words.Game.Validate, refusing inconsistent ones with the
+// words.IntegrityError describing them. Listings still include them, so
+// they can be found and repaired. Call it before the store is used.
+func (fileStore *FS) ValidateOnLoad() {
+	fileStore.validate = true
+}
+
+// SaveGame writes the game's snapshot to disk if the stored one is a version
+// behind it, replacing it atomically, and updates its summary in the index.
+// The snapshot it replaces becomes a backup. The check is atomic within a
+// process; instances sharing the directory should also hold the game's
+// lease.
 func (fileStore *FS) SaveGame(ctx context.Context, game *words.Game) error {
+	return fileStore.save(ctx, game, game.Version()-1, savedAt())
+}
+
+// RestoreGame writes a game that is not stored yet as it is, at its own
+// version and as last saved at updatedAt, for copying games from another
+// store. A game already stored is refused with words.ErrVersionConflict.
+func (fileStore *FS) RestoreGame(ctx context.Context, game *words.Game, updatedAt time.Time) error {
+	return fileStore.save(ctx, game, 0, updatedAt.UTC().Truncate(time.Millisecond))
+}
+
+// save writes the game's snapshot if the stored one is at the wanted
+// version, dating it updatedAt.
+func (fileStore *FS) save(ctx context.Context, game *words.Game, want uint64, updatedAt time.Time) error {
+	unlock, err := fileStore.saves.Lock(ctx, game.ID())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	stored, recovered, err := fileStore.storedVersion(ctx, game.ID())
+	if err != nil {
+		return err
+	}
+
+	if stored != want {
+		return fmt.Errorf("saving version %d over %d: %w", game.Version(), stored, words.ErrVersionConflict)
+	}
+
 	if err := os.MkdirAll(fileStore.directory, directoryPermissions); err != nil {
 		return fmt.Errorf("creating games directory: %w", err)
 	}
 
-	file, err := os.Create(fileStore.gameFile(game.ID()))
-	if err != nil {
-		return fmt.Errorf("creating game file: %w", err)
+	// a damaged snapshot is not worth keeping, and its version is already
+	// backed up
+	if stored > 0 && !recovered {
+		if err := fileStore.backUp(game.ID(), stored); err != nil {
+			return err
+		}
 	}
-	defer file.Close()
 
-	compressor := gzip.NewWriter(file)
-	if err := json.NewEncoder(compressor).Encode(game.State()); err != nil {
-		return fmt.Errorf("encoding game: %w", err)
+	if err := writeState(fileStore.gameFile(game.ID()), game.State(), fileStore.sealer(game.ID())); err != nil {
+		return err
 	}
+	fileStore.damaged.Delete(game.ID())
 
-	if err := compressor.Close(); err != nil {
-		return fmt.Errorf("flushing game: %w", err)
+	// the index is rebuilt from modification times
+	if err := os.Chtimes(fileStore.gameFile(game.ID()), updatedAt, updatedAt); err != nil {
+		return fmt.Errorf("dating game file: %w", err)
 	}
 
-	if err := file.Close(); err != nil {
-		return fmt.Errorf("closing game file: %w", err)
+	if err := fileStore.pruneBackups(game.ID()); err != nil {
+		return err
+	}
+
+	if err := fileStore.index.put(ctx, game.Summary(updatedAt)); err != nil {
+		return fmt.Errorf("indexing game: %w", err)
 	}
 
 	return nil
 }
 
-// GameByID reads a game's snapshot from disk and rebuilds it. A missing file
-// is reported as words.ErrGameNotFound.
-func (fileStore *FS) GameByID(ctx context.Context, gameID string) (*words.Game, error) {
-	file, err := os.Open(fileStore.gameFile(gameID))
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, words.ErrGameNotFound
+// storedVersion returns the version of the game's snapshot on disk, or zero
+// if there is none, and whether it had to be recovered from a backup. The
+// version is taken from the game's summary when the summary is dated like
+// the snapshot, so the two were written by the same save; the snapshot is
+// only decoded when they disagree, as after a save that failed to index it,
+// or when it has been found damaged.
+func (fileStore *FS) storedVersion(ctx context.Context, gameID string) (uint64, bool, error) {
+	info, err := os.Stat(fileStore.gameFile(gameID))
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+
+	if _, damaged := fileStore.damaged.Load(gameID); err == nil && !damaged {
+		summary, indexed, err := fileStore.index.summary(ctx, gameID)
+		if err == nil && indexed && summary.UpdatedAt.Equal(info.ModTime().UTC().Truncate(time.Millisecond)) {
+			return summary.Version, false, nil
 		}
+	}
 
-		return nil, fmt.Errorf("opening game file: %w", err)
+	state, recovered, err := fileStore.readState(gameID)
+	if errors.Is(err, words.ErrGameNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("reading stored version: %w", err)
 	}
-	defer file.Close()
 
-	decompressor, err := gzip.NewReader(file)
+	return state.Version, recovered, nil
+}
+
+// GameByID reads a game's snapshot from disk and rebuilds it, falling back
+// to the newest intact backup if the snapshot is damaged, and validates it
+// if the store was asked to. A missing file is reported as
+// words.ErrGameNotFound.
+func (fileStore *FS) GameByID(ctx context.Context, gameID string) (*words.Game, error) {
+	game, err := fileStore.loadGame(gameID)
 	if err != nil {
-		return nil, fmt.Errorf("decompressing game: %w", err)
+		return nil, err
 	}
-	defer decompressor.Close()
 
-	var state words.GameState
-	if err := json.NewDecoder(decompressor).Decode(&state); err != nil {
-		return nil, fmt.Errorf("decoding game: %w", err)
+	if fileStore.validate {
+		if err := game.Validate(); err != nil {
+			return nil, fmt.Errorf("validating game: %w", err)
+		}
+	}
+
+	return game, nil
+}
+
+// loadGame rebuilds a game from its snapshot without validating it.
+func (fileStore *FS) loadGame(gameID string) (*words.Game, error) {
+	state, _, err := fileStore.readState(gameID)
+	if err != nil {
+		return nil, err
 	}
 
 	game, err := words.NewGameFromState(state)
@@ -90,46 +216,368 @@ func (fileStore *FS) GameByID(ctx context.Context, gameID string) (*words.Game,
 	return game, nil
 }
 
+// readState reads the game's snapshot, or its newest intact backup if the
+// snapshot is damaged, reporting whether it fell back. A damaged snapshot is
+// remembered, so the next save checks its version against the backup.
+func (fileStore *FS) readState(gameID string) (words.GameState, bool, error) {
+	state, err := readState(fileStore.gameFile(gameID), fileStore.sealer(gameID))
+	if err == nil || errors.Is(err, words.ErrGameNotFound) {
+		return state, false, err
+	}
+	fileStore.damaged.Store(gameID, true)
+
+	recovered, version, backupErr := fileStore.readBackup(gameID)
+	if backupErr != nil {
+		return words.GameState{}, false, errors.Join(err, backupErr)
+	}
+
+	fileStore.logger.Warn("recovered game from backup",
+		"gameID", gameID,
+		"version", version,
+		"error", err,
+	)
+
+	return recovered, true, nil
+}
+
 // gameFileSuffix is the extension of stored game snapshots.
 const gameFileSuffix = ".json.gz"
 
-// RemoveIdleGames deletes unfinished games that have not been saved for at
-// least maxIdle, returning how many were removed. Finished games are kept so
-// they can be displayed later. A game's last save time is its file's
-// modification time.
-func (fileStore *FS) RemoveIdleGames(ctx context.Context, maxIdle time.Duration) (int, error) {
+// ListGames returns a page of the indexed games matching the query.
+func (fileStore *FS) ListGames(ctx context.Context, query words.GameQuery) (words.GamePage, error) {
+	summaries, err := fileStore.index.summaries(ctx)
+	if err != nil {
+		return words.GamePage{}, err
+	}
+
+	return pageOf(summaries, query), nil
+}
+
+// DeleteGames deletes the games, with their backups, and drops them from
+// the index. Games already gone are skipped.
+func (fileStore *FS) DeleteGames(ctx context.Context, gameIDs ...string) error {
+	var (
+		deleted   []string
+		deleteErr error
+	)
+	for _, gameID := range gameIDs {
+		if err := os.Remove(fileStore.gameFile(gameID)); err != nil && !os.IsNotExist(err) {
+			deleteErr = fmt.Errorf("deleting game: %w", err)
+			break
+		}
+		if err := os.RemoveAll(fileStore.backupDirectory(gameID)); err != nil {
+			deleteErr = fmt.Errorf("deleting game's backups: %w", err)
+			break
+		}
+		deleted = append(deleted, gameID)
+	}
+
+	if err := fileStore.index.remove(ctx, deleted...); err != nil {
+		return fmt.Errorf("removing deleted games from index: %w", err)
+	}
+
+	if err := fileStore.shareLinks.removeGames(deleted...); err != nil {
+		return fmt.Errorf("removing deleted games' share links: %w", err)
+	}
+
+	return deleteErr
+}
+
+// MigrateGames rewrites every snapshot written in an older schema in the
+// current one, returning how many were rewritten. Snapshots keep their
+// modification times, so a rebuilt index still sees when each game was last
+// saved. Games already in the current schema are left alone.
+func (fileStore *FS) MigrateGames(ctx context.Context) (int, error) {
+	gameIDs, err := fileStore.gameIDs()
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		migrated   []words.GameSummary
+		migrateErr error
+	)
+	for _, gameID := range gameIDs {
+		summary, rewritten, err := fileStore.migrateGame(ctx, gameID)
+		if err != nil {
+			migrateErr = fmt.Errorf("migrating game %s: %w", gameID, err)
+			break
+		}
+		if rewritten {
+			migrated = append(migrated, summary)
+		}
+	}
+
+	if len(migrated) == 0 {
+		return 0, migrateErr
+	}
+
+	for _, summary := range migrated {
+		indexed, exists, err := fileStore.index.summary(ctx, summary.ID)
+		if err == nil {
+			if exists {
+				summary.UpdatedAt = indexed.UpdatedAt
+			}
+			err = fileStore.index.put(ctx, summary)
+		}
+		if err != nil {
+			return len(migrated), fmt.Errorf("indexing migrated games: %w", err)
+		}
+	}
+
+	return len(migrated), migrateErr
+}
+
+// migrateGame rewrites one game's snapshot in the current schema if it is
+// in an older one, reporting whether it did.
+func (fileStore *FS) migrateGame(ctx context.Context, gameID string) (words.GameSummary, bool, error) {
+	unlock, err := fileStore.saves.Lock(ctx, gameID)
+	if err != nil {
+		return words.GameSummary{}, false, err
+	}
+	defer unlock()
+
+	path := fileStore.gameFile(gameID)
+
+	data, err := readCompressed(path, fileStore.sealer(gameID))
+	if err != nil {
+		return words.GameSummary{}, false, err
+	}
+
+	schema, err := words.StateSchema(data)
+	if err != nil {
+		return words.GameSummary{}, false, err
+	}
+	if schema == words.CurrentSchema {
+		return words.GameSummary{}, false, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return words.GameSummary{}, false, fmt.Errorf("checking game file: %w", err)
+	}
+
+	state, err := words.DecodeGameState(data)
+	if err != nil {
+		return words.GameSummary{}, false, err
+	}
+
+	// only rewrite snapshots that still rebuild into a game
+	game, err := words.NewGameFromState(state)
+	if err != nil {
+		return words.GameSummary{}, false, fmt.Errorf("rebuilding game: %w", err)
+	}
+
+	if err := writeState(path, game.State(), fileStore.sealer(gameID)); err != nil {
+		return words.GameSummary{}, false, err
+	}
+
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		return words.GameSummary{}, false, fmt.Errorf("restoring game file time: %w", err)
+	}
+
+	return game.Summary(info.ModTime().UTC().Truncate(time.Millisecond)), true, nil
+}
+
+// gameIDs lists the games with a snapshot in the directory.
+func (fileStore *FS) gameIDs() ([]string, error) {
+	entries, err := os.ReadDir(fileStore.directory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("reading games directory: %w", err)
+	}
+
+	var gameIDs []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), gameFileSuffix) {
+			gameIDs = append(gameIDs, strings.TrimSuffix(entry.Name(), gameFileSuffix))
+		}
+	}
+
+	return gameIDs, nil
+}
+
+// scanSummaries summarizes every snapshot in the directory, taking each
+// file's modification time as its last save.
+func (fileStore *FS) scanSummaries(ctx context.Context) ([]words.GameSummary, error) {
 	entries, err := os.ReadDir(fileStore.directory)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return 0, nil
+			return nil, nil
 		}
 
-		return 0, fmt.Errorf("reading games directory: %w", err)
+		return nil, fmt.Errorf("reading games directory: %w", err)
 	}
 
-	var removed int
+	var summaries []words.GameSummary
 	for _, entry := range entries {
 		if entry.IsDir() || !strings.HasSuffix(entry.Name(), gameFileSuffix) {
 			continue
 		}
 
 		info, err := entry.Info()
-		if err != nil || time.Since(info.ModTime()) < maxIdle {
+		if err != nil {
 			continue
 		}
 
-		game, err := fileStore.GameByID(ctx, strings.TrimSuffix(entry.Name(), gameFileSuffix))
-		if err != nil || game.Finished() {
+		game, err := fileStore.loadGame(strings.TrimSuffix(entry.Name(), gameFileSuffix))
+		if err != nil {
 			continue
 		}
 
-		if err := os.Remove(filepath.Join(fileStore.directory, entry.Name())); err != nil {
-			return removed, fmt.Errorf("removing idle game: %w", err)
+		summaries = append(summaries, game.Summary(info.ModTime().UTC().Truncate(time.Millisecond)))
+	}
+
+	return summaries, nil
+}
+
+// checksumPrefix marks the gzip comment holding the SHA-256 of a snapshot's
+// JSON.
+const checksumPrefix = "sha256:"
+
+// errCorruptSnapshot reports a snapshot whose contents do not match the
+// checksum it was written with.
+var errCorruptSnapshot = errors.New("snapshot does not match its checksum")
+
+// writeState writes a gzipped JSON snapshot to the path, sealed by the
+// sealer, atomically replacing any file already there.
+func writeState(path string, state words.GameState, sealer sealer) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encoding game: %w", err)
+	}
+
+	return writeCompressed(path, data, sealer)
+}
+
+// writeCompressed gzips the data into the file at the path, sealed by the
+// sealer, atomically replacing any file already there. The file carries a
+// checksum of the data and is flushed to disk before it replaces the old
+// one, so a crash leaves either the old file or the new one.
+func writeCompressed(path string, data []byte, sealer sealer) error {
+	checksum := sha256.Sum256(data)
+
+	var compressed bytes.Buffer
+	compressor := gzip.NewWriter(&compressed)
+	compressor.Comment = checksumPrefix + hex.EncodeToString(checksum[:])
+	if _, err := compressor.Write(data); err != nil {
+		return fmt.Errorf("compressing game: %w", err)
+	}
+
+	if err := compressor.Close(); err != nil {
+		return fmt.Errorf("flushing game: %w", err)
+	}
+
+	sealed, err := sealer.seal(compressed.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return writeFile(path, sealed)
+}
+
+// writeFile atomically replaces the file at the path with the data, flushing
+// it to disk first.
+func writeFile(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating game file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("writing game file: %w", err)
+	}
+
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("syncing game file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing game file: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("replacing game file: %w", err)
+	}
+
+	return syncDirectory(filepath.Dir(path))
+}
+
+// syncDirectory flushes the directory's entries to disk, so a file renamed
+// into it survives a crash.
+func syncDirectory(path string) error {
+	directory, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening directory: %w", err)
+	}
+	defer directory.Close()
+
+	if err := directory.Sync(); err != nil {
+		return fmt.Errorf("syncing directory: %w", err)
+	}
+
+	return nil
+}
+
+// readState reads a gzipped JSON snapshot, opening it with the sealer if it
+// is sealed and migrating it to the current schema. A missing file is
+// reported as words.ErrGameNotFound.
+func readState(path string, sealer sealer) (words.GameState, error) {
+	data, err := readCompressed(path, sealer)
+	if err != nil {
+		return words.GameState{}, err
+	}
+
+	state, err := words.DecodeGameState(data)
+	if err != nil {
+		return words.GameState{}, fmt.Errorf("decoding game: %w", err)
+	}
+
+	return state, nil
+}
+
+// readCompressed reads a file written by writeCompressed, opening it with
+// the sealer if it is sealed and checking it against its checksum if it has
+// one. A missing file is reported as words.ErrGameNotFound.
+func readCompressed(path string, sealer sealer) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, words.ErrGameNotFound
+		}
+
+		return nil, fmt.Errorf("reading game file: %w", err)
+	}
+
+	compressed, err := sealer.open(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	decompressor, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing game: %w", err)
+	}
+	defer decompressor.Close()
+
+	data, err := io.ReadAll(decompressor)
+	if err != nil {
+		return nil, fmt.Errorf("reading game: %w", err)
+	}
+
+	if want, ok := strings.CutPrefix(decompressor.Comment, checksumPrefix); ok {
+		checksum := sha256.Sum256(data)
+		if hex.EncodeToString(checksum[:]) != want {
+			return nil, errCorruptSnapshot
 		}
-		removed++
 	}
 
-	return removed, nil
+	return data, nil
 }
 
 func (fileStore *FS) gameFile(gameID string) string {
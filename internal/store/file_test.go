@@ -1,8 +1,17 @@
 package store_test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -12,54 +21,199 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestFS_GameByID(t *testing.T) {
+func TestFS_ListGames(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		name    string
-		save    bool
-		wantErr error
+		name          string
+		removeIndex   bool
+		otherInstance bool
 	}{
-		{name: "roundtrips a saved game", save: true},
-		{name: "reports a missing game", wantErr: words.ErrGameNotFound},
+		{name: "lists games from the maintained index"},
+		{name: "rebuilds a missing index from the snapshots", removeIndex: true},
+		{name: "lists games another instance saved", otherInstance: true},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			fileStore := store.NewFS(t.TempDir())
+			directory := t.TempDir()
+			fileStore := store.NewFS(directory, slog.New(slog.DiscardHandler))
+
+			saver := fileStore
+			if test.otherInstance {
+				// list once so the index is already read when the game is saved
+				_, err := fileStore.ListGames(t.Context(), words.GameQuery{})
+				require.NoError(t, err)
+
+				saver = store.NewFS(directory, slog.New(slog.DiscardHandler))
+			}
 
 			game := newSavableGame(t)
-			if test.save {
-				require.NoError(t, fileStore.SaveGame(t.Context(), game))
+			require.NoError(t, saver.SaveGame(t.Context(), game))
+
+			if test.removeIndex {
+				require.NoError(t, os.RemoveAll(filepath.Join(directory, "index")))
+			}
+
+			page, err := fileStore.ListGames(t.Context(), words.GameQuery{})
+
+			require.NoError(t, err)
+			require.Len(t, page.Games, 1)
+			assert.Equal(t, game.Summary(page.Games[0].UpdatedAt), page.Games[0])
+			assert.FileExists(t, filepath.Join(directory, "index", game.ID()+".json"))
+		})
+	}
+}
+
+func TestFS_GameByID_Recovery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		saves         uint64
+		damage        func(t *testing.T, path string)
+		wantVersion   uint64
+		wantRecovered bool
+		wantErr       bool
+	}{
+		{
+			name:        "reads an intact snapshot",
+			saves:       3,
+			wantVersion: 3,
+		},
+		{
+			name:  "falls back on a truncated snapshot",
+			saves: 3,
+			damage: func(t *testing.T, path string) {
+				info, err := os.Stat(path)
+				require.NoError(t, err)
+				require.NoError(t, os.Truncate(path, info.Size()/2))
+			},
+			wantVersion:   2,
+			wantRecovered: true,
+		},
+		{
+			name:  "falls back on a snapshot that fails its checksum",
+			saves: 3,
+			damage: func(t *testing.T, path string) {
+				var compressed bytes.Buffer
+				compressor := gzip.NewWriter(&compressed)
+				compressor.Comment = "sha256:" + strings.Repeat("0", 64)
+				_, err := compressor.Write([]byte(`{"schema": 1, "id": "game", "version": 3}`))
+				require.NoError(t, err)
+				require.NoError(t, compressor.Close())
+				require.NoError(t, os.WriteFile(path, compressed.Bytes(), 0o644))
+			},
+			wantVersion:   2,
+			wantRecovered: true,
+		},
+		{
+			name:  "reports a damaged snapshot without backups",
+			saves: 1,
+			damage: func(t *testing.T, path string) {
+				require.NoError(t, os.WriteFile(path, []byte("not gzip"), 0o644))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			directory := t.TempDir()
+			var logs bytes.Buffer
+			fileStore := store.NewFS(directory, slog.New(slog.NewTextHandler(&logs, nil)))
+
+			game := newSavableGame(t)
+			for version := uint64(1); version <= test.saves; version++ {
+				require.NoError(t, fileStore.SaveGame(t.Context(), withVersion(t, game, version)))
+			}
+
+			if test.damage != nil {
+				test.damage(t, filepath.Join(directory, game.ID()+".json.gz"))
 			}
 
 			loaded, err := fileStore.GameByID(t.Context(), game.ID())
 
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.NotErrorIs(t, err, words.ErrGameNotFound)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.wantVersion, loaded.Version())
+			assert.Equal(t, test.wantRecovered, strings.Contains(logs.String(), "recovered game from backup"))
+
+			// the game carries on from whichever snapshot it was read from
+			require.NoError(t, fileStore.SaveGame(t.Context(), withVersion(t, game, loaded.Version()+1)))
+		})
+	}
+}
+
+func TestFS_GameByID_Validation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		validate bool
+		corrupt  bool
+		wantErr  error
+	}{
+		{name: "loads a consistent game", validate: true},
+		{name: "refuses an inconsistent game", validate: true, corrupt: true, wantErr: words.ErrInconsistentGame},
+		{name: "loads an inconsistent game without validation", corrupt: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			fileStore := store.NewFS(t.TempDir(), slog.New(slog.DiscardHandler))
+			if test.validate {
+				fileStore.ValidateOnLoad()
+			}
+
+			game := newSavableGame(t)
+			if test.corrupt {
+				state := game.State()
+				state.Turn = 5
+
+				corrupted, err := words.NewGameFromState(state)
+				require.NoError(t, err)
+				game = corrupted
+			}
+			require.NoError(t, fileStore.SaveGame(t.Context(), game))
+
+			_, err := fileStore.GameByID(t.Context(), game.ID())
 			if test.wantErr != nil {
 				assert.ErrorIs(t, err, test.wantErr)
-				return
+			} else {
+				require.NoError(t, err)
 			}
 
+			// inconsistent games stay listed so they can be found
+			page, err := fileStore.ListGames(t.Context(), words.GameQuery{})
 			require.NoError(t, err)
-			assert.Equal(t, game.State(), loaded.State())
+			assert.Len(t, page.Games, 1)
 		})
 	}
 }
 
-func TestFS_RemoveIdleGames(t *testing.T) {
+func TestFS_SaveGame_Backups(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
 		name        string
-		finished    bool
-		old         bool
-		wantRemoved int
+		saves       uint64
+		wantBackups []string
 	}{
-		{name: "removes an idle unfinished game", old: true, wantRemoved: 1},
-		{name: "keeps an idle finished game", old: true, finished: true},
-		{name: "keeps a recently saved game"},
+		{name: "keeps no backup of a new game", saves: 1},
+		{name: "keeps the previous snapshots", saves: 3, wantBackups: []string{"1.json.gz", "2.json.gz"}},
+		{name: "keeps only the newest backups", saves: 6, wantBackups: []string{"3.json.gz", "4.json.gz", "5.json.gz"}},
 	}
 
 	for _, test := range tests {
@@ -67,57 +221,354 @@ func TestFS_RemoveIdleGames(t *testing.T) {
 			t.Parallel()
 
 			directory := t.TempDir()
-			fileStore := store.NewFS(directory)
+			fileStore := store.NewFS(directory, slog.New(slog.DiscardHandler))
 
 			game := newSavableGame(t)
-			if test.finished {
-				state := game.State()
-				state.Finished = true
+			for version := uint64(1); version <= test.saves; version++ {
+				require.NoError(t, fileStore.SaveGame(t.Context(), withVersion(t, game, version)))
+			}
 
-				var err error
-				game, err = words.NewGameFromState(state)
+			var backups []string
+			entries, err := os.ReadDir(filepath.Join(directory, "backups", game.ID()))
+			if !os.IsNotExist(err) {
 				require.NoError(t, err)
 			}
-			require.NoError(t, fileStore.SaveGame(t.Context(), game))
+			for _, entry := range entries {
+				backups = append(backups, entry.Name())
+			}
+
+			assert.Equal(t, test.wantBackups, backups)
+		})
+	}
+}
+
+func TestFS_SaveGame_StoredVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		tamper  func(t *testing.T, directory string, game *words.Game, summary []byte)
+		version uint64
+		wantErr error
+	}{
+		{
+			name:    "takes the version from the index without decoding the snapshot",
+			version: 3,
+			tamper: func(t *testing.T, directory string, game *words.Game, _ []byte) {
+				path := filepath.Join(directory, game.ID()+".json.gz")
+				info, err := os.Stat(path)
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(path, []byte("not a snapshot"), 0o644))
+				require.NoError(t, os.Chtimes(path, info.ModTime(), info.ModTime()))
+			},
+		},
+		{
+			name:    "reads the snapshot when the index lags behind it",
+			version: 2,
+			tamper: func(t *testing.T, directory string, game *words.Game, summary []byte) {
+				require.NoError(t, os.WriteFile(filepath.Join(directory, "index", game.ID()+".json"), summary, 0o644))
+			},
+			wantErr: words.ErrVersionConflict,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
 
-			if test.old {
-				stale := time.Now().Add(-48 * time.Hour)
-				require.NoError(t, os.Chtimes(filepath.Join(directory, game.ID()+".json.gz"), stale, stale))
+			directory := t.TempDir()
+			fileStore := store.NewFS(directory, slog.New(slog.DiscardHandler))
+
+			game := newSavableGame(t)
+			require.NoError(t, fileStore.SaveGame(t.Context(), withVersion(t, game, 1)))
+			summary, err := os.ReadFile(filepath.Join(directory, "index", game.ID()+".json"))
+			require.NoError(t, err)
+
+			// save again later, so the first save's summary is dated before
+			// the snapshot
+			time.Sleep(2 * time.Millisecond)
+			require.NoError(t, fileStore.SaveGame(t.Context(), withVersion(t, game, 2)))
+
+			test.tamper(t, directory, game, summary)
+
+			err = fileStore.SaveGame(t.Context(), withVersion(t, game, test.version))
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
 			}
 
-			removed, err := fileStore.RemoveIdleGames(t.Context(), 24*time.Hour)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFS_ShareLinkByID_LegacyFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		withID bool
+	}{
+		{name: "gives a link saved by token its ID"},
+		{name: "drops the token of a link saved with both", withID: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
 
+			directory := t.TempDir()
+
+			link := newShareLink("game", time.Hour)
+			legacy := link
+			if !test.withID {
+				legacy.ID = ""
+			}
+			data, err := json.Marshal(map[string]any{"links": []words.ShareLink{legacy}})
 			require.NoError(t, err)
-			assert.Equal(t, test.wantRemoved, removed)
+			path := filepath.Join(directory, "share-links.json")
+			require.NoError(t, os.WriteFile(path, data, 0o644))
 
-			_, err = fileStore.GameByID(t.Context(), game.ID())
-			if test.wantRemoved > 0 {
-				assert.ErrorIs(t, err, words.ErrGameNotFound)
-			} else {
-				assert.NoError(t, err)
+			loaded, err := store.NewFS(directory, slog.New(slog.DiscardHandler)).ShareLinkByID(t.Context(), link.ID)
+
+			require.NoError(t, err)
+			assert.Equal(t, link.GameID, loaded.GameID)
+
+			assert.NoFileExists(t, path, "the legacy file is migrated away")
+			migrated, err := os.ReadFile(filepath.Join(directory, "share-links", link.ID+".json"))
+			require.NoError(t, err)
+			assert.NotContains(t, string(migrated), link.Token, "links are migrated without their tokens")
+		})
+	}
+}
+
+func TestFS_SaveShareLink_Instances(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		revoke      bool
+		wantRevoked bool
+	}{
+		{name: "keeps links saved by another instance"},
+		{name: "keeps a revocation when another instance saves a link", revoke: true, wantRevoked: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			directory := t.TempDir()
+			first := store.NewFS(directory, slog.New(slog.DiscardHandler))
+			second := store.NewFS(directory, slog.New(slog.DiscardHandler))
+
+			link := newShareLink("game", time.Hour)
+			require.NoError(t, first.SaveShareLink(t.Context(), link))
+
+			// the second instance reads the links before the first changes them
+			_, err := second.ShareLinksByGame(t.Context(), "game")
+			require.NoError(t, err)
+
+			if test.revoke {
+				link.Revoked = true
+				require.NoError(t, first.SaveShareLink(t.Context(), link))
 			}
+			require.NoError(t, second.SaveShareLink(t.Context(), newShareLink("game", time.Hour)))
+
+			links, err := first.ShareLinksByGame(t.Context(), "game")
+			require.NoError(t, err)
+			assert.Len(t, links, 2)
+
+			stored, err := first.ShareLinkByID(t.Context(), link.ID)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantRevoked, stored.Revoked)
 		})
 	}
 }
 
-// newSavableGame builds a started game with a word on the board so the
-// roundtrip covers players, racks, and board replay.
-func newSavableGame(t *testing.T) *words.Game {
-	t.Helper()
+func TestFS_MigrateGames(t *testing.T) {
+	t.Parallel()
 
-	game := words.NewGame(words.Config{
-		LetterDistribution: map[rune]int{'A': 10},
-		LetterPoints:       map[rune]int{'A': 1},
-		RackSize:           3,
-	})
+	tests := []struct {
+		name         string
+		unversioned  bool
+		wantMigrated int
+	}{
+		{name: "rewrites a snapshot from before schemas", unversioned: true, wantMigrated: 1},
+		{name: "leaves a current snapshot alone"},
+	}
 
-	_, err := game.AddPlayer("player-0")
-	require.NoError(t, err)
-	require.NoError(t, game.Start())
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			directory := t.TempDir()
+			fileStore := store.NewFS(directory, slog.New(slog.DiscardHandler))
+
+			game := newSavableGame(t)
+			require.NoError(t, fileStore.SaveGame(t.Context(), game))
+
+			path := filepath.Join(directory, game.ID()+".json.gz")
+			if test.unversioned {
+				writeUnversionedSnapshot(t, path, game.State())
+			}
+			savedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+			require.NoError(t, os.Chtimes(path, savedAt, savedAt))
+
+			migrated, err := fileStore.MigrateGames(t.Context())
+
+			require.NoError(t, err)
+			assert.Equal(t, test.wantMigrated, migrated)
+
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.True(t, savedAt.Equal(info.ModTime()))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			decompressor, err := gzip.NewReader(bytes.NewReader(data))
+			require.NoError(t, err)
+			snapshot, err := io.ReadAll(decompressor)
+			require.NoError(t, err)
 
-	word := words.NewWord(words.NewPoint(0, 0), words.DirectionHorizontal, "AA")
-	_, err = game.PlayWord(game.CurrentPlayerID(), word)
+			schema, err := words.StateSchema(snapshot)
+			require.NoError(t, err)
+			assert.Equal(t, words.CurrentSchema, schema)
+
+			loaded, err := fileStore.GameByID(t.Context(), game.ID())
+			require.NoError(t, err)
+			assert.Equal(t, game.Version(), loaded.Version())
+		})
+	}
+}
+
+// writeUnversionedSnapshot writes the state as it was stored before
+// snapshots carried a schema, version, or shuffle seed.
+func writeUnversionedSnapshot(t *testing.T, path string, state words.GameState) {
+	t.Helper()
+
+	data, err := json.Marshal(state)
 	require.NoError(t, err)
 
-	return game
+	var document map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &document))
+	delete(document, "schema")
+	delete(document, "version")
+	delete(document, "shuffleSeed")
+
+	var compressed bytes.Buffer
+	compressor := gzip.NewWriter(&compressed)
+	require.NoError(t, json.NewEncoder(compressor).Encode(document))
+	require.NoError(t, compressor.Close())
+	require.NoError(t, os.WriteFile(path, compressed.Bytes(), 0o644))
+}
+
+func TestFS_Lock(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		holder     string
+		staleFile  bool
+		superseded bool
+		wantErr    error
+	}{
+		{name: "blocks another instance while held", holder: "other instance", wantErr: context.DeadlineExceeded},
+		{name: "blocks this instance while held", holder: "same instance", wantErr: context.DeadlineExceeded},
+		{name: "takes over an expired lease", staleFile: true},
+		{name: "takes a free lease"},
+		{name: "leaves the lock file of whoever superseded it", superseded: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			directory := t.TempDir()
+			fileStore := store.NewFS(directory, slog.New(slog.DiscardHandler))
+
+			switch test.holder {
+			case "other instance":
+				unlock, err := store.NewFS(directory, slog.New(slog.DiscardHandler)).Lock(t.Context(), "game")
+				require.NoError(t, err)
+				defer unlock()
+			case "same instance":
+				unlock, err := fileStore.Lock(t.Context(), "game")
+				require.NoError(t, err)
+				defer unlock()
+			}
+
+			if test.staleFile {
+				lockFile := filepath.Join(directory, "game.lock")
+				require.NoError(t, os.WriteFile(lockFile, []byte("crashed"), 0o644))
+				stale := time.Now().Add(-time.Minute)
+				require.NoError(t, os.Chtimes(lockFile, stale, stale))
+			}
+
+			ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+			defer cancel()
+
+			unlock, err := fileStore.Lock(ctx, "game")
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			lockFile := filepath.Join(directory, "game.lock")
+			if test.superseded {
+				require.NoError(t, os.WriteFile(lockFile, []byte("successor"), 0o644))
+				unlock()
+
+				owner, err := os.ReadFile(lockFile)
+				require.NoError(t, err)
+				assert.Equal(t, "successor", string(owner))
+				return
+			}
+
+			unlock()
+			assert.NoFileExists(t, lockFile)
+		})
+	}
+}
+
+func TestFS_Lock_ContendedTakeover(t *testing.T) {
+	t.Parallel()
+
+	directory := t.TempDir()
+	lockFile := filepath.Join(directory, "game.lock")
+	require.NoError(t, os.WriteFile(lockFile, []byte("crashed"), 0o644))
+	stale := time.Now().Add(-time.Minute)
+	require.NoError(t, os.Chtimes(lockFile, stale, stale))
+
+	// separate stores stand in for separate instances, so only the lease
+	// serializes them
+	var holders, overlaps atomic.Int32
+	var group sync.WaitGroup
+	for range 8 {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+
+			unlock, err := store.NewFS(directory, slog.New(slog.DiscardHandler)).Lock(t.Context(), "game")
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if holders.Add(1) > 1 {
+				overlaps.Add(1)
+			}
+			time.Sleep(5 * time.Millisecond)
+			holders.Add(-1)
+
+			unlock()
+		}()
+	}
+	group.Wait()
+
+	assert.Zero(t, overlaps.Load())
+	assert.NoFileExists(t, lockFile)
 }
@@ -0,0 +1,362 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carterjs/words/internal/crypt"
+	"github.com/carterjs/words/internal/words"
+)
+
+const (
+	// indexDirectory is the name of the directory of game summaries.
+	indexDirectory = "index"
+	// summaryFileSuffix is the extension of a game's summary file.
+	summaryFileSuffix = ".json"
+	// legacyIndexFile is the single file summaries were once all kept in.
+	legacyIndexFile = "index.json"
+)
+
+// gameIndex keeps a summary of every game in a directory, one small file per
+// game, so listing and sweeping games does not decode each one, and saving a
+// game rewrites only its own summary. A missing index is rebuilt by scanning
+// the games. Instances sharing the directory never overwrite each other's
+// summaries, and each remembers the summaries it has read, rereading only
+// files whose modification time or size has changed. Summaries are sealed
+// with the keyring, if there is one, like the games they describe.
+type gameIndex struct {
+	directory string
+	keyring   *crypt.Keyring
+	scan      func(ctx context.Context) ([]words.GameSummary, error)
+
+	mutex  sync.Mutex
+	cached map[string]cachedSummary
+}
+
+// cachedSummary is a summary as last read from its file.
+type cachedSummary struct {
+	modTime time.Time
+	size    int64
+	summary words.GameSummary
+}
+
+func newGameIndex(directory string, keyring *crypt.Keyring, scan func(ctx context.Context) ([]words.GameSummary, error)) *gameIndex {
+	return &gameIndex{
+		directory: filepath.Join(directory, indexDirectory),
+		keyring:   keyring,
+		scan:      scan,
+		cached:    make(map[string]cachedSummary),
+	}
+}
+
+// put adds or replaces a game's summary.
+func (index *gameIndex) put(ctx context.Context, summary words.GameSummary) error {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	if err := index.ensure(ctx); err != nil {
+		return err
+	}
+
+	return index.write(index.directory, summary)
+}
+
+// remove drops the games' summaries.
+func (index *gameIndex) remove(ctx context.Context, gameIDs ...string) error {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	for _, gameID := range gameIDs {
+		if err := os.Remove(index.summaryFile(gameID)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing game summary: %w", err)
+		}
+
+		delete(index.cached, gameID)
+	}
+
+	return nil
+}
+
+// summary returns the game's summary, reporting false if it has none.
+func (index *gameIndex) summary(ctx context.Context, gameID string) (words.GameSummary, bool, error) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	if err := index.ensure(ctx); err != nil {
+		return words.GameSummary{}, false, err
+	}
+
+	info, err := os.Stat(index.summaryFile(gameID))
+	if os.IsNotExist(err) {
+		return words.GameSummary{}, false, nil
+	}
+	if err != nil {
+		return words.GameSummary{}, false, fmt.Errorf("checking game summary: %w", err)
+	}
+
+	summary, err := index.read(gameID, info)
+	if err != nil {
+		return words.GameSummary{}, false, err
+	}
+
+	return summary, true, nil
+}
+
+// summaries returns every game's summary in listing order.
+func (index *gameIndex) summaries(ctx context.Context) ([]words.GameSummary, error) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	if err := index.ensure(ctx); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(index.directory)
+	if err != nil {
+		return nil, fmt.Errorf("reading game index: %w", err)
+	}
+
+	summaries := make(map[string]words.GameSummary, len(entries))
+	for _, entry := range entries {
+		gameID, isSummary := strings.CutSuffix(entry.Name(), summaryFileSuffix)
+		if entry.IsDir() || !isSummary {
+			continue
+		}
+
+		info, err := entry.Info()
+		if os.IsNotExist(err) {
+			// removed since the directory was read
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("checking game summary: %w", err)
+		}
+
+		summary, err := index.read(gameID, info)
+		if err != nil {
+			return nil, err
+		}
+
+		summaries[gameID] = summary
+	}
+
+	// forget games another instance deleted
+	for gameID := range index.cached {
+		if _, exists := summaries[gameID]; !exists {
+			delete(index.cached, gameID)
+		}
+	}
+
+	return sortedSummaries(summaries), nil
+}
+
+// read returns the game's summary, decoding its file only if it has changed
+// since it was last read. The caller must hold the lock.
+func (index *gameIndex) read(gameID string, info os.FileInfo) (words.GameSummary, error) {
+	if cached, exists := index.cached[gameID]; exists && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.summary, nil
+	}
+
+	raw, err := os.ReadFile(index.summaryFile(gameID))
+	if err != nil {
+		return words.GameSummary{}, fmt.Errorf("reading game summary: %w", err)
+	}
+
+	data, err := index.sealer(gameID).open(raw)
+	if err != nil {
+		return words.GameSummary{}, fmt.Errorf("opening game summary %s: %w", gameID, err)
+	}
+
+	var summary words.GameSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return words.GameSummary{}, fmt.Errorf("decoding game summary %s: %w", gameID, err)
+	}
+
+	index.cached[gameID] = cachedSummary{modTime: info.ModTime(), size: info.Size(), summary: summary}
+
+	return summary, nil
+}
+
+// ensure rebuilds the index from the games if it is missing. The rebuild is
+// written to a temporary directory and renamed into place, so other
+// instances see either no index or a complete one. The caller must hold the
+// lock.
+func (index *gameIndex) ensure(ctx context.Context) error {
+	if _, err := os.Stat(index.directory); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("checking game index: %w", err)
+	}
+
+	scanned, err := index.scan(ctx)
+	if err != nil {
+		return fmt.Errorf("rebuilding game index: %w", err)
+	}
+
+	parent := filepath.Dir(index.directory)
+	if err := os.MkdirAll(parent, directoryPermissions); err != nil {
+		return fmt.Errorf("creating games directory: %w", err)
+	}
+
+	building, err := os.MkdirTemp(parent, indexDirectory+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("rebuilding game index: %w", err)
+	}
+	defer os.RemoveAll(building)
+
+	for _, summary := range scanned {
+		if err := index.write(building, summary); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Rename(building, index.directory); err != nil {
+		if _, statErr := os.Stat(index.directory); statErr == nil {
+			// another instance rebuilt it first
+			return nil
+		}
+
+		return fmt.Errorf("replacing game index: %w", err)
+	}
+
+	os.Remove(filepath.Join(parent, legacyIndexFile))
+
+	return nil
+}
+
+// reseal rewrites every summary not sealed with the current key, returning
+// how many it rewrote.
+func (index *gameIndex) reseal() (int, error) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	entries, err := os.ReadDir(index.directory)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("reading game index: %w", err)
+	}
+
+	var rotated int
+	for _, entry := range entries {
+		gameID, isSummary := strings.CutSuffix(entry.Name(), summaryFileSuffix)
+		if entry.IsDir() || !isSummary {
+			continue
+		}
+
+		resealed, err := reseal(index.summaryFile(gameID), index.sealer(gameID))
+		if err != nil {
+			return rotated, err
+		}
+		if resealed {
+			rotated++
+		}
+	}
+
+	return rotated, nil
+}
+
+func (index *gameIndex) summaryFile(gameID string) string {
+	return filepath.Join(index.directory, gameID+summaryFileSuffix)
+}
+
+func (index *gameIndex) sealer(gameID string) sealer {
+	return sealer{keyring: index.keyring, binding: indexDirectory + "/" + gameID}
+}
+
+// write replaces the game's summary file in the directory atomically.
+func (index *gameIndex) write(directory string, summary words.GameSummary) error {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("encoding game summary: %w", err)
+	}
+
+	sealed, err := index.sealer(summary.ID).seal(data)
+	if err != nil {
+		return err
+	}
+
+	if err := replaceFile(filepath.Join(directory, summary.ID+summaryFileSuffix), sealed); err != nil {
+		return fmt.Errorf("replacing game summary: %w", err)
+	}
+
+	return nil
+}
+
+// replaceFile writes the data to a temporary file beside the path and
+// renames it into place, so readers see the old file or the new one.
+func replaceFile(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+// sortedSummaries returns the summaries in listing order: newest first,
+// ties broken by ID.
+func sortedSummaries(summaries map[string]words.GameSummary) []words.GameSummary {
+	sorted := make([]words.GameSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		sorted = append(sorted, summary)
+	}
+
+	sort.Slice(sorted, func(first, second int) bool {
+		if !sorted[first].CreatedAt.Equal(sorted[second].CreatedAt) {
+			return sorted[first].CreatedAt.After(sorted[second].CreatedAt)
+		}
+
+		return sorted[first].ID < sorted[second].ID
+	})
+
+	return sorted
+}
+
+// pageOf returns the page of summaries, already in listing order, that the
+// query selects.
+func pageOf(summaries []words.GameSummary, query words.GameQuery) words.GamePage {
+	page := words.GamePage{Games: []words.GameSummary{}}
+
+	for _, summary := range summaries {
+		if query.After != nil && !query.After.Precedes(summary) {
+			continue
+		}
+
+		if !query.Matches(summary) {
+			continue
+		}
+
+		if query.Limit > 0 && len(page.Games) == query.Limit {
+			page.NextCursor = words.CursorAfter(page.Games[len(page.Games)-1]).String()
+			break
+		}
+
+		page.Games = append(page.Games, summary)
+	}
+
+	return page
+}
+
+// savedAt is the update time recorded for a save happening now.
+func savedAt() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
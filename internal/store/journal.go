@@ -0,0 +1,492 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/carterjs/words/internal/lock"
+	"github.com/carterjs/words/internal/words"
+)
+
+const (
+	// snapshotInterval is how many versions pass between snapshots, bounding
+	// how many revisions a rebuild replays.
+	snapshotInterval = 32
+	// revisionsFile is the name of a game's append-only revision log.
+	revisionsFile = "revisions.jsonl"
+	// revisionReadSize is how much of a revision log is read at a time when
+	// reading it from the end.
+	revisionReadSize = 4096
+)
+
+// Journal stores each game as an append-only log of revisions, the actions
+// every save accepted and the events it recorded, and rebuilds games by
+// replaying the log through the game's own methods. The first version and
+// every snapshotInterval-th are also kept as snapshots, so a rebuild starts
+// from the latest one and replays only what followed it.
+type Journal struct {
+	directory  string
+	saves      *lock.Local
+	index      *gameIndex
+	shareLinks *shareLinkFiles
+}
+
+// NewJournal returns a store keeping a directory per game under the given
+// directory, beside an index of their summaries.
+func NewJournal(directory string) *Journal {
+	journal := &Journal{
+		directory: directory,
+		saves:     lock.NewLocal(),
+	}
+	journal.index = newGameIndex(directory, nil, journal.scanSummaries)
+	journal.shareLinks = newShareLinkFiles(directory, nil)
+
+	return journal
+}
+
+// SaveGame appends the game's revision to its log if the log ends a version
+// behind it, snapshotting the game first when the version calls for one.
+// The check is atomic within a process; instances sharing the directory
+// should also hold the game's lease.
+func (journal *Journal) SaveGame(ctx context.Context, game *words.Game) error {
+	return journal.save(ctx, game, game.Version()-1, savedAt())
+}
+
+// RestoreGame starts the log of a game that is not stored yet with a
+// snapshot of it as it is, at its own version and as last saved at
+// updatedAt, for copying games from another store. A game already stored is
+// refused with words.ErrVersionConflict.
+func (journal *Journal) RestoreGame(ctx context.Context, game *words.Game, updatedAt time.Time) error {
+	return journal.save(ctx, game, 0, updatedAt.UTC().Truncate(time.Millisecond))
+}
+
+// save appends the game's revision if the log ends at the wanted version,
+// dating it updatedAt. A log's first revision is always snapshotted.
+func (journal *Journal) save(ctx context.Context, game *words.Game, want uint64, updatedAt time.Time) error {
+	unlock, err := journal.saves.Lock(ctx, game.ID())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	stored, length, err := journal.lastRevision(game.ID())
+	if err != nil && !errors.Is(err, words.ErrGameNotFound) {
+		return err
+	}
+
+	if stored != want {
+		return fmt.Errorf("saving version %d over %d: %w", game.Version(), stored, words.ErrVersionConflict)
+	}
+
+	if err := os.MkdirAll(journal.gameDirectory(game.ID()), directoryPermissions); err != nil {
+		return fmt.Errorf("creating game directory: %w", err)
+	}
+
+	// a snapshot ahead of the log is ignored, so writing it first means a
+	// failed append leaves nothing inconsistent behind
+	if stored == 0 || game.Version()%snapshotInterval == 0 {
+		if err := writeState(journal.snapshotFile(game.ID(), game.Version()), game.State(), sealer{}); err != nil {
+			return fmt.Errorf("writing snapshot: %w", err)
+		}
+	}
+
+	line, err := json.Marshal(game.Revision())
+	if err != nil {
+		return fmt.Errorf("encoding revision: %w", err)
+	}
+
+	file, err := os.OpenFile(journal.revisionsFile(game.ID()), os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening revision log: %w", err)
+	}
+	defer file.Close()
+
+	// write after the last complete line, overwriting any torn one
+	if err := file.Truncate(length); err != nil {
+		return fmt.Errorf("trimming revision log: %w", err)
+	}
+
+	if _, err := file.WriteAt(append(line, '\n'), length); err != nil {
+		return fmt.Errorf("appending revision: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing revision log: %w", err)
+	}
+
+	// the index is rebuilt from modification times
+	if err := os.Chtimes(journal.revisionsFile(game.ID()), updatedAt, updatedAt); err != nil {
+		return fmt.Errorf("dating revision log: %w", err)
+	}
+
+	if err := journal.index.put(ctx, game.Summary(updatedAt)); err != nil {
+		return fmt.Errorf("indexing game: %w", err)
+	}
+
+	return nil
+}
+
+// GameByID rebuilds a game from its latest snapshot and the revisions after
+// it, reading only those from the end of the log. A game without a log is
+// reported as words.ErrGameNotFound.
+func (journal *Journal) GameByID(ctx context.Context, gameID string) (*words.Game, error) {
+	latest, _, err := journal.lastRevision(gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := journal.snapshotVersions(gameID, latest)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(snapshots) == 0 {
+		return nil, errors.New("rebuilding game: no snapshot to replay from")
+	}
+
+	game, err := journal.readSnapshot(gameID, snapshots[len(snapshots)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := journal.revisionsAfter(gameID, game.Version())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, revision := range revisions {
+		if err := game.Replay(revision); err != nil {
+			return nil, fmt.Errorf("rebuilding game: %w", err)
+		}
+	}
+
+	return game, nil
+}
+
+// Verify replays the game's whole log from its first snapshot and checks
+// that it reproduces every later snapshot exactly. A mismatch, which means
+// replay is no longer deterministic, is reported as words.ErrReplayDiverged.
+func (journal *Journal) Verify(ctx context.Context, gameID string) error {
+	revisions, err := journal.readRevisions(gameID)
+	if err != nil {
+		return err
+	}
+
+	var latest uint64
+	if len(revisions) > 0 {
+		latest = revisions[len(revisions)-1].Version
+	}
+
+	snapshots, err := journal.snapshotVersions(gameID, latest)
+	if err != nil {
+		return err
+	}
+
+	if len(snapshots) == 0 {
+		return errors.New("verifying game: no snapshot to replay from")
+	}
+
+	game, err := journal.readSnapshot(gameID, snapshots[0])
+	if err != nil {
+		return err
+	}
+
+	snapshots = snapshots[1:]
+	for _, revision := range revisions {
+		if revision.Version <= game.Version() {
+			continue
+		}
+
+		if err := game.Replay(revision); err != nil {
+			return fmt.Errorf("verifying game: %w", err)
+		}
+
+		if len(snapshots) == 0 || snapshots[0] != game.Version() {
+			continue
+		}
+
+		stored, err := journal.readSnapshot(gameID, snapshots[0])
+		if err != nil {
+			return err
+		}
+		snapshots = snapshots[1:]
+
+		if err := compareStates(game.State(), stored.State()); err != nil {
+			return fmt.Errorf("verifying version %d: %w", game.Version(), err)
+		}
+	}
+
+	return nil
+}
+
+// compareStates reports whether two snapshots serialize identically.
+func compareStates(replayed, stored words.GameState) error {
+	replayedJSON, err := json.Marshal(replayed)
+	if err != nil {
+		return fmt.Errorf("encoding replayed game: %w", err)
+	}
+
+	storedJSON, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("encoding stored game: %w", err)
+	}
+
+	if !bytes.Equal(replayedJSON, storedJSON) {
+		return words.ErrReplayDiverged
+	}
+
+	return nil
+}
+
+// ListGames returns a page of the indexed games matching the query.
+func (journal *Journal) ListGames(ctx context.Context, query words.GameQuery) (words.GamePage, error) {
+	summaries, err := journal.index.summaries(ctx)
+	if err != nil {
+		return words.GamePage{}, err
+	}
+
+	return pageOf(summaries, query), nil
+}
+
+// DeleteGames deletes the games' logs and snapshots and drops them from the
+// index. Games already gone are skipped.
+func (journal *Journal) DeleteGames(ctx context.Context, gameIDs ...string) error {
+	var (
+		deleted   []string
+		deleteErr error
+	)
+	for _, gameID := range gameIDs {
+		if err := os.RemoveAll(journal.gameDirectory(gameID)); err != nil {
+			deleteErr = fmt.Errorf("deleting game: %w", err)
+			break
+		}
+		deleted = append(deleted, gameID)
+	}
+
+	if err := journal.index.remove(ctx, deleted...); err != nil {
+		return fmt.Errorf("removing deleted games from index: %w", err)
+	}
+
+	if err := journal.shareLinks.removeGames(deleted...); err != nil {
+		return fmt.Errorf("removing deleted games' share links: %w", err)
+	}
+
+	return deleteErr
+}
+
+// scanSummaries rebuilds every game in the directory, taking each log's
+// modification time as its last save.
+func (journal *Journal) scanSummaries(ctx context.Context) ([]words.GameSummary, error) {
+	entries, err := os.ReadDir(journal.directory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("reading games directory: %w", err)
+	}
+
+	var summaries []words.GameSummary
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		info, err := os.Stat(journal.revisionsFile(entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		game, err := journal.GameByID(ctx, entry.Name())
+		if err != nil {
+			continue
+		}
+
+		summaries = append(summaries, game.Summary(info.ModTime().UTC().Truncate(time.Millisecond)))
+	}
+
+	return summaries, nil
+}
+
+// readRevisions reads a game's whole log in order.
+func (journal *Journal) readRevisions(gameID string) ([]words.Revision, error) {
+	var revisions []words.Revision
+	_, err := journal.readRevisionsBackward(gameID, func(revision words.Revision) bool {
+		revisions = append(revisions, revision)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(revisions)
+
+	return revisions, nil
+}
+
+// revisionsAfter reads the revisions in a game's log after the given
+// version in order, decoding nothing before them.
+func (journal *Journal) revisionsAfter(gameID string, version uint64) ([]words.Revision, error) {
+	var revisions []words.Revision
+	_, err := journal.readRevisionsBackward(gameID, func(revision words.Revision) bool {
+		if revision.Version <= version {
+			return false
+		}
+
+		revisions = append(revisions, revision)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(revisions)
+
+	return revisions, nil
+}
+
+// lastRevision returns the version of the last revision in a game's log,
+// or zero if it has none, along with the length of its complete lines.
+func (journal *Journal) lastRevision(gameID string) (uint64, int64, error) {
+	var version uint64
+	length, err := journal.readRevisionsBackward(gameID, func(revision words.Revision) bool {
+		version = revision.Version
+		return false
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return version, length, nil
+}
+
+// readRevisionsBackward reads a game's log from its last line toward its
+// first, handing each revision to visit until it returns false, and returns
+// the length of the log's complete lines. A torn last line, left by a crash
+// mid-append, was never acknowledged and is skipped.
+func (journal *Journal) readRevisionsBackward(gameID string, visit func(words.Revision) bool) (int64, error) {
+	file, err := os.Open(journal.revisionsFile(gameID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, words.ErrGameNotFound
+		}
+
+		return 0, fmt.Errorf("opening revision log: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("reading revision log: %w", err)
+	}
+
+	// pending holds the unvisited bytes from position on; once the end of
+	// the last complete line is known, it always ends with a line's newline
+	var (
+		position = info.Size()
+		pending  []byte
+		length   int64 = -1
+	)
+	for {
+		if length < 0 {
+			if end := bytes.LastIndexByte(pending, '\n'); end >= 0 {
+				length = position + int64(end) + 1
+				pending = pending[:end+1]
+			}
+		}
+
+		if length >= 0 {
+			start := bytes.LastIndexByte(pending[:len(pending)-1], '\n') + 1
+			if start > 0 || position == 0 {
+				var revision words.Revision
+				if err := json.Unmarshal(pending[start:], &revision); err != nil {
+					return 0, fmt.Errorf("decoding revision: %w", err)
+				}
+
+				pending = pending[:start]
+				if !visit(revision) || len(pending) == 0 {
+					return length, nil
+				}
+
+				continue
+			}
+		}
+
+		if position == 0 {
+			// no complete line at all
+			return 0, nil
+		}
+
+		size := min(int64(revisionReadSize), position)
+		position -= size
+
+		chunk := make([]byte, size, size+int64(len(pending)))
+		if _, err := file.ReadAt(chunk, position); err != nil {
+			return 0, fmt.Errorf("reading revision log: %w", err)
+		}
+		pending = append(chunk, pending...)
+	}
+}
+
+// snapshotVersions returns the versions of the game's snapshots up to the
+// given one, oldest first.
+func (journal *Journal) snapshotVersions(gameID string, upTo uint64) ([]uint64, error) {
+	entries, err := os.ReadDir(journal.gameDirectory(gameID))
+	if err != nil {
+		return nil, fmt.Errorf("reading game directory: %w", err)
+	}
+
+	var versions []uint64
+	for _, entry := range entries {
+		name, isSnapshot := strings.CutSuffix(entry.Name(), gameFileSuffix)
+		if !isSnapshot {
+			continue
+		}
+
+		version, err := strconv.ParseUint(name, 10, 64)
+		if err != nil || version > upTo {
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	slices.Sort(versions)
+
+	return versions, nil
+}
+
+func (journal *Journal) readSnapshot(gameID string, version uint64) (*words.Game, error) {
+	state, err := readState(journal.snapshotFile(gameID, version), sealer{})
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot %d: %w", version, err)
+	}
+
+	game, err := words.NewGameFromState(state)
+	if err != nil {
+		return nil, fmt.Errorf("rebuilding snapshot %d: %w", version, err)
+	}
+
+	return game, nil
+}
+
+func (journal *Journal) gameDirectory(gameID string) string {
+	return filepath.Join(journal.directory, gameID)
+}
+
+func (journal *Journal) revisionsFile(gameID string) string {
+	return filepath.Join(journal.gameDirectory(gameID), revisionsFile)
+}
+
+func (journal *Journal) snapshotFile(gameID string, version uint64) string {
+	return filepath.Join(journal.gameDirectory(gameID), strconv.FormatUint(version, 10)+gameFileSuffix)
+}
@@ -0,0 +1,185 @@
+package store_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/carterjs/words/internal/lock"
+	"github.com/carterjs/words/internal/store"
+	"github.com/carterjs/words/internal/words"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal_GameByID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		toggles int
+		tamper  func(log []byte) []byte
+	}{
+		{name: "replays from the first snapshot", toggles: 1},
+		{name: "replays from a later snapshot", toggles: 40},
+		{
+			name:    "decodes nothing before the latest snapshot",
+			toggles: 40,
+			tamper: func(log []byte) []byte {
+				return append([]byte("not a revision\n"), log[bytes.IndexByte(log, '\n')+1:]...)
+			},
+		},
+		{
+			name:    "skips a torn last line",
+			toggles: 40,
+			tamper: func(log []byte) []byte {
+				return append(log, `{"version":`...)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			directory := t.TempDir()
+			journal := store.NewJournal(directory)
+			game := playJournaledGame(t, journal, test.toggles)
+
+			if test.tamper != nil {
+				path := filepath.Join(directory, game.ID(), "revisions.jsonl")
+				log, err := os.ReadFile(path)
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(path, test.tamper(log), 0o644))
+			}
+
+			loaded, err := journal.GameByID(t.Context(), game.ID())
+
+			require.NoError(t, err)
+
+			// times come back from the log without their monotonic readings,
+			// so compare the games as they would be stored
+			want, err := json.Marshal(game.State())
+			require.NoError(t, err)
+			got, err := json.Marshal(loaded.State())
+			require.NoError(t, err)
+			assert.JSONEq(t, string(want), string(got))
+		})
+	}
+}
+
+func TestJournal_Verify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		tamper  bool
+		wantErr error
+	}{
+		{name: "accepts a log that replays to its snapshots"},
+		{name: "detects a snapshot the log no longer reproduces", tamper: true, wantErr: words.ErrReplayDiverged},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			directory := t.TempDir()
+			journal := store.NewJournal(directory)
+			game := playJournaledGame(t, journal, 40)
+
+			if test.tamper {
+				tamperSnapshot(t, filepath.Join(directory, game.ID(), "32.json.gz"))
+			}
+
+			err := journal.Verify(t.Context(), game.ID())
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// playJournaledGame plays a game through a service backed by the journal:
+// joins, chat, a word, an upheld challenge, an exchange, and then the given
+// number of mute toggles to run the version past snapshots. It returns the
+// game as last saved.
+func playJournaledGame(t *testing.T, journal *store.Journal, toggles int) *words.Game {
+	t.Helper()
+
+	ctx := t.Context()
+	service := words.NewService(journal, &words.MockBroker{
+		PublishFunc: func(context.Context, string, words.Event) {},
+	}, lock.NewLocal(), slog.New(slog.DiscardHandler))
+
+	game, err := service.CreateGame(ctx, "standard", words.ConfigOverrides{
+		LetterDistribution: map[rune]int{words.BlankLetter: 0},
+	})
+	require.NoError(t, err)
+
+	_, host, err := service.JoinGame(ctx, game.ID(), "host")
+	require.NoError(t, err)
+	_, guest, err := service.JoinGame(ctx, game.ID(), "guest")
+	require.NoError(t, err)
+	_, spectator, err := service.SpectateGame(ctx, game.ID(), "spectator")
+	require.NoError(t, err)
+
+	_, _, err = service.SendMessage(ctx, game.ID(), host.ID(), "", "hello")
+	require.NoError(t, err)
+
+	game, err = service.StartGame(ctx, game.ID())
+	require.NoError(t, err)
+
+	hostPlayer, _ := game.PlayerByID(host.ID())
+	word := words.NewWord(words.NewPoint(0, 0), words.DirectionHorizontal, string(hostPlayer.Letters()[:2]))
+	_, _, err = service.PlayWord(ctx, game.ID(), host.ID(), word)
+	require.NoError(t, err)
+
+	_, _, err = service.ChallengeWord(ctx, game.ID(), guest.ID())
+	require.NoError(t, err)
+
+	game, err = service.GameByID(ctx, game.ID())
+	require.NoError(t, err)
+	guestPlayer, _ := game.PlayerByID(guest.ID())
+	game, err = service.ExchangeLetters(ctx, game.ID(), guest.ID(), guestPlayer.Letters()[:1])
+	require.NoError(t, err)
+
+	for toggle := range toggles {
+		game, err = service.MuteParticipant(ctx, game.ID(), host.ID(), spectator.ID(), toggle%2 == 0)
+		require.NoError(t, err)
+	}
+
+	return game
+}
+
+// tamperSnapshot rewrites a snapshot with a different round.
+func tamperSnapshot(t *testing.T, path string) {
+	t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	decompressor, err := gzip.NewReader(file)
+	require.NoError(t, err)
+
+	var state words.GameState
+	require.NoError(t, json.NewDecoder(decompressor).Decode(&state))
+	require.NoError(t, file.Close())
+
+	state.Round++
+
+	file, err = os.Create(path)
+	require.NoError(t, err)
+	compressor := gzip.NewWriter(file)
+	require.NoError(t, json.NewEncoder(compressor).Encode(state))
+	require.NoError(t, compressor.Close())
+	require.NoError(t, file.Close())
+}
@@ -0,0 +1,240 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// leaseDuration is how long a lock file stays valid without renewal. A
+	// holder that crashes blocks the game for at most this long.
+	leaseDuration = 15 * time.Second
+	// leaseRenewInterval is how often a live holder extends its lease.
+	leaseRenewInterval = leaseDuration / 3
+	// leasePollInterval is how often a waiter checks a held lock file.
+	leasePollInterval = 25 * time.Millisecond
+	// lockFileSuffix is the extension of lease lock files.
+	lockFileSuffix = ".lock"
+	// takeoverSuffix is appended to a lock file's name for the guard held
+	// while taking over, renewing or releasing it.
+	takeoverSuffix = ".takeover"
+)
+
+// Lock takes a lease on the game, so every instance sharing the directory
+// serializes its mutations. The lease is a lock file next to the snapshot
+// holding the holder's token, whose modification time is renewed while held;
+// a lock file older than the lease duration belongs to a crashed holder and
+// is taken over. Waiters in this process queue on an in-process lock first
+// rather than all polling.
+func (fileStore *FS) Lock(ctx context.Context, gameID string) (func(), error) {
+	unlockLocal, err := fileStore.locks.Lock(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(fileStore.directory, directoryPermissions); err != nil {
+		unlockLocal()
+		return nil, fmt.Errorf("creating games directory: %w", err)
+	}
+
+	path := fileStore.lockFile(gameID)
+	token := uuid.NewString()
+
+	for {
+		acquired, err := tryLease(path, token)
+		if err != nil {
+			unlockLocal()
+			return nil, err
+		}
+
+		if acquired {
+			break
+		}
+
+		select {
+		case <-time.After(leasePollInterval):
+		case <-ctx.Done():
+			unlockLocal()
+			return nil, ctx.Err()
+		}
+	}
+
+	stopRenewing := make(chan struct{})
+	renewed := make(chan struct{})
+	go renewLease(path, token, stopRenewing, renewed)
+
+	return func() {
+		close(stopRenewing)
+		<-renewed
+
+		releaseLease(path, token)
+		unlockLocal()
+	}, nil
+}
+
+// tryLease creates the lock file if it is free, or takes it over if its
+// lease has run out.
+func tryLease(path, token string) (bool, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err == nil {
+		defer file.Close()
+
+		if _, err := file.WriteString(token); err != nil {
+			os.Remove(path)
+			return false, fmt.Errorf("writing lock file: %w", err)
+		}
+
+		return true, nil
+	}
+
+	if !errors.Is(err, os.ErrExist) {
+		return false, fmt.Errorf("creating lock file: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || !leaseExpired(info) {
+		// held, or released between the create and the stat; try again
+		return false, nil
+	}
+
+	return takeOverLease(path, token)
+}
+
+// takeOverLease replaces an expired lock file with one holding the token.
+// Takeovers, renewals and releases all happen under the takeover guard, so
+// a waiter can never replace a lock file another waiter has just taken
+// over, nor a holder renew or remove one it no longer owns.
+func takeOverLease(path, token string) (bool, error) {
+	release, guarded := guardLease(path)
+	if !guarded {
+		return false, nil
+	}
+	defer release()
+
+	// look again now that nobody else can take over: the holder may have
+	// renewed or released since the first look
+	info, err := os.Stat(path)
+	if err != nil || !leaseExpired(info) {
+		return false, nil
+	}
+
+	temporary := path + "." + token + ".tmp"
+	if err := os.WriteFile(temporary, []byte(token), 0o644); err != nil {
+		return false, fmt.Errorf("writing lock file: %w", err)
+	}
+
+	if err := os.Rename(temporary, path); err != nil {
+		os.Remove(temporary)
+		return false, fmt.Errorf("replacing expired lock file: %w", err)
+	}
+
+	return ownsLease(path, token), nil
+}
+
+// releaseLease removes the lock file if it still holds the token. A holder
+// that stalled past its lease may have been superseded, and must leave the
+// new holder's file alone.
+func releaseLease(path, token string) {
+	for {
+		release, guarded := guardLease(path)
+		if guarded {
+			if ownsLease(path, token) {
+				os.Remove(path)
+			}
+			release()
+			return
+		}
+
+		time.Sleep(leasePollInterval)
+	}
+}
+
+// guardLease creates the lock file's takeover guard, reporting false if
+// someone else holds it. Guards are only held for a few file operations, so
+// one as old as a lease belongs to a crashed process and is cleared.
+func guardLease(path string) (func(), bool) {
+	guard := path + takeoverSuffix
+
+	file, err := os.OpenFile(guard, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err == nil {
+		file.Close()
+		return func() { os.Remove(guard) }, true
+	}
+
+	if info, err := os.Stat(guard); err == nil && leaseExpired(info) {
+		// move the stale guard aside rather than deleting it, so only one of
+		// several waiters noticing it at once clears it
+		stale := guard + "." + uuid.NewString()
+		if err := os.Rename(guard, stale); err == nil {
+			os.Remove(stale)
+		}
+	}
+
+	return nil, false
+}
+
+// ownsLease reports whether the lock file holds the token.
+func ownsLease(path, token string) bool {
+	owner, err := os.ReadFile(path)
+	return err == nil && string(owner) == token
+}
+
+func leaseExpired(info os.FileInfo) bool {
+	return time.Since(info.ModTime()) > leaseDuration
+}
+
+// renewLease touches the lock file until stopped, or until it finds the
+// lease has been taken over by someone else.
+func renewLease(path, token string, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(leaseRenewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !extendLease(path, token, stop) {
+				return
+			}
+		}
+	}
+}
+
+// extendLease touches the lock file if it still holds the token, reporting
+// whether it did. The check and the touch happen under the takeover guard,
+// so a lease that expired in between cannot be taken over and then renewed
+// for its new holder. It gives up if stopped while waiting for the guard.
+func extendLease(path, token string, stop <-chan struct{}) bool {
+	for {
+		release, guarded := guardLease(path)
+		if guarded {
+			defer release()
+
+			if !ownsLease(path, token) {
+				return false
+			}
+
+			now := time.Now()
+			return os.Chtimes(path, now, now) == nil
+		}
+
+		select {
+		case <-time.After(leasePollInterval):
+		case <-stop:
+			return false
+		}
+	}
+}
+
+func (fileStore *FS) lockFile(gameID string) string {
+	return filepath.Join(fileStore.directory, gameID+lockFileSuffix)
+}
@@ -0,0 +1,167 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/carterjs/words/internal/crypt"
+)
+
+// errNoKeyring reports an encrypted snapshot read by a store with no keys.
+var errNoKeyring = errors.New("snapshot is encrypted but no keyring is configured")
+
+// sealer encrypts files at rest, binding each to what it holds, such as a
+// game's ID, so files cannot be swapped for one another. The zero sealer
+// leaves files plain.
+type sealer struct {
+	keyring *crypt.Keyring
+	binding string
+}
+
+func (fileStore *FS) sealer(gameID string) sealer {
+	return sealer{keyring: fileStore.keyring, binding: gameID}
+}
+
+// seal encrypts a snapshot under the current key, if there is a keyring.
+func (sealer sealer) seal(data []byte) ([]byte, error) {
+	if sealer.keyring == nil {
+		return data, nil
+	}
+
+	sealed, err := sealer.keyring.Seal(data, []byte(sealer.binding))
+	if err != nil {
+		return nil, fmt.Errorf("sealing game: %w", err)
+	}
+
+	return sealed, nil
+}
+
+// open decrypts a sealed snapshot, passing a plain one through unchanged.
+func (sealer sealer) open(data []byte) ([]byte, error) {
+	if !crypt.IsSealed(data) {
+		return data, nil
+	}
+
+	if sealer.keyring == nil {
+		return nil, errNoKeyring
+	}
+
+	opened, err := sealer.keyring.Open(data, []byte(sealer.binding))
+	if err != nil {
+		return nil, fmt.Errorf("opening game: %w", err)
+	}
+
+	return opened, nil
+}
+
+// RotateKeys reseals every snapshot, backup, game summary and share link not
+// already sealed with the keyring's current key, including unencrypted ones,
+// returning how many files it rewrote. Once it finishes, older keys can be
+// dropped from the keyring. Snapshots keep their modification times. Run it
+// while no other instance is saving games to the directory.
+func (fileStore *FS) RotateKeys(ctx context.Context) (int, error) {
+	if fileStore.keyring == nil {
+		return 0, errors.New("rotating keys without a keyring")
+	}
+
+	gameIDs, err := fileStore.gameIDs()
+	if err != nil {
+		return 0, err
+	}
+
+	var rotated int
+	for _, gameID := range gameIDs {
+		count, err := fileStore.rotateGame(ctx, gameID)
+		rotated += count
+		if err != nil {
+			return rotated, fmt.Errorf("rotating game %s: %w", gameID, err)
+		}
+	}
+
+	count, err := fileStore.index.reseal()
+	rotated += count
+	if err != nil {
+		return rotated, fmt.Errorf("rotating game index: %w", err)
+	}
+
+	count, err = fileStore.shareLinks.reseal()
+	rotated += count
+	if err != nil {
+		return rotated, fmt.Errorf("rotating share links: %w", err)
+	}
+
+	return rotated, nil
+}
+
+// rotateGame reseals the game's snapshot and backups, returning how many it
+// rewrote.
+func (fileStore *FS) rotateGame(ctx context.Context, gameID string) (int, error) {
+	unlock, err := fileStore.saves.Lock(ctx, gameID)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	versions, err := fileStore.backupVersions(gameID)
+	if err != nil {
+		return 0, err
+	}
+
+	paths := []string{fileStore.gameFile(gameID)}
+	for _, version := range versions {
+		paths = append(paths, fileStore.backupFile(gameID, version))
+	}
+
+	var rotated int
+	for _, path := range paths {
+		resealed, err := reseal(path, fileStore.sealer(gameID))
+		if err != nil {
+			return rotated, err
+		}
+		if resealed {
+			rotated++
+		}
+	}
+
+	return rotated, nil
+}
+
+// reseal rewrites the file sealed with the sealer's current key if it is
+// not already, keeping its modification time, and reports whether it did.
+func reseal(path string, sealer sealer) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("checking game file: %w", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("reading game file: %w", err)
+	}
+
+	if keyID, sealed := crypt.KeyID(raw); sealed && keyID == sealer.keyring.CurrentKeyID() {
+		return false, nil
+	}
+
+	opened, err := sealer.open(raw)
+	if err != nil {
+		return false, err
+	}
+
+	resealed, err := sealer.seal(opened)
+	if err != nil {
+		return false, err
+	}
+
+	if err := writeFile(path, resealed); err != nil {
+		return false, err
+	}
+
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		return false, fmt.Errorf("restoring game file time: %w", err)
+	}
+
+	return true, nil
+}
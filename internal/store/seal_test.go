@@ -0,0 +1,128 @@
+package store_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/carterjs/words/internal/crypt"
+	"github.com/carterjs/words/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedFS_GameByID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		saveKey string
+		readKey string
+		older   []string
+		wantErr bool
+	}{
+		{name: "reads a game it encrypted", saveKey: "a", readKey: "a"},
+		{name: "reads a game encrypted under an older key", saveKey: "a", readKey: "b", older: []string{"a"}},
+		{name: "reads a game stored unencrypted", readKey: "a"},
+		{name: "refuses a game encrypted under an unknown key", saveKey: "a", readKey: "b", wantErr: true},
+		{name: "refuses an encrypted game without a keyring", saveKey: "a", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			directory := t.TempDir()
+
+			game := newSavableGame(t)
+			require.NoError(t, openEncryptedFS(t, directory, test.saveKey).SaveGame(t.Context(), game))
+
+			link := newShareLink(game.ID(), time.Hour)
+			require.NoError(t, openEncryptedFS(t, directory, test.saveKey).SaveShareLink(t.Context(), link))
+
+			for _, name := range []string{game.ID() + ".json.gz", filepath.Join("index", game.ID()+".json"), filepath.Join("share-links", link.ID+".json")} {
+				data, err := os.ReadFile(filepath.Join(directory, name))
+				require.NoError(t, err)
+				assert.Equal(t, test.saveKey != "", crypt.IsSealed(data), name)
+				assert.NotContains(t, string(data), link.Token, name)
+			}
+
+			loaded, err := openEncryptedFS(t, directory, test.readKey, test.older...).GameByID(t.Context(), game.ID())
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, game.State(), loaded.State())
+
+			shared, err := openEncryptedFS(t, directory, test.readKey, test.older...).ShareLinkByID(t.Context(), link.ID)
+			require.NoError(t, err)
+			assert.Equal(t, link.GameID, shared.GameID)
+		})
+	}
+}
+
+func TestFS_RotateKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		saveKey     string
+		wantRotated int
+	}{
+		{name: "reseals a game, its backups and summary under an older key", saveKey: "a", wantRotated: 4},
+		{name: "encrypts a game stored unencrypted", wantRotated: 4},
+		{name: "leaves a game under the current key alone", saveKey: "b"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			directory := t.TempDir()
+
+			game := newSavableGame(t)
+			saving := openEncryptedFS(t, directory, test.saveKey)
+			for version := uint64(1); version <= 3; version++ {
+				require.NoError(t, saving.SaveGame(t.Context(), withVersion(t, game, version)))
+			}
+
+			rotated, err := openEncryptedFS(t, directory, "b", "a").RotateKeys(t.Context())
+
+			require.NoError(t, err)
+			assert.Equal(t, test.wantRotated, rotated)
+
+			// with the old key dropped, the game and its backups still open
+			rotatedStore := openEncryptedFS(t, directory, "b")
+			loaded, err := rotatedStore.GameByID(t.Context(), game.ID())
+			require.NoError(t, err)
+			assert.Equal(t, uint64(3), loaded.Version())
+
+			paths, err := filepath.Glob(filepath.Join(directory, "backups", game.ID(), "*"))
+			require.NoError(t, err)
+			paths = append(paths, filepath.Join(directory, game.ID()+".json.gz"), filepath.Join(directory, "index", game.ID()+".json"))
+			for _, path := range paths {
+				data, err := os.ReadFile(path)
+				require.NoError(t, err)
+				keyID, _ := crypt.KeyID(data)
+				assert.Equal(t, "b", keyID, path)
+			}
+		})
+	}
+}
+
+// openEncryptedFS opens the directory sealing with the named key, or
+// unencrypted if it is empty.
+func openEncryptedFS(t *testing.T, directory string, current string, older ...string) *store.FS {
+	t.Helper()
+
+	if current == "" {
+		return store.NewFS(directory, slog.New(slog.DiscardHandler))
+	}
+
+	return store.NewEncryptedFS(directory, newKeyring(t, current, older...), slog.New(slog.DiscardHandler))
+}
@@ -0,0 +1,444 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carterjs/words/internal/crypt"
+	"github.com/carterjs/words/internal/words"
+)
+
+const (
+	// shareLinksDirectory is the name of the directory of share links.
+	shareLinksDirectory = "share-links"
+	// shareLinkFileSuffix is the extension of a share link's file.
+	shareLinkFileSuffix = ".json"
+	// legacyShareLinksFile is the single file share links were once all kept
+	// in.
+	legacyShareLinksFile = "share-links.json"
+)
+
+// shareLinkFiles keeps every share link of a directory's games in a file of
+// its own, named by the link's ID, so instances sharing the directory never
+// overwrite each other's links and opening a shared view reads only its
+// link. Links that have expired are deleted whenever one is saved. Files are
+// sealed with the keyring, if there is one, and hold links by ID, never with
+// their tokens.
+type shareLinkFiles struct {
+	directory string
+	keyring   *crypt.Keyring
+
+	// mutex guards migrating the legacy file, once
+	mutex    sync.Mutex
+	migrated bool
+}
+
+// legacyShareLinks is the serialized form of the legacy file.
+type legacyShareLinks struct {
+	Links []words.ShareLink `json:"links"`
+}
+
+func newShareLinkFiles(directory string, keyring *crypt.Keyring) *shareLinkFiles {
+	return &shareLinkFiles{
+		directory: filepath.Join(directory, shareLinksDirectory),
+		keyring:   keyring,
+	}
+}
+
+// put adds or replaces a link, dropping its token, and deletes links that
+// have expired.
+func (files *shareLinkFiles) put(link words.ShareLink) error {
+	if err := files.migrate(); err != nil {
+		return err
+	}
+
+	link.Token = ""
+	if err := files.write(link); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return files.removeWhere(func(link words.ShareLink) bool {
+		return !now.Before(link.ExpiresAt)
+	})
+}
+
+// removeGames drops every link to the games.
+func (files *shareLinkFiles) removeGames(gameIDs ...string) error {
+	if len(gameIDs) == 0 {
+		return nil
+	}
+
+	if err := files.migrate(); err != nil {
+		return err
+	}
+
+	return files.removeWhere(func(link words.ShareLink) bool {
+		return slices.Contains(gameIDs, link.GameID)
+	})
+}
+
+// byID returns the link with the ID, or words.ErrShareLinkNotFound.
+func (files *shareLinkFiles) byID(linkID string) (words.ShareLink, error) {
+	if err := files.migrate(); err != nil {
+		return words.ShareLink{}, err
+	}
+
+	// IDs are SHA-256 digests, and anything else must not reach a path
+	if decoded, err := hex.DecodeString(linkID); err != nil || len(decoded) != sha256.Size {
+		return words.ShareLink{}, words.ErrShareLinkNotFound
+	}
+
+	link, err := files.read(linkID)
+	if os.IsNotExist(err) {
+		return words.ShareLink{}, words.ErrShareLinkNotFound
+	}
+
+	return link, err
+}
+
+// byGame returns every link to the game.
+func (files *shareLinkFiles) byGame(gameID string) ([]words.ShareLink, error) {
+	if err := files.migrate(); err != nil {
+		return nil, err
+	}
+
+	links, err := files.all()
+	if err != nil {
+		return nil, err
+	}
+
+	var matching []words.ShareLink
+	for _, link := range links {
+		if link.GameID == gameID {
+			matching = append(matching, link)
+		}
+	}
+
+	return matching, nil
+}
+
+// removeWhere deletes every link the predicate selects.
+func (files *shareLinkFiles) removeWhere(selects func(link words.ShareLink) bool) error {
+	links, err := files.all()
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		if !selects(link) {
+			continue
+		}
+
+		if err := os.Remove(files.file(link.ID)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing share link: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// all reads every link, oldest first.
+func (files *shareLinkFiles) all() ([]words.ShareLink, error) {
+	entries, err := os.ReadDir(files.directory)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading share links: %w", err)
+	}
+
+	var links []words.ShareLink
+	for _, entry := range entries {
+		linkID, isLink := strings.CutSuffix(entry.Name(), shareLinkFileSuffix)
+		if entry.IsDir() || !isLink {
+			continue
+		}
+
+		link, err := files.read(linkID)
+		if os.IsNotExist(err) {
+			// removed since the directory was read
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		links = append(links, link)
+	}
+
+	slices.SortFunc(links, func(first, second words.ShareLink) int {
+		return first.CreatedAt.Compare(second.CreatedAt)
+	})
+
+	return links, nil
+}
+
+// read reads the link with the ID, passing a missing file's error through.
+func (files *shareLinkFiles) read(linkID string) (words.ShareLink, error) {
+	raw, err := os.ReadFile(files.file(linkID))
+	if os.IsNotExist(err) {
+		return words.ShareLink{}, err
+	}
+	if err != nil {
+		return words.ShareLink{}, fmt.Errorf("reading share link: %w", err)
+	}
+
+	data, err := files.sealer(linkID).open(raw)
+	if err != nil {
+		return words.ShareLink{}, fmt.Errorf("opening share link %s: %w", linkID, err)
+	}
+
+	var link words.ShareLink
+	if err := json.Unmarshal(data, &link); err != nil {
+		return words.ShareLink{}, fmt.Errorf("decoding share link %s: %w", linkID, err)
+	}
+
+	return link, nil
+}
+
+// write replaces the link's file atomically.
+func (files *shareLinkFiles) write(link words.ShareLink) error {
+	if err := os.MkdirAll(files.directory, directoryPermissions); err != nil {
+		return fmt.Errorf("creating share links directory: %w", err)
+	}
+
+	data, err := json.Marshal(link)
+	if err != nil {
+		return fmt.Errorf("encoding share link: %w", err)
+	}
+
+	sealed, err := files.sealer(link.ID).seal(data)
+	if err != nil {
+		return err
+	}
+
+	if err := replaceFile(files.file(link.ID), sealed); err != nil {
+		return fmt.Errorf("replacing share link: %w", err)
+	}
+
+	return nil
+}
+
+// migrate moves the links in the legacy single file, if there is one, into
+// files of their own and deletes it, the first time the links are used.
+// Links saved there with their tokens, from before only IDs were kept, are
+// given IDs in their place, so the tokens do not linger on disk.
+func (files *shareLinkFiles) migrate() error {
+	files.mutex.Lock()
+	defer files.mutex.Unlock()
+
+	if files.migrated {
+		return nil
+	}
+
+	path := filepath.Join(filepath.Dir(files.directory), legacyShareLinksFile)
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		files.migrated = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading legacy share links: %w", err)
+	}
+
+	data, err := sealer{keyring: files.keyring, binding: legacyShareLinksFile}.open(raw)
+	if err != nil {
+		return fmt.Errorf("opening legacy share links: %w", err)
+	}
+
+	var legacy legacyShareLinks
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return fmt.Errorf("decoding legacy share links: %w", err)
+	}
+
+	for _, link := range legacy.Links {
+		if link.ID == "" {
+			link.ID = words.ShareLinkID(link.Token)
+		}
+		link.Token = ""
+
+		if err := files.write(link); err != nil {
+			return fmt.Errorf("migrating share links: %w", err)
+		}
+	}
+
+	// another instance may have migrated it first
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing legacy share links: %w", err)
+	}
+
+	files.migrated = true
+	return nil
+}
+
+// reseal rewrites every link not sealed with the current key, returning how
+// many it rewrote.
+func (files *shareLinkFiles) reseal() (int, error) {
+	if err := files.migrate(); err != nil {
+		return 0, err
+	}
+
+	entries, err := os.ReadDir(files.directory)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("reading share links: %w", err)
+	}
+
+	var rotated int
+	for _, entry := range entries {
+		linkID, isLink := strings.CutSuffix(entry.Name(), shareLinkFileSuffix)
+		if entry.IsDir() || !isLink {
+			continue
+		}
+
+		resealed, err := reseal(files.file(linkID), files.sealer(linkID))
+		if err != nil {
+			return rotated, err
+		}
+		if resealed {
+			rotated++
+		}
+	}
+
+	return rotated, nil
+}
+
+func (files *shareLinkFiles) file(linkID string) string {
+	return filepath.Join(files.directory, linkID+shareLinkFileSuffix)
+}
+
+func (files *shareLinkFiles) sealer(linkID string) sealer {
+	return sealer{keyring: files.keyring, binding: shareLinksDirectory + "/" + linkID}
+}
+
+// SaveShareLink adds the link, or replaces the one with its ID.
+func (fileStore *FS) SaveShareLink(_ context.Context, link words.ShareLink) error {
+	return fileStore.shareLinks.put(link)
+}
+
+// ShareLinkByID returns the link with the ID, or words.ErrShareLinkNotFound.
+func (fileStore *FS) ShareLinkByID(_ context.Context, linkID string) (words.ShareLink, error) {
+	return fileStore.shareLinks.byID(linkID)
+}
+
+// ShareLinksByGame returns every link to the game.
+func (fileStore *FS) ShareLinksByGame(_ context.Context, gameID string) ([]words.ShareLink, error) {
+	return fileStore.shareLinks.byGame(gameID)
+}
+
+// SaveShareLink adds the link, or replaces the one with its ID.
+func (journal *Journal) SaveShareLink(_ context.Context, link words.ShareLink) error {
+	return journal.shareLinks.put(link)
+}
+
+// ShareLinkByID returns the link with the ID, or words.ErrShareLinkNotFound.
+func (journal *Journal) ShareLinkByID(_ context.Context, linkID string) (words.ShareLink, error) {
+	return journal.shareLinks.byID(linkID)
+}
+
+// ShareLinksByGame returns every link to the game.
+func (journal *Journal) ShareLinksByGame(_ context.Context, gameID string) ([]words.ShareLink, error) {
+	return journal.shareLinks.byGame(gameID)
+}
+
+// SaveShareLink saves the link to the underlying store.
+func (cache *Cache) SaveShareLink(ctx context.Context, link words.ShareLink) error {
+	return cache.store.SaveShareLink(ctx, link)
+}
+
+// ShareLinkByID reads the link from the underlying store; links are not
+// cached.
+func (cache *Cache) ShareLinkByID(ctx context.Context, linkID string) (words.ShareLink, error) {
+	return cache.store.ShareLinkByID(ctx, linkID)
+}
+
+// ShareLinksByGame reads the game's links from the underlying store.
+func (cache *Cache) ShareLinksByGame(ctx context.Context, gameID string) ([]words.ShareLink, error) {
+	return cache.store.ShareLinksByGame(ctx, gameID)
+}
+
+// SaveShareLink adds the link without its token, or replaces the one with
+// its ID, and deletes links that have expired.
+func (sqlStore *SQLite) SaveShareLink(ctx context.Context, link words.ShareLink) error {
+	return sqlStore.inTransaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM share_links WHERE expires_at <= ?`, time.Now().UnixMilli()); err != nil {
+			return fmt.Errorf("deleting expired share links: %w", err)
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO share_links (id, game_id, created_by, created_at, expires_at, revoked)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET revoked = excluded.revoked, expires_at = excluded.expires_at`,
+			link.ID, link.GameID, link.CreatedBy, link.CreatedAt.UnixMilli(), link.ExpiresAt.UnixMilli(), link.Revoked,
+		)
+		if err != nil {
+			return fmt.Errorf("saving share link: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ShareLinkByID returns the link with the ID, or words.ErrShareLinkNotFound.
+func (sqlStore *SQLite) ShareLinkByID(ctx context.Context, linkID string) (words.ShareLink, error) {
+	links, err := sqlStore.queryShareLinks(ctx, `WHERE id = ?`, linkID)
+	if err != nil {
+		return words.ShareLink{}, err
+	}
+
+	if len(links) == 0 {
+		return words.ShareLink{}, words.ErrShareLinkNotFound
+	}
+
+	return links[0], nil
+}
+
+// ShareLinksByGame returns every link to the game.
+func (sqlStore *SQLite) ShareLinksByGame(ctx context.Context, gameID string) ([]words.ShareLink, error) {
+	return sqlStore.queryShareLinks(ctx, `WHERE game_id = ?`, gameID)
+}
+
+func (sqlStore *SQLite) queryShareLinks(ctx context.Context, where string, arguments ...any) ([]words.ShareLink, error) {
+	rows, err := sqlStore.db.QueryContext(ctx, `
+		SELECT id, game_id, created_by, created_at, expires_at, revoked
+		FROM share_links `+where, arguments...)
+	if err != nil {
+		return nil, fmt.Errorf("reading share links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []words.ShareLink
+	for rows.Next() {
+		var (
+			link                 words.ShareLink
+			createdAt, expiresAt int64
+		)
+		if err := rows.Scan(&link.ID, &link.GameID, &link.CreatedBy, &createdAt, &expiresAt, &link.Revoked); err != nil {
+			return nil, fmt.Errorf("reading share link: %w", err)
+		}
+
+		link.CreatedAt = time.UnixMilli(createdAt).UTC()
+		link.ExpiresAt = time.UnixMilli(expiresAt).UTC()
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading share links: %w", err)
+	}
+
+	return links, nil
+}
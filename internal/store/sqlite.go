@@ -0,0 +1,586 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/carterjs/words/internal/words"
+	_ "modernc.org/sqlite" // registers the pure-Go "sqlite" driver
+)
+
+// migrations are applied in order on open; a database records how many it
+// has seen. Append new ones, never edit applied ones.
+var migrations = []string{
+	`CREATE TABLE games (
+		id              TEXT PRIMARY KEY,
+		version         INTEGER NOT NULL,
+		started         INTEGER NOT NULL,
+		finished        INTEGER NOT NULL,
+		round           INTEGER NOT NULL,
+		turn            INTEGER NOT NULL,
+		scoreless_turns INTEGER NOT NULL,
+		pool            TEXT NOT NULL,
+		pool_index      INTEGER NOT NULL,
+		event_sequence  INTEGER NOT NULL,
+		config          TEXT NOT NULL,
+		extras          TEXT NOT NULL,
+		updated_at      INTEGER NOT NULL -- unix milliseconds
+	);
+	CREATE INDEX games_by_activity ON games (finished, updated_at);
+
+	CREATE TABLE players (
+		game_id          TEXT NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+		seat             INTEGER NOT NULL,
+		id               TEXT NOT NULL,
+		name             TEXT NOT NULL,
+		letters          TEXT NOT NULL,
+		score            INTEGER NOT NULL,
+		final_adjustment INTEGER NOT NULL,
+		turns            TEXT NOT NULL,
+		PRIMARY KEY (game_id, seat)
+	);
+	CREATE INDEX players_by_name ON players (name);
+
+	CREATE TABLE placed_words (
+		game_id   TEXT NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+		sequence  INTEGER NOT NULL,
+		col       INTEGER NOT NULL,
+		row       INTEGER NOT NULL,
+		direction TEXT NOT NULL,
+		letters   TEXT NOT NULL,
+		blanks    TEXT NOT NULL,
+		PRIMARY KEY (game_id, sequence)
+	);`,
+	`ALTER TABLE games ADD COLUMN created_at INTEGER NOT NULL DEFAULT 0; -- unix milliseconds
+	UPDATE games SET created_at = updated_at;
+	CREATE INDEX games_by_creation ON games (created_at DESC, id);`,
+	`CREATE TABLE share_links (
+		id         TEXT PRIMARY KEY, -- hash of the token, which is never stored
+		game_id    TEXT NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+		created_by TEXT NOT NULL,
+		created_at INTEGER NOT NULL, -- unix milliseconds
+		expires_at INTEGER NOT NULL, -- unix milliseconds
+		revoked    INTEGER NOT NULL
+	);
+	CREATE INDEX share_links_by_game ON share_links (game_id);`,
+}
+
+// gameExtras holds the parts of a game's state nobody queries, kept as JSON
+// on the game's row.
+type gameExtras struct {
+	StateVersion uint64                     `json:"stateVersion,omitempty"`
+	Spectators   []words.SpectatorState     `json:"spectators,omitempty"`
+	Messages     []words.ChatMessage        `json:"messages,omitempty"`
+	Muted        []string                   `json:"muted,omitempty"`
+	LastWord     *words.LastPlacedWordState `json:"lastWord,omitempty"`
+	Challenge    *words.ChallengeState      `json:"challenge,omitempty"`
+	WinnerIDs    []string                   `json:"winnerIds,omitempty"`
+	Events       []words.LoggedEvent        `json:"events,omitempty"`
+	Moves        []words.Move               `json:"moves,omitempty"`
+	ShuffleSeed  uint64                     `json:"shuffleSeed,omitempty"`
+	Shuffles     uint64                     `json:"shuffles,omitempty"`
+}
+
+// SQLite stores games in normalized tables of an embedded SQLite database,
+// so they can be listed and reported on as well as loaded.
+type SQLite struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the database at the path, creating it if needed, and
+// brings its schema up to date.
+func OpenSQLite(ctx context.Context, path string) (*SQLite, error) {
+	// immediate transactions take the write lock up front, so concurrent saves
+	// queue behind each other instead of failing to upgrade a read lock
+	dsn := "file:" + path + "?_txlock=immediate&_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+
+	sqlStore := &SQLite{db: db}
+	if err := sqlStore.migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return sqlStore, nil
+}
+
+// Close closes the database.
+func (sqlStore *SQLite) Close() error {
+	return sqlStore.db.Close()
+}
+
+// migrate applies the migrations the database has not seen yet, each in its
+// own transaction.
+func (sqlStore *SQLite) migrate(ctx context.Context) error {
+	if _, err := sqlStore.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER NOT NULL)`); err != nil {
+		return fmt.Errorf("creating migrations table: %w", err)
+	}
+
+	var applied int
+	if err := sqlStore.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&applied); err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+
+	for version := applied; version < len(migrations); version++ {
+		err := sqlStore.inTransaction(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, migrations[version]); err != nil {
+				return err
+			}
+
+			_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?)`, version+1)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("applying migration %d: %w", version+1, err)
+		}
+	}
+
+	return nil
+}
+
+// SaveGame writes the game if the stored row is a version behind it. The
+// version check and the write share a transaction, so the swap holds across
+// every instance using the database.
+func (sqlStore *SQLite) SaveGame(ctx context.Context, game *words.Game) error {
+	return sqlStore.save(ctx, game, game.Version()-1, time.Now())
+}
+
+// RestoreGame inserts a game that is not stored yet as it is, at its own
+// version and as last saved at updatedAt, for copying games from another
+// store. A game already stored is refused with words.ErrVersionConflict.
+func (sqlStore *SQLite) RestoreGame(ctx context.Context, game *words.Game, updatedAt time.Time) error {
+	return sqlStore.save(ctx, game, 0, updatedAt)
+}
+
+// save writes the game if the stored row is at the wanted version, dating
+// it updatedAt.
+func (sqlStore *SQLite) save(ctx context.Context, game *words.Game, want uint64, updatedAt time.Time) error {
+	state := game.State()
+
+	config, err := json.Marshal(state.Config)
+	if err != nil {
+		return fmt.Errorf("encoding config: %w", err)
+	}
+
+	extras, err := json.Marshal(gameExtras{
+		StateVersion: state.StateVersion,
+		Spectators:   state.Spectators,
+		Messages:     state.Messages,
+		Muted:        state.Muted,
+		LastWord:     state.LastWord,
+		Challenge:    state.Challenge,
+		WinnerIDs:    state.WinnerIDs,
+		Events:       state.Events,
+		Moves:        state.Moves,
+		ShuffleSeed:  state.ShuffleSeed,
+		Shuffles:     state.Shuffles,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding game extras: %w", err)
+	}
+
+	return sqlStore.inTransaction(ctx, func(tx *sql.Tx) error {
+		var stored uint64
+		err := tx.QueryRowContext(ctx, `SELECT version FROM games WHERE id = ?`, state.ID).Scan(&stored)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("reading stored version: %w", err)
+		}
+
+		if stored != want {
+			return fmt.Errorf("saving version %d over %d: %w", state.Version, stored, words.ErrVersionConflict)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO games (id, version, started, finished, round, turn, scoreless_turns, pool, pool_index, event_sequence, config, extras, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET
+				version = excluded.version,
+				started = excluded.started,
+				finished = excluded.finished,
+				round = excluded.round,
+				turn = excluded.turn,
+				scoreless_turns = excluded.scoreless_turns,
+				pool = excluded.pool,
+				pool_index = excluded.pool_index,
+				event_sequence = excluded.event_sequence,
+				config = excluded.config,
+				extras = excluded.extras,
+				updated_at = excluded.updated_at`,
+			state.ID, state.Version, state.Started, state.Finished, state.Round, state.Turn, state.ScorelessTurns,
+			string(state.Pool), state.PoolIndex, state.EventSequence, string(config), string(extras),
+			state.CreatedAt.UnixMilli(), updatedAt.UnixMilli(),
+		)
+		if err != nil {
+			return fmt.Errorf("writing game: %w", err)
+		}
+
+		if err := writePlayers(ctx, tx, game, state.Players); err != nil {
+			return err
+		}
+
+		return writePlacedWords(ctx, tx, state.ID, state.Words)
+	})
+}
+
+func writePlayers(ctx context.Context, tx *sql.Tx, game *words.Game, players []words.PlayerState) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM players WHERE game_id = ?`, game.ID()); err != nil {
+		return fmt.Errorf("clearing players: %w", err)
+	}
+
+	for seat, player := range players {
+		turns, err := json.Marshal(player.Turns)
+		if err != nil {
+			return fmt.Errorf("encoding turns: %w", err)
+		}
+
+		var score int
+		if current, exists := game.PlayerByID(player.ID); exists {
+			score = current.Score()
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO players (game_id, seat, id, name, letters, score, final_adjustment, turns)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			game.ID(), seat, player.ID, player.Name, string(player.Letters), score, player.FinalAdjustment, string(turns),
+		)
+		if err != nil {
+			return fmt.Errorf("writing player: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func writePlacedWords(ctx context.Context, tx *sql.Tx, gameID string, placed []words.PlacedWordState) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM placed_words WHERE game_id = ?`, gameID); err != nil {
+		return fmt.Errorf("clearing placed words: %w", err)
+	}
+
+	for sequence, word := range placed {
+		blanks, err := json.Marshal(word.Blanks)
+		if err != nil {
+			return fmt.Errorf("encoding blanks: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO placed_words (game_id, sequence, col, row, direction, letters, blanks)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			gameID, sequence, word.Column, word.Row, string(word.Direction), word.Letters, string(blanks),
+		)
+		if err != nil {
+			return fmt.Errorf("writing placed word: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GameByID loads a game from its rows and rebuilds it. A missing game is
+// reported as words.ErrGameNotFound.
+func (sqlStore *SQLite) GameByID(ctx context.Context, gameID string) (*words.Game, error) {
+	var (
+		state          words.GameState
+		pool           string
+		config, extras string
+		createdAt      int64
+	)
+
+	err := sqlStore.db.QueryRowContext(ctx, `
+		SELECT id, version, started, finished, round, turn, scoreless_turns, pool, pool_index, event_sequence, config, extras, created_at
+		FROM games WHERE id = ?`, gameID,
+	).Scan(
+		&state.ID, &state.Version, &state.Started, &state.Finished, &state.Round, &state.Turn, &state.ScorelessTurns,
+		&pool, &state.PoolIndex, &state.EventSequence, &config, &extras, &createdAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, words.ErrGameNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading game: %w", err)
+	}
+
+	state.Pool = []rune(pool)
+	state.CreatedAt = time.UnixMilli(createdAt).UTC()
+
+	if err := json.Unmarshal([]byte(config), &state.Config); err != nil {
+		return nil, fmt.Errorf("decoding config: %w", err)
+	}
+
+	var decoded gameExtras
+	if err := json.Unmarshal([]byte(extras), &decoded); err != nil {
+		return nil, fmt.Errorf("decoding game extras: %w", err)
+	}
+	state.StateVersion = decoded.StateVersion
+	state.Spectators = decoded.Spectators
+	state.Messages = decoded.Messages
+	state.Muted = decoded.Muted
+	state.LastWord = decoded.LastWord
+	state.Challenge = decoded.Challenge
+	state.WinnerIDs = decoded.WinnerIDs
+	state.Events = decoded.Events
+	state.Moves = decoded.Moves
+	state.ShuffleSeed = decoded.ShuffleSeed
+	state.Shuffles = decoded.Shuffles
+
+	if state.Players, err = sqlStore.readPlayers(ctx, gameID); err != nil {
+		return nil, err
+	}
+
+	if state.Words, err = sqlStore.readPlacedWords(ctx, gameID); err != nil {
+		return nil, err
+	}
+
+	game, err := words.NewGameFromState(state)
+	if err != nil {
+		return nil, fmt.Errorf("rebuilding game: %w", err)
+	}
+
+	return game, nil
+}
+
+func (sqlStore *SQLite) readPlayers(ctx context.Context, gameID string) ([]words.PlayerState, error) {
+	rows, err := sqlStore.db.QueryContext(ctx, `
+		SELECT id, name, letters, final_adjustment, turns
+		FROM players WHERE game_id = ? ORDER BY seat`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("reading players: %w", err)
+	}
+	defer rows.Close()
+
+	var players []words.PlayerState
+	for rows.Next() {
+		var (
+			player         words.PlayerState
+			letters, turns string
+		)
+		if err := rows.Scan(&player.ID, &player.Name, &letters, &player.FinalAdjustment, &turns); err != nil {
+			return nil, fmt.Errorf("reading player: %w", err)
+		}
+
+		player.Letters = []rune(letters)
+		if err := json.Unmarshal([]byte(turns), &player.Turns); err != nil {
+			return nil, fmt.Errorf("decoding turns: %w", err)
+		}
+
+		players = append(players, player)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading players: %w", err)
+	}
+
+	return players, nil
+}
+
+func (sqlStore *SQLite) readPlacedWords(ctx context.Context, gameID string) ([]words.PlacedWordState, error) {
+	rows, err := sqlStore.db.QueryContext(ctx, `
+		SELECT col, row, direction, letters, blanks
+		FROM placed_words WHERE game_id = ? ORDER BY sequence`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("reading placed words: %w", err)
+	}
+	defer rows.Close()
+
+	var placed []words.PlacedWordState
+	for rows.Next() {
+		var (
+			word      words.PlacedWordState
+			direction string
+			blanks    string
+		)
+		if err := rows.Scan(&word.Column, &word.Row, &direction, &word.Letters, &blanks); err != nil {
+			return nil, fmt.Errorf("reading placed word: %w", err)
+		}
+
+		word.Direction = words.Direction(direction)
+		if err := json.Unmarshal([]byte(blanks), &word.Blanks); err != nil {
+			return nil, fmt.Errorf("decoding blanks: %w", err)
+		}
+
+		placed = append(placed, word)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading placed words: %w", err)
+	}
+
+	return placed, nil
+}
+
+// ListGames returns a page of outlines of the games matching the query,
+// newest first.
+func (sqlStore *SQLite) ListGames(ctx context.Context, query words.GameQuery) (words.GamePage, error) {
+	var (
+		conditions []string
+		arguments  []any
+	)
+
+	switch query.Status {
+	case "":
+	case words.GameStatusLobby:
+		conditions = append(conditions, `started = 0`)
+	case words.GameStatusRunning:
+		conditions = append(conditions, `started = 1 AND finished = 0`)
+	case words.GameStatusFinished:
+		conditions = append(conditions, `finished = 1`)
+	default:
+		conditions = append(conditions, `FALSE`)
+	}
+
+	if query.PlayerName != "" {
+		conditions = append(conditions, `id IN (SELECT game_id FROM players WHERE name = ?)`)
+		arguments = append(arguments, query.PlayerName)
+	}
+
+	bounds := []struct {
+		condition string
+		moment    time.Time
+	}{
+		{`created_at > ?`, query.CreatedAfter},
+		{`created_at < ?`, query.CreatedBefore},
+		{`updated_at > ?`, query.UpdatedAfter},
+		{`updated_at < ?`, query.UpdatedBefore},
+	}
+	for _, bound := range bounds {
+		if !bound.moment.IsZero() {
+			conditions = append(conditions, bound.condition)
+			arguments = append(arguments, bound.moment.UnixMilli())
+		}
+	}
+
+	if query.After != nil {
+		createdAt := query.After.CreatedAt.UnixMilli()
+		conditions = append(conditions, `(created_at < ? OR (created_at = ? AND id > ?))`)
+		arguments = append(arguments, createdAt, createdAt, query.After.ID)
+	}
+
+	statement := `SELECT id, version, started, finished, round, created_at, updated_at FROM games`
+	if len(conditions) > 0 {
+		statement += ` WHERE ` + strings.Join(conditions, ` AND `)
+	}
+	statement += ` ORDER BY created_at DESC, id`
+	if query.Limit > 0 {
+		// one extra row tells whether another page follows
+		statement += ` LIMIT ?`
+		arguments = append(arguments, query.Limit+1)
+	}
+
+	rows, err := sqlStore.db.QueryContext(ctx, statement, arguments...)
+	if err != nil {
+		return words.GamePage{}, fmt.Errorf("listing games: %w", err)
+	}
+	defer rows.Close()
+
+	page := words.GamePage{Games: []words.GameSummary{}}
+	for rows.Next() {
+		var (
+			summary              words.GameSummary
+			started, finished    bool
+			createdAt, updatedAt int64
+		)
+		if err := rows.Scan(&summary.ID, &summary.Version, &started, &finished, &summary.Round, &createdAt, &updatedAt); err != nil {
+			return words.GamePage{}, fmt.Errorf("reading game summary: %w", err)
+		}
+
+		summary.Status = gameStatus(started, finished)
+		summary.CreatedAt = time.UnixMilli(createdAt).UTC()
+		summary.UpdatedAt = time.UnixMilli(updatedAt).UTC()
+
+		page.Games = append(page.Games, summary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return words.GamePage{}, fmt.Errorf("listing games: %w", err)
+	}
+
+	if query.Limit > 0 && len(page.Games) > query.Limit {
+		page.Games = page.Games[:query.Limit]
+		page.NextCursor = words.CursorAfter(page.Games[query.Limit-1]).String()
+	}
+
+	for index := range page.Games {
+		if page.Games[index].Players, err = sqlStore.playerSummaries(ctx, page.Games[index].ID); err != nil {
+			return words.GamePage{}, err
+		}
+	}
+
+	return page, nil
+}
+
+func gameStatus(started, finished bool) words.GameStatus {
+	switch {
+	case finished:
+		return words.GameStatusFinished
+	case started:
+		return words.GameStatusRunning
+	default:
+		return words.GameStatusLobby
+	}
+}
+
+func (sqlStore *SQLite) playerSummaries(ctx context.Context, gameID string) ([]words.PlayerSummary, error) {
+	rows, err := sqlStore.db.QueryContext(ctx, `SELECT id, name, score FROM players WHERE game_id = ? ORDER BY seat`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("reading player summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var players []words.PlayerSummary
+	for rows.Next() {
+		var player words.PlayerSummary
+		if err := rows.Scan(&player.ID, &player.Name, &player.Score); err != nil {
+			return nil, fmt.Errorf("reading player summary: %w", err)
+		}
+
+		players = append(players, player)
+	}
+
+	return players, rows.Err()
+}
+
+// DeleteGames deletes the games along with their players and words. Games
+// already gone are skipped.
+func (sqlStore *SQLite) DeleteGames(ctx context.Context, gameIDs ...string) error {
+	if len(gameIDs) == 0 {
+		return nil
+	}
+
+	arguments := make([]any, len(gameIDs))
+	for index, gameID := range gameIDs {
+		arguments[index] = gameID
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(gameIDs)), ", ")
+	if _, err := sqlStore.db.ExecContext(ctx, `DELETE FROM games WHERE id IN (`+placeholders+`)`, arguments...); err != nil {
+		return fmt.Errorf("deleting games: %w", err)
+	}
+
+	return nil
+}
+
+func (sqlStore *SQLite) inTransaction(ctx context.Context, work func(tx *sql.Tx) error) error {
+	tx, err := sqlStore.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	if err := work(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package store_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/carterjs/words/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenSQLite(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+	}{
+		{name: "reopens a migrated database with its games intact"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "words.db")
+
+			sqlStore, err := store.OpenSQLite(context.Background(), path)
+			require.NoError(t, err)
+
+			game := newSavableGame(t)
+			require.NoError(t, sqlStore.SaveGame(t.Context(), game))
+			require.NoError(t, sqlStore.Close())
+
+			reopened, err := store.OpenSQLite(context.Background(), path)
+			require.NoError(t, err)
+			defer reopened.Close()
+
+			loaded, err := reopened.GameByID(t.Context(), game.ID())
+			require.NoError(t, err)
+			assert.Equal(t, game.State(), loaded.State())
+		})
+	}
+}
@@ -0,0 +1,486 @@
+package store_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/carterjs/words/internal/crypt"
+	"github.com/carterjs/words/internal/store"
+	"github.com/carterjs/words/internal/words"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contractStores opens an empty instance of every Store implementation, so
+// each contract test runs against all of them.
+var contractStores = map[string]func(t *testing.T) words.Store{
+	"fs": func(t *testing.T) words.Store {
+		return store.NewFS(t.TempDir(), slog.New(slog.DiscardHandler))
+	},
+	"sqlite": func(t *testing.T) words.Store {
+		return openSQLite(t)
+	},
+	"journal": func(t *testing.T) words.Store {
+		return store.NewJournal(t.TempDir())
+	},
+	"encrypted fs": func(t *testing.T) words.Store {
+		return store.NewEncryptedFS(t.TempDir(), newKeyring(t, "current"), slog.New(slog.DiscardHandler))
+	},
+	"cache": func(t *testing.T) words.Store {
+		return store.NewCache(store.NewFS(t.TempDir(), slog.New(slog.DiscardHandler)), 16)
+	},
+}
+
+func TestStore_GameByID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		save    bool
+		wantErr error
+	}{
+		{name: "roundtrips a saved game", save: true},
+		{name: "reports a missing game", wantErr: words.ErrGameNotFound},
+	}
+
+	for _, test := range tests {
+		for backend, open := range contractStores {
+			t.Run(backend+"/"+test.name, func(t *testing.T) {
+				t.Parallel()
+
+				gameStore := open(t)
+
+				game := newSavableGame(t)
+				if test.save {
+					require.NoError(t, gameStore.SaveGame(t.Context(), game))
+				}
+
+				loaded, err := gameStore.GameByID(t.Context(), game.ID())
+
+				if test.wantErr != nil {
+					assert.ErrorIs(t, err, test.wantErr)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, game.State(), loaded.State())
+			})
+		}
+	}
+}
+
+func TestStore_SaveGame(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		storedAt     uint64
+		savedAt      uint64
+		wantConflict bool
+	}{
+		{name: "saves a new game at version 1", savedAt: 1},
+		{name: "saves the next version", storedAt: 1, savedAt: 2},
+		{name: "rejects a stale version", storedAt: 2, savedAt: 2, wantConflict: true},
+		{name: "rejects skipping versions", storedAt: 1, savedAt: 3, wantConflict: true},
+		{name: "rejects a new game over an existing one", storedAt: 1, savedAt: 1, wantConflict: true},
+	}
+
+	for _, test := range tests {
+		for backend, open := range contractStores {
+			t.Run(backend+"/"+test.name, func(t *testing.T) {
+				t.Parallel()
+
+				gameStore := open(t)
+				game := newSavableGame(t)
+
+				for version := uint64(1); version <= test.storedAt; version++ {
+					require.NoError(t, gameStore.SaveGame(t.Context(), withVersion(t, game, version)))
+				}
+
+				err := gameStore.SaveGame(t.Context(), withVersion(t, game, test.savedAt))
+
+				if test.wantConflict {
+					assert.ErrorIs(t, err, words.ErrVersionConflict)
+					return
+				}
+
+				require.NoError(t, err)
+				loaded, err := gameStore.GameByID(t.Context(), game.ID())
+				require.NoError(t, err)
+				assert.Equal(t, test.savedAt, loaded.Version())
+			})
+		}
+	}
+}
+
+func TestStore_RestoreGame(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		storedAt     uint64
+		restoredAt   uint64
+		wantConflict bool
+	}{
+		{name: "restores a game at its version", restoredAt: 5},
+		{name: "restores a game at version 1", restoredAt: 1},
+		{name: "refuses a game already stored", storedAt: 1, restoredAt: 5, wantConflict: true},
+	}
+
+	for _, test := range tests {
+		for backend, open := range contractStores {
+			t.Run(backend+"/"+test.name, func(t *testing.T) {
+				t.Parallel()
+
+				gameStore, ok := open(t).(store.Restorer)
+				if !ok {
+					t.Skip("store cannot restore games")
+				}
+
+				game := newSavableGame(t)
+				for version := uint64(1); version <= test.storedAt; version++ {
+					require.NoError(t, gameStore.SaveGame(t.Context(), withVersion(t, game, version)))
+				}
+
+				updatedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+				err := gameStore.RestoreGame(t.Context(), withVersion(t, game, test.restoredAt), updatedAt)
+
+				if test.wantConflict {
+					assert.ErrorIs(t, err, words.ErrVersionConflict)
+					return
+				}
+
+				require.NoError(t, err)
+
+				loaded, err := gameStore.GameByID(t.Context(), game.ID())
+				require.NoError(t, err)
+				assert.Equal(t, test.restoredAt, loaded.Version())
+
+				page, err := gameStore.ListGames(t.Context(), words.GameQuery{})
+				require.NoError(t, err)
+				require.Len(t, page.Games, 1)
+				assert.True(t, updatedAt.Equal(page.Games[0].UpdatedAt))
+
+				// the restored game saves on from its own version
+				require.NoError(t, gameStore.SaveGame(t.Context(), withVersion(t, game, test.restoredAt+1)))
+			})
+		}
+	}
+}
+
+func TestStore_ListGames(t *testing.T) {
+	t.Parallel()
+
+	hourAgo := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		query     words.GameQuery
+		wantCount int
+		wantPages int
+	}{
+		{name: "lists every game", wantCount: 3, wantPages: 1},
+		{name: "filters lobby games", query: words.GameQuery{Status: words.GameStatusLobby}, wantCount: 1, wantPages: 1},
+		{name: "filters running games", query: words.GameQuery{Status: words.GameStatusRunning}, wantCount: 1, wantPages: 1},
+		{name: "filters finished games", query: words.GameQuery{Status: words.GameStatusFinished}, wantCount: 1, wantPages: 1},
+		{name: "filters by player name", query: words.GameQuery{PlayerName: "player-0"}, wantCount: 2, wantPages: 1},
+		{name: "skips unknown players", query: words.GameQuery{PlayerName: "nobody"}, wantPages: 1},
+		{name: "filters by creation time", query: words.GameQuery{CreatedAfter: hourAgo}, wantCount: 3, wantPages: 1},
+		{name: "filters out games created later", query: words.GameQuery{CreatedBefore: hourAgo}, wantPages: 1},
+		{name: "filters out games updated later", query: words.GameQuery{UpdatedBefore: hourAgo}, wantPages: 1},
+		{name: "pages through games", query: words.GameQuery{Limit: 2}, wantCount: 3, wantPages: 2},
+		{name: "pages through exactly filled pages", query: words.GameQuery{Limit: 1}, wantCount: 3, wantPages: 3},
+	}
+
+	for _, test := range tests {
+		for backend, open := range contractStores {
+			t.Run(backend+"/"+test.name, func(t *testing.T) {
+				t.Parallel()
+
+				gameStore := open(t)
+
+				lobby := words.NewGame(words.Config{RackSize: 3})
+				_, err := lobby.AddPlayer("player-1")
+				require.NoError(t, err)
+
+				for _, game := range []*words.Game{withVersion(t, lobby, 1), newSavableGame(t), newFinishedGame(t)} {
+					require.NoError(t, gameStore.SaveGame(t.Context(), game))
+				}
+
+				query := test.query
+				seen := make(map[string]bool)
+
+				var pages int
+				for {
+					page, err := gameStore.ListGames(t.Context(), query)
+					require.NoError(t, err)
+					pages++
+
+					for _, summary := range page.Games {
+						assert.True(t, query.Matches(summary))
+						assert.False(t, seen[summary.ID], "listed %s twice", summary.ID)
+						seen[summary.ID] = true
+					}
+
+					if page.NextCursor == "" {
+						break
+					}
+
+					cursor, err := words.ParseGameCursor(page.NextCursor)
+					require.NoError(t, err)
+					query.After = &cursor
+				}
+
+				assert.Len(t, seen, test.wantCount)
+				assert.Equal(t, test.wantPages, pages)
+			})
+		}
+	}
+}
+
+func TestStore_DeleteGames(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		saved    int
+		deleted  int
+		unknown  bool
+		wantKept int
+	}{
+		{name: "deletes the games", saved: 3, deleted: 2, wantKept: 1},
+		{name: "skips games already gone", saved: 1, deleted: 1, unknown: true},
+		{name: "does nothing without games", saved: 1, wantKept: 1},
+	}
+
+	for _, test := range tests {
+		for backend, open := range contractStores {
+			t.Run(backend+"/"+test.name, func(t *testing.T) {
+				t.Parallel()
+
+				gameStore, ok := open(t).(interface {
+					words.Store
+					DeleteGames(ctx context.Context, gameIDs ...string) error
+				})
+				if !ok {
+					t.Skip("store cannot delete games")
+				}
+
+				var gameIDs []string
+				for range test.saved {
+					game := newSavableGame(t)
+					require.NoError(t, gameStore.SaveGame(t.Context(), game))
+					require.NoError(t, gameStore.SaveShareLink(t.Context(), newShareLink(game.ID(), time.Hour)))
+					gameIDs = append(gameIDs, game.ID())
+				}
+
+				deleted := gameIDs[:test.deleted]
+				if test.unknown {
+					deleted = append(deleted, "unknown")
+				}
+
+				require.NoError(t, gameStore.DeleteGames(t.Context(), deleted...))
+
+				for _, gameID := range deleted {
+					_, err := gameStore.GameByID(t.Context(), gameID)
+					assert.ErrorIs(t, err, words.ErrGameNotFound)
+
+					links, err := gameStore.ShareLinksByGame(t.Context(), gameID)
+					require.NoError(t, err)
+					assert.Empty(t, links)
+				}
+
+				page, err := gameStore.ListGames(t.Context(), words.GameQuery{})
+				require.NoError(t, err)
+				assert.Len(t, page.Games, test.wantKept)
+			})
+		}
+	}
+}
+
+func TestStore_ShareLinks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		save      func(gameID string) []words.ShareLink
+		linkID    func(saved []words.ShareLink) string
+		wantLink  func(saved []words.ShareLink) words.ShareLink
+		wantLinks int
+		wantErr   error
+	}{
+		{
+			name: "roundtrips a saved link",
+			save: func(gameID string) []words.ShareLink {
+				return []words.ShareLink{newShareLink(gameID, time.Hour), newShareLink(gameID, 2*time.Hour)}
+			},
+			linkID:    func(saved []words.ShareLink) string { return saved[1].ID },
+			wantLink:  func(saved []words.ShareLink) words.ShareLink { return saved[1] },
+			wantLinks: 2,
+		},
+		{
+			name: "replaces a link with the same ID",
+			save: func(gameID string) []words.ShareLink {
+				link := newShareLink(gameID, time.Hour)
+				revoked := link
+				revoked.Revoked = true
+				return []words.ShareLink{link, revoked}
+			},
+			linkID:    func(saved []words.ShareLink) string { return saved[0].ID },
+			wantLink:  func(saved []words.ShareLink) words.ShareLink { return saved[1] },
+			wantLinks: 1,
+		},
+		{
+			name: "drops expired links when another is saved",
+			save: func(gameID string) []words.ShareLink {
+				return []words.ShareLink{newShareLink(gameID, -time.Hour), newShareLink(gameID, time.Hour)}
+			},
+			linkID:    func(saved []words.ShareLink) string { return saved[0].ID },
+			wantLinks: 1,
+			wantErr:   words.ErrShareLinkNotFound,
+		},
+		{
+			name:    "reports a missing link",
+			save:    func(string) []words.ShareLink { return nil },
+			linkID:  func([]words.ShareLink) string { return "missing" },
+			wantErr: words.ErrShareLinkNotFound,
+		},
+		{
+			name:    "reports an ID that is not a link's",
+			save:    func(string) []words.ShareLink { return nil },
+			linkID:  func([]words.ShareLink) string { return "../index" },
+			wantErr: words.ErrShareLinkNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		for backend, open := range contractStores {
+			t.Run(backend+"/"+test.name, func(t *testing.T) {
+				t.Parallel()
+
+				gameStore := open(t)
+
+				game := newSavableGame(t)
+				require.NoError(t, gameStore.SaveGame(t.Context(), game))
+				other := newSavableGame(t)
+				require.NoError(t, gameStore.SaveGame(t.Context(), other))
+				require.NoError(t, gameStore.SaveShareLink(t.Context(), newShareLink(other.ID(), time.Hour)))
+
+				saved := test.save(game.ID())
+				for _, link := range saved {
+					require.NoError(t, gameStore.SaveShareLink(t.Context(), link))
+				}
+
+				links, err := gameStore.ShareLinksByGame(t.Context(), game.ID())
+				require.NoError(t, err)
+				assert.Len(t, links, test.wantLinks)
+
+				link, err := gameStore.ShareLinkByID(t.Context(), test.linkID(saved))
+				if test.wantErr != nil {
+					assert.ErrorIs(t, err, test.wantErr)
+					return
+				}
+				require.NoError(t, err)
+				want := test.wantLink(saved)
+				want.Token = ""
+				assert.Equal(t, want, link, "links are kept without their tokens")
+			})
+		}
+	}
+}
+
+// newShareLink returns a link to the game created now and expiring after
+// the lifetime, with times as precise as every store keeps them.
+func newShareLink(gameID string, lifetime time.Duration) words.ShareLink {
+	createdAt := time.Now().UTC().Truncate(time.Millisecond)
+	token := rand.Text()
+
+	return words.ShareLink{
+		ID:        words.ShareLinkID(token),
+		Token:     token,
+		GameID:    gameID,
+		CreatedBy: "player-0",
+		CreatedAt: createdAt,
+		ExpiresAt: createdAt.Add(lifetime),
+	}
+}
+
+// newSavableGame builds a started game with a word on the board so the
+// roundtrip covers players, racks, and board replay. It is at version 1, as
+// a freshly created game would be.
+func newSavableGame(t testing.TB) *words.Game {
+	t.Helper()
+
+	game := words.NewGame(words.Config{
+		LetterDistribution: map[rune]int{'A': 10},
+		LetterPoints:       map[rune]int{'A': 1},
+		RackSize:           3,
+	})
+
+	_, err := game.AddPlayer("player-0")
+	require.NoError(t, err)
+	require.NoError(t, game.Start())
+
+	word := words.NewWord(words.NewPoint(0, 0), words.DirectionHorizontal, "AA")
+	_, err = game.PlayWord(game.CurrentPlayerID(), word)
+	require.NoError(t, err)
+
+	return withVersion(t, game, 1)
+}
+
+// newFinishedGame is newSavableGame marked as over.
+func newFinishedGame(t testing.TB) *words.Game {
+	t.Helper()
+
+	state := newSavableGame(t).State()
+	state.Finished = true
+
+	game, err := words.NewGameFromState(state)
+	require.NoError(t, err)
+
+	return game
+}
+
+// withVersion returns a copy of the game at the given version.
+func withVersion(t testing.TB, game *words.Game, version uint64) *words.Game {
+	t.Helper()
+
+	state := game.State()
+	state.Version = version
+
+	versioned, err := words.NewGameFromState(state)
+	require.NoError(t, err)
+
+	return versioned
+}
+
+// newKeyring returns a keyring sealing with a key named current, and able
+// to open anything sealed by another newKeyring holding the same keys.
+func newKeyring(t *testing.T, current string, older ...string) *crypt.Keyring {
+	t.Helper()
+
+	keys := map[string][]byte{current: bytes.Repeat([]byte(current[:1]), 32)}
+	for _, keyID := range older {
+		keys[keyID] = bytes.Repeat([]byte(keyID[:1]), 32)
+	}
+
+	keyring, err := crypt.NewKeyring(current, keys)
+	require.NoError(t, err)
+
+	return keyring
+}
+
+func openSQLite(t *testing.T) *store.SQLite {
+	t.Helper()
+
+	sqlStore, err := store.OpenSQLite(context.Background(), filepath.Join(t.TempDir(), "words.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlStore.Close() })
+
+	return sqlStore
+}
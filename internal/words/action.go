@@ -0,0 +1,145 @@
+package words
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ActionType names a kind of change accepted by a game.
+type ActionType string
+
+const (
+	// ActionTypeJoin adds a player.
+	ActionTypeJoin ActionType = "JOIN"
+	// ActionTypeSpectate adds a spectator.
+	ActionTypeSpectate ActionType = "SPECTATE"
+	// ActionTypeLeave removes a spectator.
+	ActionTypeLeave ActionType = "LEAVE"
+	// ActionTypeStart starts the game.
+	ActionTypeStart ActionType = "START"
+	// ActionTypePlayWord places a word.
+	ActionTypePlayWord ActionType = "PLAY_WORD"
+	// ActionTypePass forfeits a turn.
+	ActionTypePass ActionType = "PASS"
+	// ActionTypeExchange swaps letters with the pool.
+	ActionTypeExchange ActionType = "EXCHANGE"
+	// ActionTypeChallenge challenges the last played word.
+	ActionTypeChallenge ActionType = "CHALLENGE"
+	// ActionTypeVote votes on the open challenge.
+	ActionTypeVote ActionType = "VOTE"
+	// ActionTypeSendMessage posts a chat message.
+	ActionTypeSendMessage ActionType = "SEND_MESSAGE"
+	// ActionTypeMute mutes or unmutes a participant.
+	ActionTypeMute ActionType = "MUTE"
+)
+
+// Action is a change a game accepted, with everything needed to take it
+// again: who took it, with what, and any IDs or times it was assigned.
+// PlayerID is whoever acted, whether player, spectator, sender, or host.
+type Action struct {
+	Type          ActionType       `json:"type"`
+	PlayerID      string           `json:"playerId,omitempty"`
+	Name          string           `json:"name,omitempty"`
+	Word          *PlacedWordState `json:"word,omitempty"`
+	Letters       []rune           `json:"letters,omitempty"`
+	Vote          Vote             `json:"vote,omitempty"`
+	Message       *ChatMessage     `json:"message,omitempty"`
+	ParticipantID string           `json:"participantId,omitempty"`
+	Muted         bool             `json:"muted,omitempty"`
+}
+
+// Revision is what a single save changed about a game: the actions it
+// accepted and the events recorded about them, in order.
+type Revision struct {
+	Version uint64        `json:"version"`
+	Actions []Action      `json:"actions,omitempty"`
+	Events  []LoggedEvent `json:"events,omitempty"`
+}
+
+// Revision returns the changes made since the game was loaded, labelled with
+// the version they are being saved as.
+func (game *Game) Revision() Revision {
+	return Revision{
+		Version: game.version,
+		Actions: game.actions,
+		Events:  game.unpublished,
+	}
+}
+
+// Replay takes a revision's actions again and records its events, moving
+// the game to the revision's version. An action the game no longer accepts,
+// or an event out of sequence, is reported as ErrReplayDiverged.
+func (game *Game) Replay(revision Revision) error {
+	if revision.Version != game.version+1 {
+		return fmt.Errorf("replaying version %d onto %d: %w", revision.Version, game.version, ErrReplayDiverged)
+	}
+
+	for index, action := range revision.Actions {
+		if err := game.apply(action); err != nil {
+			return fmt.Errorf("replaying action %d (%s) of version %d: %w: %w", index, action.Type, revision.Version, ErrReplayDiverged, err)
+		}
+	}
+
+	for _, logged := range revision.Events {
+		if logged.Event.ID != game.eventSequence+1 {
+			return fmt.Errorf("replaying event %d after %d: %w", logged.Event.ID, game.eventSequence, ErrReplayDiverged)
+		}
+
+		game.recordEvent(logged.Channel, logged.Event, logged.RecordedAt)
+	}
+
+	game.version = revision.Version
+	if changesState(revision.Actions) {
+		game.stateVersion = revision.Version
+	}
+	game.actions = nil
+	game.unpublished = nil
+
+	return nil
+}
+
+// apply takes a recorded action through the method that first accepted it.
+func (game *Game) apply(action Action) error {
+	var err error
+
+	switch action.Type {
+	case ActionTypeJoin:
+		_, err = game.addPlayer(action.PlayerID, action.Name)
+	case ActionTypeSpectate:
+		game.addSpectator(action.PlayerID, action.Name)
+	case ActionTypeLeave:
+		err = game.RemoveSpectator(action.PlayerID)
+	case ActionTypeStart:
+		err = game.Start()
+	case ActionTypePlayWord:
+		if action.Word == nil {
+			return errors.New("word missing")
+		}
+		_, err = game.PlayWord(action.PlayerID, action.Word.word())
+	case ActionTypePass:
+		err = game.PassTurn(action.PlayerID)
+	case ActionTypeExchange:
+		err = game.ExchangeLetters(action.PlayerID, action.Letters)
+	case ActionTypeChallenge:
+		_, err = game.Challenge(action.PlayerID)
+	case ActionTypeVote:
+		_, err = game.CastVote(action.PlayerID, action.Vote)
+	case ActionTypeSendMessage:
+		if action.Message == nil {
+			return errors.New("message missing")
+		}
+		message := action.Message
+		_, err = game.addMessage(message.ID, message.SenderID, message.RecipientID, message.Text, message.SentAt)
+	case ActionTypeMute:
+		err = game.SetMuted(action.PlayerID, action.ParticipantID, action.Muted)
+	default:
+		return fmt.Errorf("unknown action type %q", action.Type)
+	}
+
+	return err
+}
+
+// recordAction appends an accepted action to those awaiting a save.
+func (game *Game) recordAction(action Action) {
+	game.actions = append(game.actions, action)
+}
@@ -0,0 +1,83 @@
+package words_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/carterjs/words/internal/words"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGame_Replay(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		version uint64
+		tamper  func(revision *words.Revision)
+		wantErr error
+	}{
+		{name: "reproduces the recorded game", version: 1},
+		{name: "rejects a revision that skips a version", version: 2, wantErr: words.ErrReplayDiverged},
+		{
+			name:    "rejects an action the game no longer accepts",
+			version: 1,
+			tamper: func(revision *words.Revision) {
+				revision.Actions = append(revision.Actions, words.Action{Type: words.ActionTypeStart})
+			},
+			wantErr: words.ErrReplayDiverged,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			game := words.NewGame(testConfig(map[rune]int{'A': 10, 'B': 10}, 3))
+
+			// copy the state as a store would, so the game's later exchanges
+			// cannot reach into it
+			encoded, err := json.Marshal(game.State())
+			require.NoError(t, err)
+			var initial words.GameState
+			require.NoError(t, json.Unmarshal(encoded, &initial))
+
+			_, err = game.AddPlayer("player-0")
+			require.NoError(t, err)
+			_, err = game.AddPlayer("player-1")
+			require.NoError(t, err)
+			spectator, err := game.AddSpectator("watcher")
+			require.NoError(t, err)
+			require.NoError(t, game.RemoveSpectator(spectator.ID()))
+			require.NoError(t, game.Start())
+
+			player, _ := game.PlayerByID(game.CurrentPlayerID())
+			require.NoError(t, game.ExchangeLetters(player.ID(), player.Letters()[:2]))
+			require.NoError(t, game.PassTurn(game.CurrentPlayerID()))
+
+			revision := game.Revision()
+			revision.Version = test.version
+			if test.tamper != nil {
+				test.tamper(&revision)
+			}
+
+			replayed, err := words.NewGameFromState(initial)
+			require.NoError(t, err)
+
+			err = replayed.Replay(revision)
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			want := game.State()
+			want.Version = test.version
+			want.StateVersion = test.version
+			assert.Equal(t, want, replayed.State())
+		})
+	}
+}
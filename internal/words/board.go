@@ -38,6 +38,12 @@ func (board *Board) Letter(point Point) (rune, bool) {
 	return letter, exists
 }
 
+// Blank reports whether the letter at the given point was played from a blank.
+func (board *Board) Blank(point Point) bool {
+	_, isBlank := board.blanks[point]
+	return isBlank
+}
+
 // PlaceholderLetter marks a position in a typed word that must be filled by
 // a letter already on the board.
 const PlaceholderLetter = '*'
@@ -0,0 +1,224 @@
+package words
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// chatHistoryLimit bounds how many messages a game keeps, so late joiners
+	// get a backlog without snapshots growing forever.
+	chatHistoryLimit = 100
+	// maxMessageLength bounds a single message, in characters.
+	maxMessageLength = 500
+)
+
+// ChatMessage is a message sent in a game's chat. A message with a
+// RecipientID is a whisper, visible only to its sender and recipient.
+type ChatMessage struct {
+	ID          string    `json:"id"`
+	SenderID    string    `json:"senderId"`
+	SenderName  string    `json:"senderName"`
+	RecipientID string    `json:"recipientId,omitempty"`
+	Text        string    `json:"text"`
+	SentAt      time.Time `json:"sentAt"`
+}
+
+// Whisper reports whether the message is private to its sender and recipient.
+func (message ChatMessage) Whisper() bool {
+	return message.RecipientID != ""
+}
+
+// HostID returns the ID of the player who joined first, who moderates the
+// game's chat, or the empty string if nobody has joined.
+func (game *Game) HostID() string {
+	if len(game.players) == 0 {
+		return ""
+	}
+
+	return game.players[0].id
+}
+
+// AddMessage records a chat message from a player or spectator. Whispers may
+// only be sent to players, since only players have a private channel.
+func (game *Game) AddMessage(senderID, recipientID, text string, sentAt time.Time) (ChatMessage, error) {
+	return game.addMessage(uuid.NewString(), senderID, recipientID, text, sentAt)
+}
+
+func (game *Game) addMessage(messageID, senderID, recipientID, text string, sentAt time.Time) (ChatMessage, error) {
+	senderName, isParticipant := game.participantName(senderID)
+	if !isParticipant {
+		return ChatMessage{}, ErrNotParticipant
+	}
+
+	if game.Muted(senderID) {
+		return ChatMessage{}, ErrMuted
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ChatMessage{}, ErrEmptyMessage
+	}
+
+	if len([]rune(text)) > maxMessageLength {
+		return ChatMessage{}, ErrMessageTooLong
+	}
+
+	if recipientID != "" && game.playerIndex(recipientID) < 0 {
+		return ChatMessage{}, ErrPlayerNotFound
+	}
+
+	message := ChatMessage{
+		ID:          messageID,
+		SenderID:    senderID,
+		SenderName:  senderName,
+		RecipientID: recipientID,
+		Text:        text,
+		SentAt:      sentAt,
+	}
+
+	game.messages = append(game.messages, message)
+	if overflow := len(game.messages) - chatHistoryLimit; overflow > 0 {
+		game.messages = game.messages[overflow:]
+	}
+
+	game.recordAction(Action{Type: ActionTypeSendMessage, PlayerID: senderID, Message: &message})
+
+	return message, nil
+}
+
+// MessagesFor returns the chat history visible to the given participant:
+// every public message plus the whispers they sent or received.
+func (game *Game) MessagesFor(participantID string) []ChatMessage {
+	messages := []ChatMessage{}
+	for _, message := range game.messages {
+		if !message.Whisper() || message.SenderID == participantID || message.RecipientID == participantID {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages
+}
+
+// SetMuted mutes or unmutes a participant's chat. Only the host may do so.
+func (game *Game) SetMuted(hostID, participantID string, muted bool) error {
+	if hostID == "" || hostID != game.HostID() {
+		return ErrNotHost
+	}
+
+	if _, isParticipant := game.participantName(participantID); !isParticipant {
+		return ErrNotParticipant
+	}
+
+	if muted {
+		if game.muted == nil {
+			game.muted = make(map[string]struct{})
+		}
+		game.muted[participantID] = struct{}{}
+	} else {
+		delete(game.muted, participantID)
+	}
+
+	game.recordAction(Action{Type: ActionTypeMute, PlayerID: hostID, ParticipantID: participantID, Muted: muted})
+
+	return nil
+}
+
+// Muted reports whether the host has muted the participant.
+func (game *Game) Muted(participantID string) bool {
+	_, muted := game.muted[participantID]
+	return muted
+}
+
+func (game *Game) participantName(participantID string) (string, bool) {
+	if player, isPlayer := game.PlayerByID(participantID); isPlayer {
+		return player.name, true
+	}
+
+	if spectator, isSpectator := game.SpectatorByID(participantID); isSpectator {
+		return spectator.name, true
+	}
+
+	return "", false
+}
+
+const (
+	// chatRateLimit is how many messages one participant may send per
+	// chatRateWindow.
+	chatRateLimit  = 5
+	chatRateWindow = 10 * time.Second
+)
+
+// chatLimiter enforces a sliding-window message rate per participant. It is
+// in-process only: a participant spread across several servers gets the
+// limit on each.
+type chatLimiter struct {
+	mutex sync.Mutex
+	sent  map[string][]time.Time
+	// sweptAt is when every key was last pruned, so senders who stop
+	// chatting are forgotten without walking the whole map on each message.
+	sweptAt time.Time
+}
+
+func newChatLimiter() *chatLimiter {
+	return &chatLimiter{
+		sent: make(map[string][]time.Time),
+	}
+}
+
+// allow reports whether the key may send another message at the given time.
+// It does not count the message; record does once the message is accepted.
+func (limiter *chatLimiter) allow(key string, now time.Time) bool {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	limiter.sweep(now)
+
+	return len(limiter.prune(key, now)) < chatRateLimit
+}
+
+// record counts a message the key sent at the given time.
+func (limiter *chatLimiter) record(key string, now time.Time) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	limiter.sent[key] = append(limiter.prune(key, now), now)
+}
+
+// prune drops the key's messages that have left the window and returns the
+// rest.
+func (limiter *chatLimiter) prune(key string, now time.Time) []time.Time {
+	cutoff := now.Add(-chatRateWindow)
+
+	times := limiter.sent[key]
+	recent := times[:0]
+	for _, sentAt := range times {
+		if sentAt.After(cutoff) {
+			recent = append(recent, sentAt)
+		}
+	}
+
+	if len(recent) == 0 {
+		delete(limiter.sent, key)
+		return nil
+	}
+
+	limiter.sent[key] = recent
+	return recent
+}
+
+// sweep prunes every key, at most once per window.
+func (limiter *chatLimiter) sweep(now time.Time) {
+	if now.Sub(limiter.sweptAt) < chatRateWindow {
+		return
+	}
+
+	for key := range limiter.sent {
+		limiter.prune(key, now)
+	}
+
+	limiter.sweptAt = now
+}
@@ -0,0 +1,109 @@
+package words_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carterjs/words/internal/words"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGame_AddMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		sender      string
+		recipient   string
+		text        string
+		mute        bool
+		wantErr     error
+		wantVisible map[string]bool
+	}{
+		{name: "shows public messages to everyone", sender: "player-0", text: "hi", wantVisible: map[string]bool{"player-0": true, "player-1": true, "spectator": true}},
+		{name: "lets spectators chat", sender: "spectator", text: "gg", wantVisible: map[string]bool{"player-0": true, "player-1": true, "spectator": true}},
+		{name: "shows whispers only to both ends", sender: "spectator", recipient: "player-1", text: "psst", wantVisible: map[string]bool{"player-0": false, "player-1": true, "spectator": true}},
+		{name: "rejects outsiders", sender: "outsider", text: "hi", wantErr: words.ErrNotParticipant},
+		{name: "rejects whispers to spectators", sender: "player-0", recipient: "spectator", text: "hi", wantErr: words.ErrPlayerNotFound},
+		{name: "rejects blank messages", sender: "player-0", text: "   ", wantErr: words.ErrEmptyMessage},
+		{name: "rejects long messages", sender: "player-0", text: strings.Repeat("a", 501), wantErr: words.ErrMessageTooLong},
+		{name: "rejects muted participants", sender: "player-1", text: "hi", mute: true, wantErr: words.ErrMuted},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			game := newLobbyGame(t, 2, testConfig(map[rune]int{'A': 20}, 3))
+			spectator, err := game.AddSpectator("spectator")
+			require.NoError(t, err)
+
+			ids := map[string]string{
+				"player-0":  game.Players()[0].ID(),
+				"player-1":  game.Players()[1].ID(),
+				"spectator": spectator.ID(),
+				"outsider":  "outsider",
+			}
+
+			if test.mute {
+				require.NoError(t, game.SetMuted(game.HostID(), ids[test.sender], true))
+			}
+
+			message, err := game.AddMessage(ids[test.sender], ids[test.recipient], test.text, time.Now())
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.sender, message.SenderName)
+			for viewer, wantVisible := range test.wantVisible {
+				assert.Equal(t, wantVisible, len(game.MessagesFor(ids[viewer])) == 1, viewer)
+			}
+		})
+	}
+}
+
+func TestGame_SetMuted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		byHost  bool
+		wantErr error
+	}{
+		{name: "lets the host mute", byHost: true},
+		{name: "rejects anyone else", wantErr: words.ErrNotHost},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			game := newLobbyGame(t, 2, testConfig(map[rune]int{'A': 20}, 3))
+			moderatorID := game.Players()[1].ID()
+			if test.byHost {
+				moderatorID = game.HostID()
+			}
+
+			targetID := game.Players()[1].ID()
+			err := game.SetMuted(moderatorID, targetID, true)
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				assert.False(t, game.Muted(targetID))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, game.Muted(targetID))
+
+			rebuilt, err := words.NewGameFromState(game.State())
+			require.NoError(t, err)
+			assert.True(t, rebuilt.Muted(targetID))
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package words
+
+import (
+	"maps"
+	"slices"
+)
+
+// Clone returns a deep copy of the game as a store would load it: changing
+// either copy leaves the other untouched, and the copy carries none of the
+// actions or events awaiting the original's next save. Configuration and
+// placed words are shared, since neither is ever modified in place.
+func (game *Game) Clone() *Game {
+	clone := *game
+
+	clone.pool = slices.Clone(game.pool)
+	clone.players = slices.Clone(game.players)
+	for index := range clone.players {
+		clone.players[index].letters = slices.Clone(game.players[index].letters)
+		clone.players[index].turns = slices.Clone(game.players[index].turns)
+	}
+	clone.spectators = slices.Clone(game.spectators)
+	clone.messages = slices.Clone(game.messages)
+	clone.muted = maps.Clone(game.muted)
+	clone.board = game.board.clone()
+	clone.winnerIDs = slices.Clone(game.winnerIDs)
+	clone.eventLog = slices.Clone(game.eventLog)
+	clone.moves = slices.Clone(game.moves)
+
+	if game.lastWord != nil {
+		lastWord := *game.lastWord
+		clone.lastWord = &lastWord
+	}
+
+	if game.challenge != nil {
+		clone.challenge = &challengeRecord{
+			challengerID: game.challenge.challengerID,
+			votes:        maps.Clone(game.challenge.votes),
+		}
+	}
+
+	clone.unpublished = nil
+	clone.actions = nil
+
+	return &clone
+}
+
+func (board *Board) clone() *Board {
+	return &Board{
+		grid:   maps.Clone(board.grid),
+		blanks: maps.Clone(board.blanks),
+		words:  slices.Clone(board.words),
+		bounds: board.bounds,
+		config: board.config,
+	}
+}
@@ -2,17 +2,23 @@ package words
 
 import (
 	"math/rand"
+	"time"
 
 	"github.com/carterjs/words/internal/pattern"
 )
 
 // Config describes the rules a game is played with: the letters available,
 // their point values, the rack size, and the board's modifier layout.
+// SpectatorDelay holds public events back from spectators by that long. It
+// only applies to event streams: reads of the game and its board always show
+// the current state, so the delay keeps spectators from relaying moves as
+// they happen rather than hiding the game from anyone determined to look.
 type Config struct {
 	LetterDistribution map[rune]int            `json:"letterDistribution"`
 	LetterPoints       map[rune]int            `json:"letterPoints"`
 	RackSize           int                     `json:"rackSize"`
 	Modifiers          pattern.Group[Modifier] `json:"modifiers"`
+	SpectatorDelay     time.Duration           `json:"spectatorDelay,omitempty"`
 }
 
 // ConfigOverrides carries per-game adjustments applied on top of a preset.
@@ -20,6 +26,7 @@ type ConfigOverrides struct {
 	RackSize           int
 	LetterDistribution map[rune]int
 	LetterPoints       map[rune]int
+	SpectatorDelay     time.Duration
 }
 
 func configWithOverrides(config Config, overrides ConfigOverrides) Config {
@@ -30,6 +37,10 @@ func configWithOverrides(config Config, overrides ConfigOverrides) Config {
 		config.RackSize = overrides.RackSize
 	}
 
+	if overrides.SpectatorDelay > 0 {
+		config.SpectatorDelay = overrides.SpectatorDelay
+	}
+
 	return config
 }
 
@@ -53,6 +53,61 @@ var (
 	ErrCannotVoteOnOwnWord = errors.New("cannot vote on your own word")
 	// ErrInvalidVote reports a vote value that is neither valid nor invalid.
 	ErrInvalidVote = errors.New("invalid vote")
+	// ErrInvalidName reports a player or spectator name that is blank, too
+	// long, or holds control characters.
+	ErrInvalidName = errors.New("invalid name")
+	// ErrTooManySpectators reports a game that already has as many
+	// spectators as it allows.
+	ErrTooManySpectators = errors.New("too many spectators")
+	// ErrNotParticipant reports someone who is neither a player nor a spectator.
+	ErrNotParticipant = errors.New("not a player or spectator in this game")
+	// ErrNotHost reports a moderation action by someone other than the host.
+	ErrNotHost = errors.New("only the host can do that")
+	// ErrMuted reports a chat message from a muted participant.
+	ErrMuted = errors.New("participant is muted")
+	// ErrEmptyMessage reports a chat message with no text.
+	ErrEmptyMessage = errors.New("message is empty")
+	// ErrMessageTooLong reports a chat message over the length limit.
+	ErrMessageTooLong = errors.New("message is too long")
+	// ErrRateLimited reports a participant sending messages too quickly.
+	ErrRateLimited = errors.New("sending messages too quickly")
+	// ErrVersionConflict reports a save racing another save of the same game:
+	// the stored copy is no longer the version the game was loaded at.
+	ErrVersionConflict = errors.New("game was modified concurrently")
+	// ErrVersionMismatch reports a game that is no longer at the version the
+	// caller expected.
+	ErrVersionMismatch = errors.New("game is not at the expected version")
+	// ErrReplayDiverged reports a game's action log that no longer replays to
+	// the state it was recorded from.
+	ErrReplayDiverged = errors.New("replayed game diverged from its log")
+	// ErrInvalidCursor reports a listing cursor that was not issued by a
+	// previous page.
+	ErrInvalidCursor = errors.New("invalid listing cursor")
+	// ErrUnknownSchema reports a snapshot written in a schema newer than this
+	// build understands.
+	ErrUnknownSchema = errors.New("unknown game state schema")
+	// ErrUnknownFormat reports an export or import in a format this build
+	// does not write.
+	ErrUnknownFormat = errors.New("unknown transfer format")
+	// ErrMalformedTransfer reports an import that cannot be read as the
+	// format it claims to be in.
+	ErrMalformedTransfer = errors.New("malformed transfer")
+	// ErrInconsistentGame reports a game that breaks one of its invariants;
+	// the error is an IntegrityError listing them.
+	ErrInconsistentGame = errors.New("game is inconsistent")
+	// ErrGameNotFinished reports an action that waits for the game to end.
+	ErrGameNotFinished = errors.New("game is not finished")
+	// ErrNoMoveHistory reports a game played before moves were recorded.
+	ErrNoMoveHistory = errors.New("game has no move history")
+	// ErrMalformedNotation reports GCG notation that cannot be read, or
+	// whose moves the game will not replay.
+	ErrMalformedNotation = errors.New("malformed game notation")
+	// ErrShareLinkNotFound reports a share token that does not exist, or no
+	// longer grants access because it expired or was revoked.
+	ErrShareLinkNotFound = errors.New("share link not found")
+	// ErrInvalidShareLinkLifetime reports a share link asked to last a
+	// negative time or longer than MaxShareLinkLifetime.
+	ErrInvalidShareLinkLifetime = errors.New("invalid share link lifetime")
 )
 
 // WordConflictError reports a placement that disagrees with a letter already
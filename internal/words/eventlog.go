@@ -0,0 +1,129 @@
+package words
+
+import (
+	"context"
+	"time"
+)
+
+// eventLogLimit bounds how many events a game keeps for subscribers resuming
+// after a disconnect. Anyone further behind has to reload the game instead.
+const eventLogLimit = 256
+
+// LoggedEvent is an event as recorded in a game's log, with the channel it
+// was published on so replays can respect private channels.
+type LoggedEvent struct {
+	Channel    string    `json:"channel"`
+	Event      Event     `json:"event"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// LastEventID returns the ID of the most recently recorded event, or zero if
+// the game has recorded none.
+func (game *Game) LastEventID() uint64 {
+	return game.eventSequence
+}
+
+// EventsAfter returns the logged events with IDs above lastEventID published
+// on any of the given channels, oldest first.
+func (game *Game) EventsAfter(lastEventID uint64, channels ...string) []LoggedEvent {
+	var events []LoggedEvent
+	for _, logged := range game.eventLog {
+		if logged.Event.ID <= lastEventID {
+			continue
+		}
+
+		for _, channel := range channels {
+			if logged.Channel == channel {
+				events = append(events, logged)
+				break
+			}
+		}
+	}
+
+	return events
+}
+
+// eventLogCovers reports whether the log still holds every event after
+// lastEventID. An ID beyond the last recorded event was not issued by this
+// game as it stands, say after a restore or import, so nothing covers it.
+func (game *Game) eventLogCovers(lastEventID uint64) bool {
+	if lastEventID > game.eventSequence {
+		return false
+	}
+
+	if len(game.eventLog) == 0 {
+		return lastEventID == game.eventSequence
+	}
+
+	return game.eventLog[0].Event.ID <= lastEventID+1
+}
+
+// recordEvent assigns the event the game's next sequence number and appends
+// it to the log and to the events awaiting publication.
+func (game *Game) recordEvent(channel string, event Event, recordedAt time.Time) {
+	game.eventSequence++
+	event.ID = game.eventSequence
+
+	logged := LoggedEvent{Channel: channel, Event: event, RecordedAt: recordedAt}
+
+	game.eventLog = append(game.eventLog, logged)
+	if overflow := len(game.eventLog) - eventLogLimit; overflow > 0 {
+		game.eventLog = game.eventLog[overflow:]
+	}
+
+	game.unpublished = append(game.unpublished, logged)
+}
+
+// takeUnpublished returns the events recorded since the last call and
+// forgets them.
+func (game *Game) takeUnpublished() []LoggedEvent {
+	unpublished := game.unpublished
+	game.unpublished = nil
+	return unpublished
+}
+
+// replayingSubscription yields a backlog of logged events before switching to
+// a live subscription, skipping live events the backlog already covered.
+type replayingSubscription struct {
+	subscription Subscription
+	backlog      []LoggedEvent
+	lastEventID  uint64
+}
+
+func newReplayingSubscription(subscription Subscription, backlog []LoggedEvent, lastEventID uint64) *replayingSubscription {
+	return &replayingSubscription{
+		subscription: subscription,
+		backlog:      backlog,
+		lastEventID:  lastEventID,
+	}
+}
+
+// Next returns the next backlog event, then live events newer than anything
+// already delivered.
+func (replaying *replayingSubscription) Next(ctx context.Context) (Event, error) {
+	if len(replaying.backlog) > 0 {
+		event := replaying.backlog[0].Event
+		replaying.backlog = replaying.backlog[1:]
+		replaying.lastEventID = max(replaying.lastEventID, event.ID)
+		return event, nil
+	}
+
+	for {
+		event, err := replaying.subscription.Next(ctx)
+		if err != nil {
+			return Event{}, err
+		}
+
+		// events without an ID are not logged and cannot be duplicates
+		if event.ID != 0 && event.ID <= replaying.lastEventID {
+			continue
+		}
+
+		return event, nil
+	}
+}
+
+// Close ends the live subscription.
+func (replaying *replayingSubscription) Close() {
+	replaying.subscription.Close()
+}
@@ -1,6 +1,9 @@
 package words
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 // EventType names a kind of game event delivered to subscribers.
 type EventType string
@@ -8,6 +11,10 @@ type EventType string
 const (
 	// EventTypePlayerJoined announces a new player in the game.
 	EventTypePlayerJoined EventType = "PLAYER_JOINED"
+	// EventTypeSpectatorJoined announces a new spectator watching the game.
+	EventTypeSpectatorJoined EventType = "SPECTATOR_JOINED"
+	// EventTypeSpectatorLeft announces a spectator who stopped watching.
+	EventTypeSpectatorLeft EventType = "SPECTATOR_LEFT"
 	// EventTypeGameStarted announces that the game has started.
 	EventTypeGameStarted EventType = "GAME_STARTED"
 	// EventTypeWordPlayed announces a word placed on the board.
@@ -26,11 +33,21 @@ const (
 	EventTypeChallengeResolved EventType = "CHALLENGE_RESOLVED"
 	// EventTypeGameEnded announces the end of the game and final scores.
 	EventTypeGameEnded EventType = "GAME_ENDED"
+	// EventTypeChatMessage carries a chat message: public ones on the game
+	// channel, whispers on the sender's and recipient's private channels.
+	EventTypeChatMessage EventType = "CHAT_MESSAGE"
+	// EventTypeParticipantMuted announces the host muting or unmuting someone.
+	EventTypeParticipantMuted EventType = "PARTICIPANT_MUTED"
+	// EventTypeResyncRequired tells a subscriber it missed events and must
+	// reload the game. It carries no payload and no ID.
+	EventTypeResyncRequired EventType = "RESYNC_REQUIRED"
 )
 
 // Event is a notification about a change to a game. Payload is the JSON
-// encoding of the event's payload type.
+// encoding of the event's payload type. ID increases monotonically across
+// all of a game's channels, so a subscriber can tell what it missed.
 type Event struct {
+	ID      uint64          `json:"id,omitempty"`
 	Type    EventType       `json:"type"`
 	Payload json.RawMessage `json:"payload"`
 }
@@ -41,6 +58,17 @@ type PlayerJoinedPayload struct {
 	PlayerName string `json:"playerName"`
 }
 
+// SpectatorJoinedPayload is the payload of EventTypeSpectatorJoined.
+type SpectatorJoinedPayload struct {
+	SpectatorID   string `json:"spectatorId"`
+	SpectatorName string `json:"spectatorName"`
+}
+
+// SpectatorLeftPayload is the payload of EventTypeSpectatorLeft.
+type SpectatorLeftPayload struct {
+	SpectatorID string `json:"spectatorId"`
+}
+
 // GameStartedPayload is the payload of EventTypeGameStarted. Letters is only
 // populated on a player's private channel.
 type GameStartedPayload struct {
@@ -114,3 +142,19 @@ type GameEndedPayload struct {
 	WinnerIDs []string       `json:"winnerIds"`
 	Scores    map[string]int `json:"scores"`
 }
+
+// ChatMessagePayload is the payload of EventTypeChatMessage.
+type ChatMessagePayload struct {
+	ID          string    `json:"id"`
+	SenderID    string    `json:"senderId"`
+	SenderName  string    `json:"senderName"`
+	RecipientID string    `json:"recipientId,omitempty"`
+	Text        string    `json:"text"`
+	SentAt      time.Time `json:"sentAt"`
+}
+
+// ParticipantMutedPayload is the payload of EventTypeParticipantMuted.
+type ParticipantMutedPayload struct {
+	ParticipantID string `json:"participantId"`
+	Muted         bool   `json:"muted"`
+}
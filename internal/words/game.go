@@ -3,8 +3,9 @@ package words
 import (
 	"fmt"
 	"math"
-	"math/rand"
+	"math/rand/v2"
 	"sort"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -17,6 +18,9 @@ const scorelessRoundLimit = 2
 // and the turn and challenge state around them.
 type Game struct {
 	id             string
+	version        uint64
+	stateVersion   uint64
+	createdAt      time.Time
 	started        bool
 	finished       bool
 	round          int
@@ -24,12 +28,30 @@ type Game struct {
 	pool           []rune
 	poolIndex      int
 	players        []Player
+	spectators     []Spectator
+	messages       []ChatMessage
+	muted          map[string]struct{}
 	turn           int
 	scorelessTurns int
 	board          *Board
 	lastWord       *lastWordRecord
 	challenge      *challengeRecord
 	winnerIDs      []string
+	eventSequence  uint64
+	eventLog       []LoggedEvent
+	moves          []Move
+
+	// shuffleSeed and shuffles make every reshuffle of the pool reproducible,
+	// so replaying the game's actions deals the same letters.
+	shuffleSeed uint64
+	shuffles    uint64
+
+	// unpublished holds events recorded since the game was loaded; it is
+	// never persisted.
+	unpublished []LoggedEvent
+	// actions holds the actions taken since the game was loaded; it is never
+	// persisted as part of the snapshot.
+	actions []Action
 }
 
 // lastWordRecord tracks the most recently played word. A word is settled —
@@ -43,11 +65,13 @@ type lastWordRecord struct {
 // NewGame returns a new unstarted game with the given configuration.
 func NewGame(config Config) *Game {
 	return &Game{
-		id:     uuid.NewString(),
-		round:  1,
-		config: config,
-		pool:   initialLetterPool(config),
-		board:  NewBoard(config),
+		id:          uuid.NewString(),
+		createdAt:   time.Now().UTC().Truncate(time.Millisecond),
+		round:       1,
+		config:      config,
+		pool:        initialLetterPool(config),
+		board:       NewBoard(config),
+		shuffleSeed: rand.Uint64(),
 	}
 }
 
@@ -122,14 +146,26 @@ func (game *Game) LettersRemaining() int {
 	return len(game.pool) - game.poolIndex
 }
 
-// AddPlayer adds a player to an unstarted game and returns them.
+// AddPlayer adds a player to an unstarted game and returns them. Names that
+// fail validation are reported as ErrInvalidName.
 func (game *Game) AddPlayer(name string) (Player, error) {
+	if err := validateName(name); err != nil {
+		return Player{}, err
+	}
+
+	return game.addPlayer(uuid.NewString(), name)
+}
+
+func (game *Game) addPlayer(playerID, name string) (Player, error) {
 	if game.started {
 		return Player{}, ErrGameStarted
 	}
 
-	player := newPlayer(name)
+	player := Player{id: playerID, name: name}
 	game.players = append(game.players, player)
+
+	game.recordAction(Action{Type: ActionTypeJoin, PlayerID: playerID, Name: name})
+
 	return player, nil
 }
 
@@ -149,6 +185,8 @@ func (game *Game) Start() error {
 
 	game.started = true
 
+	game.recordAction(Action{Type: ActionTypeStart})
+
 	return nil
 }
 
@@ -171,6 +209,13 @@ func (game *Game) PlayWord(playerID string, word Word) (PlacementResult, error)
 	}
 
 	player := &game.players[game.playerIndex(playerID)]
+	game.recordMove(Move{
+		Type:     MoveTypePlay,
+		PlayerID: playerID,
+		Rack:     player.letters,
+		Word:     newPlacedWordState(result.DirectWord),
+		Points:   result.Points,
+	})
 	player.takeLetters(lettersFromMap(result.LettersUsed))
 
 	drawn := game.fillPlayerRack(player)
@@ -184,6 +229,8 @@ func (game *Game) PlayWord(playerID string, word Word) (PlacementResult, error)
 	game.lastWord = &lastWordRecord{playerID: playerID}
 	game.scorelessTurns = 0
 
+	game.recordAction(Action{Type: ActionTypePlayWord, PlayerID: playerID, Word: newPlacedWordState(word)})
+
 	if game.LettersRemaining() == 0 && len(player.letters) == 0 {
 		game.finish(playerID)
 		return result, nil
@@ -201,9 +248,13 @@ func (game *Game) PassTurn(playerID string) error {
 		return fmt.Errorf("checking turn: %w", err)
 	}
 
+	game.recordMove(Move{Type: MoveTypePass, PlayerID: playerID, Rack: game.players[game.playerIndex(playerID)].letters})
+
 	game.settleLastWord()
 	game.endScorelessTurn()
 
+	game.recordAction(Action{Type: ActionTypePass, PlayerID: playerID})
+
 	return nil
 }
 
@@ -227,6 +278,8 @@ func (game *Game) ExchangeLetters(playerID string, letters []rune) error {
 		return ErrMissingLetters
 	}
 
+	game.recordMove(Move{Type: MoveTypeExchange, PlayerID: playerID, Rack: player.letters, Letters: letters})
+
 	player.takeLetters(letters)
 	player.giveLetters(game.pool[game.poolIndex : game.poolIndex+len(letters)])
 	game.poolIndex += len(letters)
@@ -239,6 +292,8 @@ func (game *Game) ExchangeLetters(playerID string, letters []rune) error {
 	game.settleLastWord()
 	game.endScorelessTurn()
 
+	game.recordAction(Action{Type: ActionTypeExchange, PlayerID: playerID, Letters: append([]rune(nil), letters...)})
+
 	return nil
 }
 
@@ -260,6 +315,8 @@ func (game *Game) Challenge(playerID string) (ChallengeOutcome, error) {
 		return ChallengeOutcome{}, fmt.Errorf("resolving challenge: %w", err)
 	}
 
+	game.recordAction(Action{Type: ActionTypeChallenge, PlayerID: playerID})
+
 	return outcome, nil
 }
 
@@ -305,6 +362,8 @@ func (game *Game) CastVote(playerID string, vote Vote) (ChallengeOutcome, error)
 		return ChallengeOutcome{}, fmt.Errorf("resolving challenge: %w", err)
 	}
 
+	game.recordAction(Action{Type: ActionTypeVote, PlayerID: playerID, Vote: vote})
+
 	return outcome, nil
 }
 
@@ -427,6 +486,8 @@ func (game *Game) rescindLastWord() (Word, error) {
 	game.poolIndex -= lastTurn.LettersDrawn
 	mover.giveLetters(lettersFromMap(lastTurn.LettersUsed))
 
+	game.recordMove(Move{Type: MoveTypeWithdrawn, PlayerID: mover.id, Rack: mover.letters, Points: -lastTurn.Points})
+
 	// shuffle so the same letters cannot simply be drawn again
 	game.shufflePoolTail()
 
@@ -590,9 +651,14 @@ func (game *Game) advanceTurn() {
 	}
 }
 
+// shufflePoolTail shuffles the undrawn letters. Each shuffle draws from its
+// own stream of the game's seed, so a replay shuffles identically.
 func (game *Game) shufflePoolTail() {
+	random := rand.New(rand.NewPCG(game.shuffleSeed, game.shuffles))
+	game.shuffles++
+
 	tail := game.pool[game.poolIndex:]
-	rand.Shuffle(len(tail), func(first, second int) {
+	random.Shuffle(len(tail), func(first, second int) {
 		tail[first], tail[second] = tail[second], tail[first]
 	})
 }
@@ -609,17 +675,23 @@ func (game *Game) finish(goingOutPlayerID string) {
 			continue
 		}
 
-		var rackValue int
-		for _, letter := range player.letters {
-			rackValue += game.config.LetterPoints[letter]
-		}
-
+		rackValue := game.rackValue(*player)
 		player.finalAdjustment = -rackValue
 		forfeitTotal += rackValue
+
+		if len(player.letters) > 0 {
+			game.recordMove(Move{Type: MoveTypeEndRack, PlayerID: player.id, Letters: player.letters, Points: -rackValue})
+		}
 	}
 
 	if goingOutIndex := game.playerIndex(goingOutPlayerID); goingOutIndex >= 0 {
 		game.players[goingOutIndex].finalAdjustment = forfeitTotal
+
+		for _, player := range game.players {
+			if player.id != goingOutPlayerID && len(player.letters) > 0 {
+				game.recordMove(Move{Type: MoveTypeEndRack, PlayerID: goingOutPlayerID, Letters: player.letters, Points: game.rackValue(player)})
+			}
+		}
 	}
 
 	game.winnerIDs = game.computeWinnerIDs()
@@ -643,10 +715,25 @@ func (game *Game) computeWinnerIDs() []string {
 	return winnerIDs
 }
 
+// lettersFromMap returns the letters in board order, row by row, so callers
+// handing them to a rack do so deterministically.
 func lettersFromMap(lettersByPoint map[Point]rune) []rune {
-	var letters []rune
-	for _, letter := range lettersByPoint {
-		letters = append(letters, letter)
+	points := make([]Point, 0, len(lettersByPoint))
+	for point := range lettersByPoint {
+		points = append(points, point)
+	}
+
+	sort.Slice(points, func(first, second int) bool {
+		if points[first].Row() != points[second].Row() {
+			return points[first].Row() < points[second].Row()
+		}
+
+		return points[first].Column() < points[second].Column()
+	})
+
+	letters := make([]rune, 0, len(points))
+	for _, point := range points {
+		letters = append(letters, lettersByPoint[point])
 	}
 
 	return letters
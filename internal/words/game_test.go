@@ -2,6 +2,7 @@ package words_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/carterjs/words/internal/words"
@@ -9,6 +10,39 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func TestGame_AddPlayer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		player  string
+		wantErr error
+	}{
+		{name: "adds named players", player: "alice"},
+		{name: "rejects empty names", player: "", wantErr: words.ErrInvalidName},
+		{name: "rejects long names", player: strings.Repeat("é", 41), wantErr: words.ErrInvalidName},
+		{name: "rejects control characters", player: "al\x00ice", wantErr: words.ErrInvalidName},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			game := words.NewGame(testConfig(map[rune]int{'A': 20}, 3))
+
+			player, err := game.AddPlayer(test.player)
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+				assert.Empty(t, game.Players())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.player, player.Name())
+		})
+	}
+}
+
 func TestGame_Start(t *testing.T) {
 	t.Parallel()
 
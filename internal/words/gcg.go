@@ -0,0 +1,698 @@
+package words
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GCG is the notation word-game analysis tools such as Quackle read and
+// write: pragma lines starting with # and an event line per move starting
+// with >. Its board is a grid with lettered columns and numbered rows, so an
+// export declares which cell of the unbounded board is its top-left A1 with
+// a #window pragma.
+const (
+	// gcgWindowRadius is how far the default window, a standard 15x15 board
+	// centred on the origin, reaches from the centre cell.
+	gcgWindowRadius = 7
+	// gcgBlank stands for a blank tile on a rack.
+	gcgBlank = '?'
+	// gcgPlayedThrough stands for a letter of a word already on the board.
+	gcgPlayedThrough = '.'
+	// maxGCGMoves bounds how many moves notation may hold, well beyond any
+	// real game, so reading it cannot be made to replay without end.
+	maxGCGMoves = 1000
+)
+
+// gcgWindow places a GCG board on the unbounded one: its A1 cell is at
+// column, row.
+type gcgWindow struct {
+	column, row int
+}
+
+var defaultGCGWindow = gcgWindow{column: -gcgWindowRadius, row: -gcgWindowRadius}
+
+// position renders where a word starts: row then column across, column
+// then row down.
+func (window gcgWindow) position(start Point, direction Direction) string {
+	column := gcgColumnLabel(start.Column() - window.column)
+	row := start.Row() - window.row + 1
+
+	if direction == DirectionHorizontal {
+		return fmt.Sprint(row, column)
+	}
+
+	return fmt.Sprint(column, row)
+}
+
+// parsePosition reads a position rendered by position.
+func (window gcgWindow) parsePosition(position string) (Point, Direction, bool) {
+	direction := DirectionVertical
+	if position != "" && unicode.IsDigit(rune(position[0])) {
+		direction = DirectionHorizontal
+	}
+
+	split := strings.IndexFunc(position, func(char rune) bool {
+		return unicode.IsDigit(char) != (direction == DirectionHorizontal)
+	})
+	if split <= 0 {
+		return "", "", false
+	}
+
+	rawColumn, rawRow := position[split:], position[:split]
+	if direction == DirectionVertical {
+		rawColumn, rawRow = rawRow, rawColumn
+	}
+
+	column, validColumn := parseGCGColumnLabel(rawColumn)
+	row, err := strconv.Atoi(rawRow)
+	if !validColumn || err != nil || row < 1 {
+		return "", "", false
+	}
+
+	return NewPoint(window.column+column, window.row+row-1), direction, true
+}
+
+// gcgColumnLabel letters a column from zero as spreadsheets do, so windows
+// wider than the alphabet go on to AA, AB and so on.
+func gcgColumnLabel(column int) string {
+	var label []rune
+	for column++; column > 0; column /= 26 {
+		column--
+		label = append([]rune{rune('A' + column%26)}, label...)
+	}
+
+	return string(label)
+}
+
+func parseGCGColumnLabel(label string) (int, bool) {
+	var column int
+	for _, char := range label {
+		if char < 'A' || char > 'Z' {
+			return 0, false
+		}
+		column = column*26 + int(char-'A') + 1
+	}
+
+	return column - 1, label != ""
+}
+
+// WriteGCG writes the game's move history in GCG notation, with every
+// player's rack before each of their moves. The window is the standard board
+// centred on the origin, grown to cover every word ever placed. A game
+// played before moves were recorded is reported as ErrNoMoveHistory.
+func (game *Game) WriteGCG(w io.Writer) error {
+	if len(game.moves) == 0 && (game.round > 1 || game.turn > 0 || game.finished) {
+		return ErrNoMoveHistory
+	}
+
+	nicknames := gcgNicknames(game.players)
+	window := game.gcgWindow()
+
+	writer := bufio.NewWriter(w)
+	fmt.Fprintln(writer, "#character-encoding UTF-8")
+	fmt.Fprintf(writer, "#id words %s\n", game.id)
+	for index, player := range game.players {
+		name := strings.Join(strings.Fields(player.name), " ")
+		if name == "" {
+			name = nicknames[player.id]
+		}
+		fmt.Fprintf(writer, "#player%d %s %s\n", index+1, nicknames[player.id], name)
+	}
+	fmt.Fprintf(writer, "#window %d %d\n", window.column, window.row)
+
+	board := NewBoard(game.config)
+	totals := make(map[string]int, len(game.players))
+	for index, move := range game.moves {
+		notation, err := window.moveNotation(board, move)
+		if err != nil {
+			return fmt.Errorf("writing move %d: %w", index+1, err)
+		}
+
+		totals[move.PlayerID] += move.Points
+		fmt.Fprintf(writer, ">%s: %s %+d %d\n", nicknames[move.PlayerID], notation, move.Points, totals[move.PlayerID])
+	}
+
+	return writer.Flush()
+}
+
+// gcgNicknames gives each player the one-word, unique name event lines
+// refer to them by.
+func gcgNicknames(players []Player) map[string]string {
+	nicknames := make(map[string]string, len(players))
+	taken := make(map[string]struct{}, len(players))
+
+	for index, player := range players {
+		nickname := strings.Join(strings.Fields(player.name), "_")
+		if nickname == "" {
+			nickname = fmt.Sprint("player", index+1)
+		}
+
+		if _, clashes := taken[nickname]; clashes {
+			nickname = fmt.Sprint(nickname, "_", index+1)
+		}
+
+		nicknames[player.id] = nickname
+		taken[nickname] = struct{}{}
+	}
+
+	return nicknames
+}
+
+// gcgWindow returns the default window grown to the top-left of every word
+// in the history, withdrawn ones included.
+func (game *Game) gcgWindow() gcgWindow {
+	window := defaultGCGWindow
+	for _, move := range game.moves {
+		if move.Word == nil {
+			continue
+		}
+
+		window.column = min(window.column, move.Word.Column)
+		window.row = min(window.row, move.Word.Row)
+	}
+
+	return window
+}
+
+// moveNotation renders the move's rack and what it did, replaying plays and
+// withdrawals onto the board so played-through letters can be marked.
+func (window gcgWindow) moveNotation(board *Board, move Move) (string, error) {
+	rack := gcgLetters(move.Rack)
+
+	switch move.Type {
+	case MoveTypePlay:
+		if move.Word == nil {
+			return "", fmt.Errorf("play without a word: %w", ErrReplayDiverged)
+		}
+
+		word := move.Word.word()
+		result, err := board.PlaceWord(word)
+		if err != nil {
+			return "", fmt.Errorf("replaying word %q: %w: %w", word.String(), ErrReplayDiverged, err)
+		}
+
+		letters := make([]rune, 0, word.Length())
+		for position := range word.Length() {
+			point, letter, _ := word.Index(position)
+
+			_, placed := result.LettersUsed[point]
+			switch {
+			case !placed:
+				letter = gcgPlayedThrough
+			case word.Blank(point):
+				letter = unicode.ToLower(letter)
+			}
+
+			letters = append(letters, letter)
+		}
+
+		return fmt.Sprintf("%s %s %s", rack, window.position(word.Start(), word.Direction()), string(letters)), nil
+	case MoveTypeWithdrawn:
+		if err := board.removeLastWord(); err != nil {
+			return "", fmt.Errorf("withdrawing word: %w: %w", ErrReplayDiverged, err)
+		}
+
+		return rack + " --", nil
+	case MoveTypeExchange:
+		return fmt.Sprintf("%s -%s", rack, gcgLetters(move.Letters)), nil
+	case MoveTypePass:
+		return rack + " -", nil
+	case MoveTypeEndRack:
+		return fmt.Sprintf("(%s)", gcgLetters(move.Letters)), nil
+	default:
+		return "", fmt.Errorf("unknown move type %q: %w", move.Type, ErrReplayDiverged)
+	}
+}
+
+// gcgLetters renders rack letters with blanks as question marks.
+func gcgLetters(letters []rune) string {
+	rendered := make([]rune, len(letters))
+	for index, letter := range letters {
+		if letter == BlankLetter {
+			letter = gcgBlank
+		}
+		rendered[index] = letter
+	}
+
+	return string(rendered)
+}
+
+func parseGCGLetters(rendered string) []rune {
+	letters := []rune(rendered)
+	for index, letter := range letters {
+		if letter == gcgBlank {
+			letters[index] = BlankLetter
+		}
+	}
+
+	return letters
+}
+
+// gcgNotation is a parsed GCG file.
+type gcgNotation struct {
+	players []gcgPlayer
+	window  gcgWindow
+	events  []gcgEvent
+}
+
+type gcgPlayer struct {
+	nickname, name string
+}
+
+// gcgEvent is one event line. Rack is nil on end-of-game lines, which carry
+// the rack they score in Letters instead.
+type gcgEvent struct {
+	line     int
+	nickname string
+	kind     MoveType
+	rack     []rune
+	position string
+	word     string
+	letters  []rune
+	points   int
+}
+
+// ReadGCG builds a new game with the given config from GCG notation,
+// replaying its moves through PlayWord, ExchangeLetters and PassTurn, and
+// withdrawing challenged plays through Challenge, with the pool arranged so
+// every player draws the racks the notation records. A #window pragma places
+// the notation's board on the unbounded one; without one it is the standard
+// board centred on the origin. Notation that cannot be read, holds more than
+// a thousand moves, or that the game will not replay, is reported as
+// ErrMalformedNotation.
+func ReadGCG(r io.Reader, config Config) (*Game, error) {
+	notation, err := parseGCG(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(notation.players) == 0 {
+		return nil, fmt.Errorf("%w: no #player pragmas", ErrMalformedNotation)
+	}
+
+	game := NewGame(config)
+	playerIDs := make(map[string]string, len(notation.players))
+	for _, player := range notation.players {
+		added, err := game.AddPlayer(player.name)
+		if err != nil {
+			return nil, fmt.Errorf("adding player %s: %w", player.nickname, err)
+		}
+		playerIDs[player.nickname] = added.id
+	}
+
+	var dealt int
+	for _, player := range notation.players {
+		if rack, known := notation.nextRack(player.nickname, -1); known {
+			if err := game.arrangePool(dealt, rack); err != nil {
+				return nil, fmt.Errorf("%w: dealing %s: %w", ErrMalformedNotation, player.nickname, err)
+			}
+		}
+		dealt += min(config.RackSize, len(game.pool)-dealt)
+	}
+
+	if err := game.Start(); err != nil {
+		return nil, fmt.Errorf("starting game: %w", err)
+	}
+
+	for index, event := range notation.events {
+		playerID, exists := playerIDs[event.nickname]
+		if !exists {
+			return nil, fmt.Errorf("line %d: %w: no #player pragma names %s", event.line, ErrMalformedNotation, event.nickname)
+		}
+
+		if err := game.replayGCGEvent(notation, index, playerID); err != nil {
+			return nil, fmt.Errorf("line %d: %w: %w", event.line, ErrMalformedNotation, err)
+		}
+	}
+
+	return game, nil
+}
+
+func parseGCG(r io.Reader) (gcgNotation, error) {
+	notation := gcgNotation{window: defaultGCGWindow}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		pragma, arguments, _ := strings.Cut(text, " ")
+
+		switch {
+		case strings.HasPrefix(pragma, "#player"):
+			number, err := strconv.Atoi(strings.TrimPrefix(pragma, "#player"))
+			fields := strings.Fields(arguments)
+			if err != nil || number != len(notation.players)+1 || len(fields) == 0 {
+				return gcgNotation{}, fmt.Errorf("line %d: %w: players must be numbered in order and named", line, ErrMalformedNotation)
+			}
+
+			player := gcgPlayer{nickname: fields[0], name: fields[0]}
+			if len(fields) > 1 {
+				player.name = strings.Join(fields[1:], " ")
+			}
+			notation.players = append(notation.players, player)
+		case pragma == "#window":
+			_, err := fmt.Sscan(arguments, &notation.window.column, &notation.window.row)
+			if err != nil {
+				return gcgNotation{}, fmt.Errorf("line %d: %w: reading window: %w", line, ErrMalformedNotation, err)
+			}
+		case strings.HasPrefix(text, ">"):
+			event, relevant, err := parseGCGEvent(text)
+			if err != nil {
+				return gcgNotation{}, fmt.Errorf("line %d: %w: %w", line, ErrMalformedNotation, err)
+			}
+
+			if relevant {
+				if len(notation.events) == maxGCGMoves {
+					return gcgNotation{}, fmt.Errorf("line %d: %w: more than %d moves", line, ErrMalformedNotation, maxGCGMoves)
+				}
+
+				event.line = line
+				notation.events = append(notation.events, event)
+			}
+		}
+		// other pragmas, and lines continuing a note, do not affect play
+	}
+
+	if err := scanner.Err(); err != nil {
+		return gcgNotation{}, fmt.Errorf("%w: %w", ErrMalformedNotation, err)
+	}
+
+	return notation, nil
+}
+
+// parseGCGEvent reads an event line, reporting whether it affects play:
+// challenge bonuses and time penalties do not exist in this game.
+func parseGCGEvent(text string) (gcgEvent, bool, error) {
+	nickname, rest, found := strings.Cut(strings.TrimPrefix(text, ">"), ":")
+	fields := strings.Fields(rest)
+	if !found || len(fields) < 3 {
+		return gcgEvent{}, false, errors.New("an event needs a player, a move, points and a total")
+	}
+
+	points, err := strconv.Atoi(fields[len(fields)-2])
+	if err != nil {
+		return gcgEvent{}, false, fmt.Errorf("reading points: %w", err)
+	}
+
+	event := gcgEvent{nickname: strings.TrimSpace(nickname), points: points}
+	move := fields[:len(fields)-2]
+
+	if last := move[len(move)-1]; strings.HasPrefix(last, "(") && strings.HasSuffix(last, ")") {
+		scored := strings.Trim(last, "()")
+		if scored == "challenge" || scored == "time" {
+			return gcgEvent{}, false, nil
+		}
+
+		event.kind = MoveTypeEndRack
+		event.letters = parseGCGLetters(scored)
+		return event, true, nil
+	}
+
+	event.rack = parseGCGLetters(move[0])
+
+	switch {
+	case len(move) == 3:
+		event.kind = MoveTypePlay
+		event.position = move[1]
+		event.word = move[2]
+	case len(move) == 2 && move[1] == "-":
+		event.kind = MoveTypePass
+	case len(move) == 2 && move[1] == "--":
+		event.kind = MoveTypeWithdrawn
+	case len(move) == 2 && strings.HasPrefix(move[1], "-"):
+		event.kind = MoveTypeExchange
+		event.letters = parseGCGLetters(strings.TrimPrefix(move[1], "-"))
+	default:
+		return gcgEvent{}, false, fmt.Errorf("unrecognized move %q", strings.Join(move, " "))
+	}
+
+	return event, true, nil
+}
+
+// nextRack returns the rack the notation next records the player holding
+// after the event at the given index, if it records one: the rack of their
+// next move, or of the penalty for letters left when the game ends. A play
+// withdrawn next draws nothing that counts, so it records none.
+func (notation gcgNotation) nextRack(nickname string, after int) ([]rune, bool) {
+	for _, event := range notation.events[after+1:] {
+		if event.nickname != nickname {
+			continue
+		}
+
+		switch {
+		case event.kind == MoveTypeWithdrawn:
+			return nil, false
+		case event.kind == MoveTypeEndRack && event.points < 0:
+			return event.letters, true
+		case event.kind == MoveTypeEndRack:
+			// a bonus for going out scores someone else's rack
+			continue
+		default:
+			return event.rack, true
+		}
+	}
+
+	return nil, false
+}
+
+// replayGCGEvent takes the event at the given index, after arranging the
+// pool so the player draws the rack the notation records next.
+func (game *Game) replayGCGEvent(notation gcgNotation, index int, playerID string) error {
+	event := notation.events[index]
+
+	if event.kind == MoveTypeEndRack {
+		if !game.finished {
+			return errors.New("the game is not over")
+		}
+		return nil
+	}
+
+	// a withdrawn play's rack is the one it returns to
+	if event.kind == MoveTypeWithdrawn {
+		if err := game.withdrawLastPlay(playerID); err != nil {
+			return err
+		}
+		return game.checkGCGRack(event, playerID)
+	}
+
+	if err := game.checkGCGRack(event, playerID); err != nil {
+		return err
+	}
+	player := game.players[game.playerIndex(playerID)]
+
+	switch event.kind {
+	case MoveTypePlay:
+		word, err := game.gcgWord(notation.window, event)
+		if err != nil {
+			return err
+		}
+
+		checked, err := game.checkWord(playerID, word)
+		if err != nil {
+			return fmt.Errorf("checking word: %w", err)
+		}
+
+		if err := game.arrangeDraw(notation, index, player, lettersFromMap(checked.LettersUsed)); err != nil {
+			return err
+		}
+
+		result, err := game.PlayWord(playerID, word)
+		if err != nil {
+			return err
+		}
+
+		if result.Points != event.points {
+			return fmt.Errorf("the play scores %d, not %d", result.Points, event.points)
+		}
+	case MoveTypeExchange:
+		if err := game.arrangeDraw(notation, index, player, event.letters); err != nil {
+			return err
+		}
+
+		return game.ExchangeLetters(playerID, event.letters)
+	case MoveTypePass:
+		return game.PassTurn(playerID)
+	}
+
+	return nil
+}
+
+// checkGCGRack reports a player holding other letters than the event's rack.
+func (game *Game) checkGCGRack(event gcgEvent, playerID string) error {
+	letters := game.players[game.playerIndex(playerID)].letters
+	if !sameLetters(letters, event.rack) {
+		return fmt.Errorf("%s holds %s, not %s", event.nickname, gcgLetters(letters), gcgLetters(event.rack))
+	}
+
+	return nil
+}
+
+// gcgWord builds the word a play event places, filling played-through
+// letters from the board and marking lowercase letters as blanks.
+func (game *Game) gcgWord(window gcgWindow, event gcgEvent) (Word, error) {
+	start, direction, valid := window.parsePosition(event.position)
+	if !valid {
+		return Word{}, fmt.Errorf("unreadable position %q", event.position)
+	}
+
+	// some writers bracket played-through letters instead of dotting them
+	letters := []rune(strings.NewReplacer("(", "", ")", "").Replace(event.word))
+
+	var blanks []Point
+	for position, letter := range letters {
+		switch {
+		case letter == gcgPlayedThrough:
+			letters[position] = PlaceholderLetter
+		case unicode.IsLower(letter):
+			deltaColumn, deltaRow := direction.Vector(position)
+			blanks = append(blanks, start.Offset(deltaColumn, deltaRow))
+			letters[position] = unicode.ToUpper(letter)
+		}
+	}
+
+	word, resolvable := game.board.FillPlaceholders(NewWord(start, direction, string(letters)))
+	if !resolvable {
+		return Word{}, fmt.Errorf("%s %s plays through an empty square", event.position, event.word)
+	}
+
+	return word.WithBlanks(blanks...), nil
+}
+
+// arrangeDraw moves to the front of the pool the letters the player must
+// draw after spending the given ones to hold the rack the notation records
+// next for them.
+func (game *Game) arrangeDraw(notation gcgNotation, index int, player Player, spent []rune) error {
+	next, known := notation.nextRack(notation.events[index].nickname, index)
+	if !known {
+		return nil
+	}
+
+	kept, holds := subtractLetters(player.letters, spent)
+	if !holds {
+		return fmt.Errorf("%s does not hold %s", notation.events[index].nickname, gcgLetters(spent))
+	}
+
+	drawn, follows := subtractLetters(next, kept)
+	if !follows {
+		return fmt.Errorf("keeping %s cannot leave %s holding %s next", gcgLetters(kept), notation.events[index].nickname, gcgLetters(next))
+	}
+
+	if err := game.arrangePool(0, drawn); err != nil {
+		return fmt.Errorf("drawing %s: %w", gcgLetters(drawn), err)
+	}
+
+	return nil
+}
+
+// arrangePool moves the given letters to the undrawn pool, offset letters
+// in, so they are the next drawn from there.
+func (game *Game) arrangePool(offset int, letters []rune) error {
+	for index, letter := range letters {
+		position := game.poolIndex + offset + index
+		if position >= len(game.pool) {
+			return ErrNotEnoughLettersInPool
+		}
+
+		found := slices.Index(game.pool[position:], letter)
+		if found < 0 {
+			return fmt.Errorf("no %q left: %w", letter, ErrNotEnoughLettersInPool)
+		}
+
+		game.pool[position], game.pool[position+found] = game.pool[position+found], game.pool[position]
+	}
+
+	return nil
+}
+
+// withdrawLastPlay has the other players challenge the mover's last play
+// until the challenge is upheld.
+func (game *Game) withdrawLastPlay(moverID string) error {
+	moverIndex := game.playerIndex(moverID)
+	if game.lastWord == nil || game.lastWord.playerID != moverID {
+		return ErrNothingToChallenge
+	}
+
+	var outcome ChallengeOutcome
+	for offset := 1; offset < len(game.players) && !outcome.Resolved; offset++ {
+		voterID := game.players[(moverIndex+offset)%len(game.players)].id
+
+		var err error
+		if offset == 1 {
+			outcome, err = game.Challenge(voterID)
+		} else {
+			outcome, err = game.CastVote(voterID, VoteInvalid)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if !outcome.Upheld {
+		return errors.New("the challenge was not upheld")
+	}
+
+	return nil
+}
+
+// subtractLetters returns the letters left after taking away the given
+// ones, and whether they were all there to take.
+func subtractLetters(from, taken []rune) ([]rune, bool) {
+	remaining := slices.Clone(from)
+	for _, letter := range taken {
+		index := slices.Index(remaining, letter)
+		if index < 0 {
+			return nil, false
+		}
+		remaining = slices.Delete(remaining, index, index+1)
+	}
+
+	return remaining, true
+}
+
+func sameLetters(first, second []rune) bool {
+	remaining, contained := subtractLetters(first, second)
+	return contained && len(remaining) == 0
+}
+
+// ExportGCG writes a finished game's history in GCG notation. Racks stay
+// private while a game is in play, so unfinished games are refused with
+// ErrGameNotFinished.
+func (service *Service) ExportGCG(ctx context.Context, gameID string, w io.Writer) error {
+	game, err := service.store.GameByID(ctx, gameID)
+	if err != nil {
+		return fmt.Errorf("loading game: %w", err)
+	}
+
+	if !game.finished {
+		return ErrGameNotFinished
+	}
+
+	return game.WriteGCG(w)
+}
+
+// ImportGCG creates a game with the preset's config by replaying GCG
+// notation with ReadGCG.
+func (service *Service) ImportGCG(ctx context.Context, r io.Reader, presetID string) (*Game, error) {
+	preset, exists := PresetByID(presetID)
+	if !exists {
+		return nil, ErrPresetNotFound
+	}
+
+	game, err := ReadGCG(r, preset.Config)
+	if err != nil {
+		return nil, err
+	}
+	game.advanceVersion()
+
+	if err := service.store.SaveGame(ctx, game); err != nil {
+		return nil, fmt.Errorf("saving imported game: %w", err)
+	}
+
+	return game, nil
+}
@@ -0,0 +1,201 @@
+package words_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/carterjs/words/internal/words"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGame_WriteGCG(t *testing.T) {
+	t.Parallel()
+
+	config := testConfig(map[rune]int{'A': 10, words.BlankLetter: 2}, 3)
+
+	game := newStartedGame(t, 2, config)
+	playCurrent(t, game, horizontal(0, 0, "AA"))
+	exchanger := mustPlayer(t, game, game.CurrentPlayerID())
+	require.NoError(t, game.ExchangeLetters(exchanger.ID(), exchanger.Letters()[:1]))
+	require.NoError(t, game.PassTurn(game.CurrentPlayerID()))
+	playCurrent(t, game, vertical(0, 0, "AAA"))
+	_, err := game.Challenge(game.CurrentPlayerID())
+	require.NoError(t, err)
+	for !game.Finished() {
+		require.NoError(t, game.PassTurn(game.CurrentPlayerID()))
+	}
+
+	notation := writeGCG(t, game)
+
+	lines := strings.Split(strings.TrimSpace(notation), "\n")
+	patterns := []string{
+		`^#character-encoding UTF-8$`,
+		`^#id words ` + game.ID() + `$`,
+		`^#player1 player-0 player-0$`,
+		`^#player2 player-1 player-1$`,
+		`^#window -7 -7$`,
+		`^>player-0: [A?]{3} 8H [Aa]{2} \+\d \d$`,
+		`^>player-1: [A?]{3} -[A?] \+0 0$`,
+		`^>player-0: [A?]{3} - \+0 \d$`,
+		`^>player-1: [A?]{3} H8 \.[Aa]{2} \+\d \d$`,
+		`^>player-1: [A?]{3} -- -\d 0$`,
+	}
+	require.Greater(t, len(lines), len(patterns))
+	for index, pattern := range patterns {
+		assert.Regexp(t, regexp.MustCompile(pattern), lines[index])
+	}
+	assert.Regexp(t, regexp.MustCompile(`^>player-\d: \([A?]+\) -\d+ -?\d+$`), lines[len(lines)-1])
+
+	imported, err := words.ReadGCG(strings.NewReader(notation), config)
+	require.NoError(t, err)
+	assert.True(t, imported.Finished())
+	for index, player := range imported.Players() {
+		assert.Equal(t, game.Players()[index].Score(), player.Score())
+	}
+
+	withoutID := func(notation string) string {
+		return regexp.MustCompile(`(?m)^#id .*$`).ReplaceAllString(notation, "")
+	}
+	assert.Equal(t, withoutID(notation), withoutID(writeGCG(t, imported)))
+}
+
+func TestReadGCG(t *testing.T) {
+	t.Parallel()
+
+	const header = "#player1 ann Ann Example\n#player2 bob Bob\n"
+
+	tests := []struct {
+		name       string
+		notation   string
+		wantErr    error
+		wantScores []int
+	}{
+		{
+			name:       "replays plays through the board",
+			notation:   header + ">ann: AAA 8H AA +2 2\n>bob: AAA H8 .AA +3 3\n",
+			wantScores: []int{2, 3},
+		},
+		{
+			name:       "places the board by its window",
+			notation:   header + "#window -3 -3\n>ann: AAA 4D AA +2 2\n>bob: AAA D4 .AA +3 3\n",
+			wantScores: []int{2, 3},
+		},
+		{
+			name:       "ignores notes and challenge bonuses",
+			notation:   header + "#note a quiet start\n>ann: AAA 8H AA +2 2\n>bob: (challenge) +5 5\n>bob: AAA - +0 5\n",
+			wantScores: []int{2, 0},
+		},
+		{
+			name:     "rejects notation without players",
+			notation: ">ann: AAA 8H AA +2 2\n",
+			wantErr:  words.ErrMalformedNotation,
+		},
+		{
+			name:     "rejects an unknown player",
+			notation: header + ">cat: AAA 8H AA +2 2\n",
+			wantErr:  words.ErrMalformedNotation,
+		},
+		{
+			name:     "rejects a play scored differently",
+			notation: header + ">ann: AAA 8H AA +5 5\n",
+			wantErr:  words.ErrMalformedNotation,
+		},
+		{
+			name:     "rejects a rack the pool cannot deal",
+			notation: header + ">ann: AAB 8H AA +2 2\n",
+			wantErr:  words.ErrMalformedNotation,
+		},
+		{
+			name:     "rejects a play off the centre",
+			notation: header + ">ann: AAA 1A AA +2 2\n",
+			wantErr:  words.ErrFirstWordNotCentered,
+		},
+		{
+			name:     "rejects a move out of turn",
+			notation: header + ">bob: AAA - +0 0\n",
+			wantErr:  words.ErrNotYourTurn,
+		},
+		{
+			name:     "rejects an unreadable move",
+			notation: header + ">ann: AAA 8H +2 2\n",
+			wantErr:  words.ErrMalformedNotation,
+		},
+		{
+			name:     "rejects more moves than any game holds",
+			notation: header + strings.Repeat(">ann: AAA - +0 0\n>bob: AAA - +0 0\n", 501),
+			wantErr:  words.ErrMalformedNotation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			game, err := words.ReadGCG(strings.NewReader(test.notation), testConfig(map[rune]int{'A': 16}, 3))
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var scores []int
+			for _, player := range game.Players() {
+				scores = append(scores, player.Score())
+			}
+			assert.Equal(t, test.wantScores, scores)
+			assert.Equal(t, "Ann Example", game.Players()[0].Name())
+		})
+	}
+}
+
+func TestService_ExportGCG(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		passes  int
+		wantErr error
+	}{
+		{name: "exports a finished game", passes: 4},
+		{name: "refuses a game in play", passes: 1, wantErr: words.ErrGameNotFinished},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			preset, exists := words.PresetByID("standard")
+			require.True(t, exists)
+
+			game := newStartedGame(t, 2, preset.Config)
+			for range test.passes {
+				require.NoError(t, game.PassTurn(game.CurrentPlayerID()))
+			}
+
+			service := newStoredService(t)
+			imported, err := service.ImportGCG(t.Context(), strings.NewReader(writeGCG(t, game)), "standard")
+			require.NoError(t, err)
+
+			var exported bytes.Buffer
+			err = service.ExportGCG(t.Context(), imported.ID(), &exported)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, exported.String(), "#id words "+imported.ID())
+		})
+	}
+}
+
+func writeGCG(t *testing.T, game *words.Game) string {
+	t.Helper()
+
+	var notation bytes.Buffer
+	require.NoError(t, game.WriteGCG(&notation))
+
+	return notation.String()
+}
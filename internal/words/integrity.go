@@ -0,0 +1,223 @@
+package words
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Invariant names a rule every consistent game obeys.
+type Invariant string
+
+const (
+	// InvariantLetterCount: the undrawn pool, every rack and the board
+	// together hold exactly the configured letter distribution.
+	InvariantLetterCount Invariant = "letter_count"
+	// InvariantTurn: the turn indexes a player.
+	InvariantTurn Invariant = "turn"
+	// InvariantPoolIndex: the pool index lies within the pool.
+	InvariantPoolIndex Invariant = "pool_index"
+	// InvariantRackSize: no rack holds more than the configured rack size.
+	InvariantRackSize Invariant = "rack_size"
+	// InvariantTurnRecord: every word on the board was placed by exactly one
+	// recorded turn, which scored what the word scores.
+	InvariantTurnRecord Invariant = "turn_record"
+	// InvariantFinalAdjustment: players are only adjusted for the letters
+	// left on their racks once the game is over, and only the player who
+	// went out gains what the others forfeit.
+	InvariantFinalAdjustment Invariant = "final_adjustment"
+	// InvariantWinners: a finished game's winners have the highest score.
+	InvariantWinners Invariant = "winners"
+)
+
+// IntegrityProblem is one broken invariant, described precisely enough to
+// repair by hand.
+type IntegrityProblem struct {
+	Invariant Invariant `json:"invariant"`
+	PlayerID  string    `json:"playerId,omitempty"`
+	Detail    string    `json:"detail"`
+}
+
+// IntegrityError lists every invariant a game breaks. It matches
+// ErrInconsistentGame.
+type IntegrityError struct {
+	GameID   string             `json:"gameId"`
+	Problems []IntegrityProblem `json:"problems"`
+}
+
+// Error implements the error interface.
+func (integrity IntegrityError) Error() string {
+	details := make([]string, 0, len(integrity.Problems))
+	for _, problem := range integrity.Problems {
+		details = append(details, problem.Detail)
+	}
+
+	return fmt.Sprintf("game %s is inconsistent: %s", integrity.GameID, strings.Join(details, "; "))
+}
+
+// Unwrap lets errors.Is match ErrInconsistentGame.
+func (integrity IntegrityError) Unwrap() error {
+	return ErrInconsistentGame
+}
+
+// Validate checks the game's invariants, returning an IntegrityError listing
+// every one it breaks, or nil if it is consistent.
+func (game *Game) Validate() error {
+	integrity := IntegrityError{GameID: game.id}
+	report := func(invariant Invariant, playerID, format string, args ...any) {
+		integrity.Problems = append(integrity.Problems, IntegrityProblem{
+			Invariant: invariant,
+			PlayerID:  playerID,
+			Detail:    fmt.Sprintf(format, args...),
+		})
+	}
+
+	if len(game.players) == 0 && game.turn != 0 || len(game.players) > 0 && (game.turn < 0 || game.turn >= len(game.players)) {
+		report(InvariantTurn, "", "turn %d is out of range for %d players", game.turn, len(game.players))
+	}
+
+	if game.poolIndex < 0 || game.poolIndex > len(game.pool) {
+		report(InvariantPoolIndex, "", "pool index %d is out of range for a pool of %d letters", game.poolIndex, len(game.pool))
+	} else {
+		game.validateLetterCounts(report)
+	}
+
+	for _, player := range game.players {
+		if len(player.letters) > game.config.RackSize {
+			report(InvariantRackSize, player.id, "player %s holds %d letters, more than the rack size of %d", player.id, len(player.letters), game.config.RackSize)
+		}
+	}
+
+	game.validateScores(report)
+
+	if len(integrity.Problems) > 0 {
+		return integrity
+	}
+
+	return nil
+}
+
+// validateLetterCounts checks every letter is accounted for exactly once.
+func (game *Game) validateLetterCounts(report func(Invariant, string, string, ...any)) {
+	pool := letterCounts(game.pool[game.poolIndex:])
+
+	racks := make(map[rune]int)
+	for _, player := range game.players {
+		for _, letter := range player.letters {
+			racks[letter]++
+		}
+	}
+
+	board := make(map[rune]int)
+	for point, letter := range game.board.grid {
+		if _, isBlank := game.board.blanks[point]; isBlank {
+			letter = BlankLetter
+		}
+		board[letter]++
+	}
+
+	letters := make(map[rune]struct{})
+	for _, counts := range []map[rune]int{game.config.LetterDistribution, pool, racks, board} {
+		for letter := range counts {
+			letters[letter] = struct{}{}
+		}
+	}
+
+	for _, letter := range slices.Sorted(maps.Keys(letters)) {
+		want := game.config.LetterDistribution[letter]
+		got := pool[letter] + racks[letter] + board[letter]
+		if got != want {
+			report(InvariantLetterCount, "", "letter %q: the distribution has %d but %d are in play (%d in the pool, %d on racks, %d on the board)",
+				letter, want, got, pool[letter], racks[letter], board[letter])
+		}
+	}
+}
+
+// validateScores checks every recorded score against the board with
+// RecomputeScores, and a finished game's winners against the scores.
+func (game *Game) validateScores(report func(Invariant, string, string, ...any)) {
+	audit, err := game.RecomputeScores()
+	if err != nil {
+		report(InvariantTurnRecord, "", "the board no longer replays: %v", err)
+		return
+	}
+
+	for _, word := range audit.UnrecordedWords {
+		report(InvariantTurnRecord, "", "word %q was placed by no recorded turn", word)
+	}
+
+	for _, discrepancy := range audit.Discrepancies {
+		switch {
+		case discrepancy.Turn == 0:
+			report(InvariantFinalAdjustment, discrepancy.PlayerID, "player %s has a final adjustment of %d but should have %d",
+				discrepancy.PlayerID, discrepancy.Recorded, discrepancy.Recomputed)
+		case discrepancy.Word == "":
+			report(InvariantTurnRecord, discrepancy.PlayerID, "turn %d of player %s placed letters that are not on the board",
+				discrepancy.Turn, discrepancy.PlayerID)
+		default:
+			report(InvariantTurnRecord, discrepancy.PlayerID, "turn %d of player %s scored %d but its word %q scores %d",
+				discrepancy.Turn, discrepancy.PlayerID, discrepancy.Recorded, discrepancy.Word, discrepancy.Recomputed)
+		}
+	}
+
+	if !game.finished {
+		return
+	}
+
+	if want := game.computeWinnerIDs(); !slices.Equal(want, game.winnerIDs) {
+		report(InvariantWinners, "", "winners are %v but the highest scores belong to %v", game.winnerIDs, want)
+	}
+}
+
+// ValidationReport lists what ValidateGames found.
+type ValidationReport struct {
+	// Checked counts the games validated.
+	Checked int `json:"checked"`
+	// Invalid lists the games that break their invariants.
+	Invalid []IntegrityError `json:"invalid"`
+	// Unreadable lists the games that could not be loaded at all.
+	Unreadable []UnreadableGame `json:"unreadable"`
+}
+
+// UnreadableGame explains why a game could not be loaded for validation.
+type UnreadableGame struct {
+	GameID string `json:"gameId"`
+	Reason string `json:"reason"`
+}
+
+// ValidateGames checks every stored game matching the query's filters with
+// Validate, reporting each inconsistent or unreadable one without stopping.
+// A store that validates games as it loads them reports inconsistent ones
+// as failed loads; those are listed as invalid too.
+func (service *Service) ValidateGames(ctx context.Context, query GameQuery) (ValidationReport, error) {
+	var report ValidationReport
+
+	err := service.eachGameSummary(ctx, query, func(summary GameSummary) error {
+		game, err := service.store.GameByID(ctx, summary.ID)
+		if errors.Is(err, ErrGameNotFound) {
+			return nil
+		}
+
+		if err == nil {
+			err = game.Validate()
+		}
+
+		var integrity IntegrityError
+		switch {
+		case err == nil:
+			report.Checked++
+		case errors.As(err, &integrity):
+			report.Checked++
+			report.Invalid = append(report.Invalid, integrity)
+		default:
+			report.Unreadable = append(report.Unreadable, UnreadableGame{GameID: summary.ID, Reason: err.Error()})
+		}
+
+		return nil
+	})
+
+	return report, err
+}
@@ -0,0 +1,219 @@
+package words_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/carterjs/words/internal/words"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGame_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		play           func(t *testing.T, game *words.Game)
+		corrupt        func(state *words.GameState)
+		wantInvariants []words.Invariant
+	}{
+		{
+			name: "accepts a game in play",
+			play: func(t *testing.T, game *words.Game) {
+				playCurrent(t, game, horizontal(0, 0, "AA"))
+				playCurrent(t, game, vertical(0, 0, "AA"))
+			},
+		},
+		{
+			name: "accepts a game after an exchange and an upheld challenge",
+			play: func(t *testing.T, game *words.Game) {
+				require.NoError(t, game.ExchangeLetters(game.CurrentPlayerID(), []rune{'A'}))
+				playCurrent(t, game, horizontal(0, 0, "AA"))
+				_, err := game.Challenge(game.CurrentPlayerID())
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "accepts a finished game",
+			play: func(t *testing.T, game *words.Game) {
+				for range 4 {
+					require.NoError(t, game.PassTurn(game.CurrentPlayerID()))
+				}
+				require.True(t, game.Finished())
+			},
+		},
+		{
+			name: "reports a missing letter",
+			corrupt: func(state *words.GameState) {
+				state.Players[0].Letters = state.Players[0].Letters[1:]
+			},
+			wantInvariants: []words.Invariant{words.InvariantLetterCount},
+		},
+		{
+			name: "reports a turn out of range",
+			corrupt: func(state *words.GameState) {
+				state.Turn = 2
+			},
+			wantInvariants: []words.Invariant{words.InvariantTurn},
+		},
+		{
+			name: "reports a pool index out of range",
+			corrupt: func(state *words.GameState) {
+				state.PoolIndex = len(state.Pool) + 1
+			},
+			wantInvariants: []words.Invariant{words.InvariantPoolIndex},
+		},
+		{
+			name: "reports an overfull rack",
+			corrupt: func(state *words.GameState) {
+				state.Players[0].Letters = append(state.Players[0].Letters, state.Pool[state.PoolIndex])
+				state.PoolIndex++
+			},
+			wantInvariants: []words.Invariant{words.InvariantRackSize},
+		},
+		{
+			name: "reports a misscored turn",
+			play: func(t *testing.T, game *words.Game) {
+				playCurrent(t, game, horizontal(0, 0, "AA"))
+			},
+			corrupt: func(state *words.GameState) {
+				for index := range state.Players {
+					if len(state.Players[index].Turns) > 0 {
+						state.Players[index].Turns[0].Points++
+					}
+				}
+			},
+			wantInvariants: []words.Invariant{words.InvariantTurnRecord},
+		},
+		{
+			name: "reports a turn whose word is not on the board",
+			play: func(t *testing.T, game *words.Game) {
+				playCurrent(t, game, horizontal(0, 0, "AA"))
+			},
+			corrupt: func(state *words.GameState) {
+				// the word's letters go back to the pool with it
+				state.Pool = append(state.Pool, 'A', 'A')
+				state.Words = nil
+			},
+			wantInvariants: []words.Invariant{words.InvariantTurnRecord},
+		},
+		{
+			name: "reports a final adjustment in play",
+			corrupt: func(state *words.GameState) {
+				state.Players[1].FinalAdjustment = -3
+			},
+			wantInvariants: []words.Invariant{words.InvariantFinalAdjustment},
+		},
+		{
+			name: "reports the wrong winners",
+			play: func(t *testing.T, game *words.Game) {
+				playCurrent(t, game, horizontal(0, 0, "AA"))
+				for range 4 {
+					require.NoError(t, game.PassTurn(game.CurrentPlayerID()))
+				}
+			},
+			corrupt: func(state *words.GameState) {
+				state.WinnerIDs = []string{"player-9"}
+			},
+			wantInvariants: []words.Invariant{words.InvariantWinners},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			game := newStartedGame(t, 2, testConfig(map[rune]int{'A': 16}, 3))
+			if test.play != nil {
+				test.play(t, game)
+			}
+
+			if test.corrupt != nil {
+				state := game.State()
+				test.corrupt(&state)
+
+				corrupted, err := words.NewGameFromState(state)
+				require.NoError(t, err)
+				game = corrupted
+			}
+
+			err := game.Validate()
+			if test.wantInvariants == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, words.ErrInconsistentGame)
+
+			var integrity words.IntegrityError
+			require.True(t, errors.As(err, &integrity))
+			assert.Equal(t, game.ID(), integrity.GameID)
+
+			var invariants []words.Invariant
+			for _, problem := range integrity.Problems {
+				invariants = append(invariants, problem.Invariant)
+			}
+			assert.Equal(t, test.wantInvariants, invariants)
+		})
+	}
+}
+
+func TestService_ValidateGames(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		consistent  int
+		corrupted   int
+		wantChecked int
+	}{
+		{name: "checks every game", consistent: 2, wantChecked: 2},
+		{name: "reports inconsistent games", consistent: 1, corrupted: 2, wantChecked: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			service := newStoredService(t)
+
+			var input strings.Builder
+			for range test.consistent {
+				input.WriteString(exportedState(t, uuid.NewString()))
+			}
+
+			var corruptedIDs []string
+			for range test.corrupted {
+				gameID := uuid.NewString()
+				corruptedIDs = append(corruptedIDs, gameID)
+
+				var state words.GameState
+				require.NoError(t, json.Unmarshal([]byte(exportedState(t, gameID)), &state))
+				state.Pool = state.Pool[1:]
+
+				data, err := json.Marshal(state)
+				require.NoError(t, err)
+				input.Write(append(data, '\n'))
+			}
+
+			_, err := service.ImportGames(t.Context(), strings.NewReader(input.String()), words.TransferFormatJSONLines, words.TransferFilter{})
+			require.NoError(t, err)
+
+			report, err := service.ValidateGames(t.Context(), words.GameQuery{})
+			require.NoError(t, err)
+			assert.Equal(t, test.wantChecked, report.Checked)
+			assert.Empty(t, report.Unreadable)
+
+			var invalidIDs []string
+			for _, integrity := range report.Invalid {
+				invalidIDs = append(invalidIDs, integrity.GameID)
+				assert.Equal(t, words.InvariantLetterCount, integrity.Problems[0].Invariant)
+			}
+			assert.ElementsMatch(t, corruptedIDs, invalidIDs)
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package words
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultListLimit is the page size of a listing that asks for none.
+	defaultListLimit = 20
+	// maxListLimit caps the page size of a listing.
+	maxListLimit = 100
+	// scanBatchSize is how many games are listed at once when walking every
+	// game matching a query.
+	scanBatchSize = 100
+)
+
+// GameStatus is where a game is in its lifecycle.
+type GameStatus string
+
+const (
+	// GameStatusLobby is a game still waiting for players to start it.
+	GameStatusLobby GameStatus = "LOBBY"
+	// GameStatusRunning is a game in play.
+	GameStatusRunning GameStatus = "RUNNING"
+	// GameStatusFinished is a game that is over.
+	GameStatusFinished GameStatus = "FINISHED"
+)
+
+// Status returns where the game is in its lifecycle.
+func (game *Game) Status() GameStatus {
+	switch {
+	case game.finished:
+		return GameStatusFinished
+	case game.started:
+		return GameStatusRunning
+	default:
+		return GameStatusLobby
+	}
+}
+
+// CreatedAt returns when the game was created.
+func (game *Game) CreatedAt() time.Time {
+	return game.createdAt
+}
+
+// GameSummary is a game's listable outline, without its board or racks.
+type GameSummary struct {
+	ID        string          `json:"id"`
+	Version   uint64          `json:"version"`
+	Status    GameStatus      `json:"status"`
+	Round     int             `json:"round"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+	Players   []PlayerSummary `json:"players"`
+}
+
+// PlayerSummary is a player's name and score within a GameSummary.
+type PlayerSummary struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+}
+
+// Summary outlines the game as saved at the given time.
+func (game *Game) Summary(updatedAt time.Time) GameSummary {
+	summary := GameSummary{
+		ID:        game.id,
+		Version:   game.version,
+		Status:    game.Status(),
+		Round:     game.round,
+		CreatedAt: game.createdAt,
+		UpdatedAt: updatedAt,
+	}
+
+	for _, player := range game.players {
+		summary.Players = append(summary.Players, PlayerSummary{
+			ID:    player.id,
+			Name:  player.name,
+			Score: player.Score(),
+		})
+	}
+
+	return summary
+}
+
+// GameQuery selects games to list. Zero fields match everything; time
+// bounds are exclusive. Games are listed newest first, and After resumes a
+// listing past the cursor a previous page ended with.
+type GameQuery struct {
+	Status        GameStatus
+	PlayerName    string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+	After         *GameCursor
+	// Limit caps the page; zero means no limit.
+	Limit int
+}
+
+// Matches reports whether the summary satisfies the query's filters. It
+// ignores the cursor and limit.
+func (query GameQuery) Matches(summary GameSummary) bool {
+	if query.Status != "" && summary.Status != query.Status {
+		return false
+	}
+
+	if !withinBounds(summary.CreatedAt, query.CreatedAfter, query.CreatedBefore) {
+		return false
+	}
+
+	if !withinBounds(summary.UpdatedAt, query.UpdatedAfter, query.UpdatedBefore) {
+		return false
+	}
+
+	if query.PlayerName == "" {
+		return true
+	}
+
+	for _, player := range summary.Players {
+		if player.Name == query.PlayerName {
+			return true
+		}
+	}
+
+	return false
+}
+
+func withinBounds(moment, after, before time.Time) bool {
+	if !after.IsZero() && !moment.After(after) {
+		return false
+	}
+
+	return before.IsZero() || moment.Before(before)
+}
+
+// GamePage is one page of a listing. NextCursor is empty on the last page.
+type GamePage struct {
+	Games      []GameSummary `json:"games"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+}
+
+// GameCursor is a position in a listing: just past the game with this
+// creation time and ID.
+type GameCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// CursorAfter returns the cursor positioned just past the summary.
+func CursorAfter(summary GameSummary) GameCursor {
+	return GameCursor{CreatedAt: summary.CreatedAt, ID: summary.ID}
+}
+
+// Precedes reports whether the summary comes after the cursor in listing
+// order: newest first, ties broken by ID.
+func (cursor GameCursor) Precedes(summary GameSummary) bool {
+	if !summary.CreatedAt.Equal(cursor.CreatedAt) {
+		return summary.CreatedAt.Before(cursor.CreatedAt)
+	}
+
+	return summary.ID > cursor.ID
+}
+
+// String encodes the cursor as an opaque token.
+func (cursor GameCursor) String() string {
+	raw := strconv.FormatInt(cursor.CreatedAt.UnixMilli(), 10) + ":" + cursor.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseGameCursor decodes a token made by GameCursor.String, reporting
+// ErrInvalidCursor if it is not one.
+func ParseGameCursor(token string) (GameCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return GameCursor{}, ErrInvalidCursor
+	}
+
+	rawCreatedAt, id, found := strings.Cut(string(raw), ":")
+	if !found || id == "" {
+		return GameCursor{}, ErrInvalidCursor
+	}
+
+	createdAt, err := strconv.ParseInt(rawCreatedAt, 10, 64)
+	if err != nil {
+		return GameCursor{}, ErrInvalidCursor
+	}
+
+	return GameCursor{CreatedAt: time.UnixMilli(createdAt).UTC(), ID: id}, nil
+}
+
+// eachGameSummary calls visit with every stored game matching the query's
+// filters, newest first, paging through the store. The query's cursor and
+// limit are ignored.
+func (service *Service) eachGameSummary(ctx context.Context, query GameQuery, visit func(GameSummary) error) error {
+	query.After = nil
+	query.Limit = scanBatchSize
+
+	for {
+		page, err := service.store.ListGames(ctx, query)
+		if err != nil {
+			return fmt.Errorf("listing games: %w", err)
+		}
+
+		for _, summary := range page.Games {
+			if err := visit(summary); err != nil {
+				return err
+			}
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+
+		cursor, err := ParseGameCursor(page.NextCursor)
+		if err != nil {
+			return fmt.Errorf("paging games: %w", err)
+		}
+		query.After = &cursor
+	}
+}
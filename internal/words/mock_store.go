@@ -5,8 +5,13 @@ import "context"
 // MockStore is a hand-written functional mock of Store for tests. A nil
 // function field panics to surface unexpected calls.
 type MockStore struct {
-	SaveGameFunc func(ctx context.Context, game *Game) error
-	GameByIDFunc func(ctx context.Context, gameID string) (*Game, error)
+	SaveGameFunc  func(ctx context.Context, game *Game) error
+	GameByIDFunc  func(ctx context.Context, gameID string) (*Game, error)
+	ListGamesFunc func(ctx context.Context, query GameQuery) (GamePage, error)
+
+	SaveShareLinkFunc    func(ctx context.Context, link ShareLink) error
+	ShareLinkByIDFunc    func(ctx context.Context, linkID string) (ShareLink, error)
+	ShareLinksByGameFunc func(ctx context.Context, gameID string) ([]ShareLink, error)
 }
 
 // SaveGame calls SaveGameFunc.
@@ -18,3 +23,23 @@ func (mock *MockStore) SaveGame(ctx context.Context, game *Game) error {
 func (mock *MockStore) GameByID(ctx context.Context, gameID string) (*Game, error) {
 	return mock.GameByIDFunc(ctx, gameID)
 }
+
+// ListGames calls ListGamesFunc.
+func (mock *MockStore) ListGames(ctx context.Context, query GameQuery) (GamePage, error) {
+	return mock.ListGamesFunc(ctx, query)
+}
+
+// SaveShareLink calls SaveShareLinkFunc.
+func (mock *MockStore) SaveShareLink(ctx context.Context, link ShareLink) error {
+	return mock.SaveShareLinkFunc(ctx, link)
+}
+
+// ShareLinkByID calls ShareLinkByIDFunc.
+func (mock *MockStore) ShareLinkByID(ctx context.Context, linkID string) (ShareLink, error) {
+	return mock.ShareLinkByIDFunc(ctx, linkID)
+}
+
+// ShareLinksByGame calls ShareLinksByGameFunc.
+func (mock *MockStore) ShareLinksByGame(ctx context.Context, gameID string) ([]ShareLink, error) {
+	return mock.ShareLinksByGameFunc(ctx, gameID)
+}
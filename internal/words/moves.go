@@ -0,0 +1,45 @@
+package words
+
+import "slices"
+
+// MoveType names a kind of entry in a game's move history.
+type MoveType string
+
+const (
+	// MoveTypePlay places a word.
+	MoveTypePlay MoveType = "PLAY"
+	// MoveTypeExchange swaps letters with the pool.
+	MoveTypeExchange MoveType = "EXCHANGE"
+	// MoveTypePass forfeits a turn.
+	MoveTypePass MoveType = "PASS"
+	// MoveTypeWithdrawn takes the last play back after an upheld challenge.
+	MoveTypeWithdrawn MoveType = "WITHDRAWN"
+	// MoveTypeEndRack adjusts a score for letters left on a rack at the end
+	// of the game: a penalty for the player holding them, or a bonus for the
+	// player who went out.
+	MoveTypeEndRack MoveType = "END_RACK"
+)
+
+// Move is one entry in a game's history. Rack is the player's rack before
+// the move, Letters the letters exchanged or, at the end of the game, left
+// on the rack scored, and Points what the move added to the player's score.
+type Move struct {
+	Type     MoveType         `json:"type"`
+	PlayerID string           `json:"playerId"`
+	Rack     []rune           `json:"rack,omitempty"`
+	Word     *PlacedWordState `json:"word,omitempty"`
+	Letters  []rune           `json:"letters,omitempty"`
+	Points   int              `json:"points"`
+}
+
+// Moves returns the game's history in play order. Games saved before moves
+// were recorded have none.
+func (game *Game) Moves() []Move {
+	return slices.Clone(game.moves)
+}
+
+func (game *Game) recordMove(move Move) {
+	move.Rack = slices.Clone(move.Rack)
+	move.Letters = slices.Clone(move.Letters)
+	game.moves = append(game.moves, move)
+}
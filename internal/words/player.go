@@ -1,9 +1,15 @@
 package words
 
 import (
-	"github.com/google/uuid"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// maxNameLength bounds a player's or spectator's display name, in
+// characters.
+const maxNameLength = 40
+
 // Player is a participant in a game, holding a rack of letters and a record
 // of scored turns.
 type Player struct {
@@ -21,13 +27,6 @@ type TurnRecord struct {
 	LettersDrawn int            `json:"lettersDrawn"`
 }
 
-func newPlayer(name string) Player {
-	return Player{
-		id:   uuid.NewString(),
-		name: name,
-	}
-}
-
 // ID returns the player's unique identifier.
 func (player Player) ID() string {
 	return player.id
@@ -101,18 +100,19 @@ func (player *Player) giveLetters(letters []rune) {
 	player.letters = append(player.letters, letters...)
 }
 
+// takeLetters removes the given letters from the rack, keeping the rest in
+// their original order.
 func (player *Player) takeLetters(letters []rune) {
-	remaining := letterCounts(player.letters)
-
-	for _, letter := range letters {
-		remaining[letter]--
-	}
+	taken := letterCounts(letters)
 
 	var newLetters []rune
-	for letter, count := range remaining {
-		for range count {
-			newLetters = append(newLetters, letter)
+	for _, letter := range player.letters {
+		if taken[letter] > 0 {
+			taken[letter]--
+			continue
 		}
+
+		newLetters = append(newLetters, letter)
 	}
 
 	player.letters = newLetters
@@ -126,3 +126,22 @@ func letterCounts(letters []rune) map[rune]int {
 
 	return counts
 }
+
+// validateName checks a display name chosen by a player or spectator. Names
+// must have visible text, fit within maxNameLength, and hold no control
+// characters that would garble rosters and transcripts.
+func validateName(name string) error {
+	if !utf8.ValidString(name) || strings.TrimSpace(name) == "" {
+		return ErrInvalidName
+	}
+
+	if utf8.RuneCountInString(name) > maxNameLength {
+		return ErrInvalidName
+	}
+
+	if strings.ContainsFunc(name, unicode.IsControl) {
+		return ErrInvalidName
+	}
+
+	return nil
+}
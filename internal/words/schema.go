@@ -0,0 +1,98 @@
+package words
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+)
+
+// CurrentSchema is the GameState schema this build writes. Changing the
+// snapshot format means bumping it and registering a migration from the
+// previous schema.
+const CurrentSchema = 1
+
+// migration upgrades a decoded snapshot by one schema, editing its fields in
+// place.
+type migration func(document map[string]json.RawMessage) error
+
+// migrations is the chain of upgrades between schemas: migrations[n] turns a
+// schema n snapshot into a schema n+1 one.
+var migrations = []migration{
+	0: migrateUnversioned,
+}
+
+// StateSchema returns the schema a serialized snapshot was written in.
+// Snapshots written before schemas existed are schema 0.
+func StateSchema(data []byte) (int, error) {
+	var header struct {
+		Schema int `json:"schema"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, fmt.Errorf("reading schema: %w", err)
+	}
+
+	return header.Schema, nil
+}
+
+// DecodeGameState decodes a serialized snapshot written in any schema up to
+// CurrentSchema, migrating it to the current one. A snapshot from a newer
+// schema is reported as ErrUnknownSchema.
+func DecodeGameState(data []byte) (GameState, error) {
+	schema, err := StateSchema(data)
+	if err != nil {
+		return GameState{}, err
+	}
+
+	if schema > CurrentSchema || schema < 0 {
+		return GameState{}, fmt.Errorf("decoding schema %d: %w", schema, ErrUnknownSchema)
+	}
+
+	if schema < CurrentSchema {
+		var document map[string]json.RawMessage
+		if err := json.Unmarshal(data, &document); err != nil {
+			return GameState{}, fmt.Errorf("decoding schema %d: %w", schema, err)
+		}
+
+		for ; schema < CurrentSchema; schema++ {
+			if err := migrations[schema](document); err != nil {
+				return GameState{}, fmt.Errorf("migrating schema %d: %w", schema, err)
+			}
+		}
+
+		document["schema"] = json.RawMessage(fmt.Sprint(CurrentSchema))
+
+		if data, err = json.Marshal(document); err != nil {
+			return GameState{}, fmt.Errorf("encoding schema %d: %w", schema, err)
+		}
+	}
+
+	var state GameState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return GameState{}, fmt.Errorf("decoding game state: %w", err)
+	}
+
+	return state, nil
+}
+
+// migrateUnversioned upgrades snapshots written before games had versions
+// or shuffle seeds. Any stored game has been saved at least once, so it
+// starts at version 1, and its seed is derived from its ID so every load
+// reshuffles the pool the same way.
+func migrateUnversioned(document map[string]json.RawMessage) error {
+	if _, ok := document["version"]; !ok {
+		document["version"] = json.RawMessage("1")
+	}
+
+	if _, ok := document["shuffleSeed"]; !ok {
+		var id string
+		if err := json.Unmarshal(document["id"], &id); err != nil {
+			return fmt.Errorf("reading id: %w", err)
+		}
+
+		seed := fnv.New64a()
+		seed.Write([]byte(id))
+		document["shuffleSeed"] = json.RawMessage(fmt.Sprint(seed.Sum64()))
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package words_test
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/carterjs/words/internal/words"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files from the current output")
+
+// TestDecodeGameState_Golden loads every snapshot under testdata/states,
+// each frozen in the format of the schema its name starts with, and compares
+// the game it rebuilds into with the .golden file beside it. Snapshots are
+// never edited once committed; a schema change adds new ones.
+func TestDecodeGameState_Golden(t *testing.T) {
+	t.Parallel()
+
+	paths, err := filepath.Glob(filepath.Join("testdata", "states", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			state, err := words.DecodeGameState(data)
+			require.NoError(t, err)
+
+			game, err := words.NewGameFromState(state)
+			require.NoError(t, err)
+
+			got, err := json.MarshalIndent(game.State(), "", "\t")
+			require.NoError(t, err)
+			got = append(got, '\n')
+
+			goldenPath := strings.TrimSuffix(path, ".json") + ".golden"
+			if *update {
+				require.NoError(t, os.WriteFile(goldenPath, got, 0o644))
+			}
+
+			want, err := os.ReadFile(goldenPath)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func TestDecodeGameState(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		data        string
+		wantVersion uint64
+		wantErr     error
+	}{
+		{name: "starts an unversioned snapshot at version 1", data: `{"id": "game"}`, wantVersion: 1},
+		{name: "keeps a current snapshot's version", data: `{"schema": 1, "id": "game", "version": 7}`, wantVersion: 7},
+		{name: "rejects a newer schema", data: `{"schema": 99, "id": "game"}`, wantErr: words.ErrUnknownSchema},
+		{name: "rejects a negative schema", data: `{"schema": -1, "id": "game"}`, wantErr: words.ErrUnknownSchema},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			state, err := words.DecodeGameState([]byte(test.data))
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, words.CurrentSchema, state.Schema)
+			assert.Equal(t, test.wantVersion, state.Version)
+		})
+	}
+}
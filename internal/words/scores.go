@@ -0,0 +1,153 @@
+package words
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ScoreAudit compares the points the game recorded with the points its
+// board implies.
+type ScoreAudit struct {
+	// Scores maps each player's ID to their score recomputed from the
+	// board, including the end-of-game adjustment if the game is over.
+	Scores map[string]int `json:"scores"`
+	// Discrepancies lists every turn, and every end-of-game adjustment,
+	// recorded with other points than recomputed.
+	Discrepancies []ScoreDiscrepancy `json:"discrepancies"`
+	// UnrecordedWords lists the words on the board no recorded turn placed.
+	UnrecordedWords []string `json:"unrecordedWords"`
+}
+
+// ScoreDiscrepancy is one recorded score that differs from the recomputed
+// one.
+type ScoreDiscrepancy struct {
+	PlayerID string `json:"playerId"`
+	// Turn is the 1-based position of the turn in the player's record, or
+	// zero for the end-of-game adjustment.
+	Turn int `json:"turn"`
+	// Word is the word the turn placed, or empty if it is not on the board.
+	Word       string `json:"word,omitempty"`
+	Recorded   int    `json:"recorded"`
+	Recomputed int    `json:"recomputed"`
+}
+
+// RecomputeScores replays the board's words in order onto an empty board
+// with the game's config, re-deriving each move's points, indirect words,
+// blanks and modifiers included, and matches each word to the recorded
+// turn that placed the same letters. A word that no longer places is
+// reported as ErrReplayDiverged.
+func (game *Game) RecomputeScores() (ScoreAudit, error) {
+	audit := ScoreAudit{Scores: make(map[string]int, len(game.players))}
+
+	type recordedTurn struct {
+		playerID string
+		turn     int
+		record   TurnRecord
+	}
+
+	var unmatched []recordedTurn
+	for _, player := range game.players {
+		audit.Scores[player.id] = 0
+		for index, record := range player.turns {
+			unmatched = append(unmatched, recordedTurn{playerID: player.id, turn: index + 1, record: record})
+		}
+	}
+
+	replayed := NewBoard(game.config)
+	for _, word := range game.board.words {
+		result, err := replayed.PlaceWord(word)
+		if err != nil {
+			return audit, fmt.Errorf("replaying word %q: %w: %w", word.String(), ErrReplayDiverged, err)
+		}
+
+		match := slices.IndexFunc(unmatched, func(turn recordedTurn) bool {
+			return maps.Equal(turn.record.LettersUsed, result.LettersUsed)
+		})
+		if match < 0 {
+			audit.UnrecordedWords = append(audit.UnrecordedWords, word.String())
+			continue
+		}
+
+		turn := unmatched[match]
+		unmatched = slices.Delete(unmatched, match, match+1)
+
+		audit.Scores[turn.playerID] += result.Points
+		if turn.record.Points != result.Points {
+			audit.Discrepancies = append(audit.Discrepancies, ScoreDiscrepancy{
+				PlayerID:   turn.playerID,
+				Turn:       turn.turn,
+				Word:       word.String(),
+				Recorded:   turn.record.Points,
+				Recomputed: result.Points,
+			})
+		}
+	}
+
+	for _, turn := range unmatched {
+		audit.Discrepancies = append(audit.Discrepancies, ScoreDiscrepancy{
+			PlayerID: turn.playerID,
+			Turn:     turn.turn,
+			Recorded: turn.record.Points,
+		})
+	}
+
+	for _, player := range game.players {
+		adjustment := game.recomputeFinalAdjustment(player)
+		audit.Scores[player.id] += adjustment
+
+		if player.finalAdjustment != adjustment {
+			audit.Discrepancies = append(audit.Discrepancies, ScoreDiscrepancy{
+				PlayerID:   player.id,
+				Recorded:   player.finalAdjustment,
+				Recomputed: adjustment,
+			})
+		}
+	}
+
+	slices.SortStableFunc(audit.Discrepancies, func(first, second ScoreDiscrepancy) int {
+		if first.PlayerID != second.PlayerID {
+			return game.playerIndex(first.PlayerID) - game.playerIndex(second.PlayerID)
+		}
+
+		return first.Turn - second.Turn
+	})
+
+	return audit, nil
+}
+
+// recomputeFinalAdjustment returns what finish gives the player: nothing
+// before the game is over, the value of their rack forfeited, or, for the
+// player whose last word emptied their rack and the pool, what everyone
+// else forfeited.
+func (game *Game) recomputeFinalAdjustment(player Player) int {
+	if !game.finished {
+		return 0
+	}
+
+	if len(player.letters) > 0 {
+		return -game.rackValue(player)
+	}
+
+	wentOut := game.lastWord != nil && game.lastWord.playerID == player.id && game.LettersRemaining() == 0
+	if !wentOut {
+		return 0
+	}
+
+	var forfeitTotal int
+	for _, other := range game.players {
+		forfeitTotal += game.rackValue(other)
+	}
+
+	return forfeitTotal
+}
+
+// rackValue sums the points of the letters on the player's rack.
+func (game *Game) rackValue(player Player) int {
+	var value int
+	for _, letter := range player.letters {
+		value += game.config.LetterPoints[letter]
+	}
+
+	return value
+}
@@ -0,0 +1,134 @@
+package words_test
+
+import (
+	"testing"
+
+	"github.com/carterjs/words/internal/pattern"
+	"github.com/carterjs/words/internal/words"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGame_RecomputeScores(t *testing.T) {
+	t.Parallel()
+
+	doubleWordConfig := testConfig(map[rune]int{'A': 16}, 3)
+	doubleWordConfig.Modifiers = pattern.Group[words.Modifier]{
+		{Value: words.ModifierDoubleWord, Grids: []pattern.Grid{{X: 1, Width: 100, Height: 100}}},
+	}
+
+	tests := []struct {
+		name              string
+		config            words.Config
+		play              func(t *testing.T, game *words.Game)
+		corrupt           func(state *words.GameState)
+		wantDiscrepancies func(players []words.Player) []words.ScoreDiscrepancy
+	}{
+		{
+			name:   "agrees with a game scored with modifiers and indirect words",
+			config: doubleWordConfig,
+			play: func(t *testing.T, game *words.Game) {
+				playCurrent(t, game, horizontal(0, 0, "AA"))
+				playCurrent(t, game, horizontal(0, 1, "AA"))
+			},
+		},
+		{
+			name:   "agrees with a game scored with blanks",
+			config: testConfig(map[rune]int{words.BlankLetter: 16}, 3),
+			play: func(t *testing.T, game *words.Game) {
+				playCurrent(t, game, horizontal(0, 0, "AA").WithBlanks(words.NewPoint(0, 0), words.NewPoint(1, 0)))
+			},
+		},
+		{
+			name:   "agrees with a finished game",
+			config: testConfig(map[rune]int{'A': 16}, 3),
+			play: func(t *testing.T, game *words.Game) {
+				playCurrent(t, game, horizontal(0, 0, "AA"))
+				for range 4 {
+					require.NoError(t, game.PassTurn(game.CurrentPlayerID()))
+				}
+			},
+		},
+		{
+			name:   "reports a misscored turn",
+			config: doubleWordConfig,
+			play: func(t *testing.T, game *words.Game) {
+				playCurrent(t, game, horizontal(0, 0, "AA"))
+				playCurrent(t, game, horizontal(0, 1, "AA"))
+			},
+			corrupt: func(state *words.GameState) {
+				state.Players[1].Turns[0].Points = 5
+			},
+			wantDiscrepancies: func(players []words.Player) []words.ScoreDiscrepancy {
+				return []words.ScoreDiscrepancy{
+					{PlayerID: players[1].ID(), Turn: 1, Word: "AA", Recorded: 5, Recomputed: 6},
+				}
+			},
+		},
+		{
+			name:   "reports points for a word not on the board",
+			config: testConfig(map[rune]int{'A': 16}, 3),
+			play: func(t *testing.T, game *words.Game) {
+				playCurrent(t, game, horizontal(0, 0, "AA"))
+			},
+			corrupt: func(state *words.GameState) {
+				state.Pool = append(state.Pool, 'A', 'A')
+				state.Words = nil
+			},
+			wantDiscrepancies: func(players []words.Player) []words.ScoreDiscrepancy {
+				return []words.ScoreDiscrepancy{
+					{PlayerID: players[0].ID(), Turn: 1, Recorded: 2},
+				}
+			},
+		},
+		{
+			name:   "reports a tampered final adjustment",
+			config: testConfig(map[rune]int{'A': 16}, 3),
+			play: func(t *testing.T, game *words.Game) {
+				for range 4 {
+					require.NoError(t, game.PassTurn(game.CurrentPlayerID()))
+				}
+			},
+			corrupt: func(state *words.GameState) {
+				state.Players[0].FinalAdjustment = 0
+			},
+			wantDiscrepancies: func(players []words.Player) []words.ScoreDiscrepancy {
+				return []words.ScoreDiscrepancy{
+					{PlayerID: players[0].ID(), Recomputed: -3},
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			game := newStartedGame(t, 2, test.config)
+			test.play(t, game)
+
+			if test.corrupt != nil {
+				state := game.State()
+				test.corrupt(&state)
+
+				corrupted, err := words.NewGameFromState(state)
+				require.NoError(t, err)
+				game = corrupted
+			}
+
+			audit, err := game.RecomputeScores()
+			require.NoError(t, err)
+			assert.Empty(t, audit.UnrecordedWords)
+
+			if test.wantDiscrepancies == nil {
+				assert.Empty(t, audit.Discrepancies)
+				for _, player := range game.Players() {
+					assert.Equal(t, player.Score(), audit.Scores[player.ID()])
+				}
+				return
+			}
+
+			assert.Equal(t, test.wantDiscrepancies(game.Players()), audit.Discrepancies)
+		})
+	}
+}
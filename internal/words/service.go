@@ -5,14 +5,30 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"sync"
+	"time"
 )
 
 // Store persists games between requests. Implementations translate their
 // own failures into this package's errors, notably ErrGameNotFound.
+//
+// SaveGame is a compare-and-swap: it stores the game only if the stored copy
+// is one version behind it, or absent for a game at version 1, and returns
+// ErrVersionConflict otherwise.
+//
+// ListGames returns one page of the games matching the query, newest first,
+// with a cursor to the next page if there is one.
+//
+// SaveShareLink adds a share link or replaces the one with its ID, and may
+// drop links whose expiry has passed; links are saved without their tokens.
+// ShareLinkByID reports a missing link as ErrShareLinkNotFound;
+// ShareLinksByGame returns a game's links in no particular order.
 type Store interface {
 	SaveGame(ctx context.Context, game *Game) error
 	GameByID(ctx context.Context, gameID string) (*Game, error)
+	ListGames(ctx context.Context, query GameQuery) (GamePage, error)
+	SaveShareLink(ctx context.Context, link ShareLink) error
+	ShareLinkByID(ctx context.Context, linkID string) (ShareLink, error)
+	ShareLinksByGame(ctx context.Context, gameID string) ([]ShareLink, error)
 }
 
 // Broker fans events out to game subscribers.
@@ -21,6 +37,13 @@ type Broker interface {
 	Subscribe(ctx context.Context, channels ...string) Subscription
 }
 
+// Locker serializes mutations of a game, possibly across processes. Lock
+// blocks until the game is held or the context ends and returns the function
+// releasing it.
+type Locker interface {
+	Lock(ctx context.Context, gameID string) (func(), error)
+}
+
 // Subscription is one subscriber's stream of game events.
 type Subscription interface {
 	Next(ctx context.Context) (Event, error)
@@ -31,21 +54,21 @@ type Subscription interface {
 // game mutation goes through it, so concurrent requests against the same
 // game are serialized.
 type Service struct {
-	store  Store
-	broker Broker
-	logger *slog.Logger
-
-	mutex     sync.Mutex
-	gameLocks map[string]*sync.Mutex
+	store       Store
+	broker      Broker
+	locker      Locker
+	logger      *slog.Logger
+	chatLimiter *chatLimiter
 }
 
-// NewService returns a service backed by the given store and broker.
-func NewService(store Store, broker Broker, logger *slog.Logger) *Service {
+// NewService returns a service backed by the given store, broker and locker.
+func NewService(store Store, broker Broker, locker Locker, logger *slog.Logger) *Service {
 	return &Service{
-		store:     store,
-		broker:    broker,
-		logger:    logger,
-		gameLocks: make(map[string]*sync.Mutex),
+		store:       store,
+		broker:      broker,
+		locker:      locker,
+		logger:      logger,
+		chatLimiter: newChatLimiter(),
 	}
 }
 
@@ -57,6 +80,7 @@ func (service *Service) CreateGame(ctx context.Context, presetID string, overrid
 	}
 
 	game := NewGame(configWithOverrides(preset.Config, overrides))
+	game.advanceVersion()
 
 	if err := service.store.SaveGame(ctx, game); err != nil {
 		return nil, fmt.Errorf("saving new game: %w", err)
@@ -75,11 +99,46 @@ func (service *Service) GameByID(ctx context.Context, gameID string) (*Game, err
 	return game, nil
 }
 
+// ListGames returns a page of the games matching the query. The page size
+// defaults to defaultListLimit and is capped at maxListLimit.
+func (service *Service) ListGames(ctx context.Context, query GameQuery) (GamePage, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultListLimit
+	}
+	query.Limit = min(query.Limit, maxListLimit)
+
+	page, err := service.store.ListGames(ctx, query)
+	if err != nil {
+		return GamePage{}, fmt.Errorf("listing games: %w", err)
+	}
+
+	return page, nil
+}
+
+// gameForUpdate loads a game about to be mutated, enforcing any version the
+// context expects.
+func (service *Service) gameForUpdate(ctx context.Context, gameID string) (*Game, error) {
+	game, err := service.GameByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkExpectedVersion(ctx, game); err != nil {
+		return nil, err
+	}
+
+	return game, nil
+}
+
 // JoinGame adds a player to the game and announces them to subscribers.
 func (service *Service) JoinGame(ctx context.Context, gameID, playerName string) (*Game, Player, error) {
-	defer service.lockGame(gameID)()
+	unlock, err := service.lockGame(ctx, gameID)
+	if err != nil {
+		return nil, Player{}, err
+	}
+	defer unlock()
 
-	game, err := service.GameByID(ctx, gameID)
+	game, err := service.gameForUpdate(ctx, gameID)
 	if err != nil {
 		return nil, Player{}, fmt.Errorf("joining game: %w", err)
 	}
@@ -89,23 +148,85 @@ func (service *Service) JoinGame(ctx context.Context, gameID, playerName string)
 		return nil, Player{}, fmt.Errorf("adding player: %w", err)
 	}
 
-	if err := service.store.SaveGame(ctx, game); err != nil {
-		return nil, Player{}, fmt.Errorf("saving game: %w", err)
-	}
-
-	service.publish(ctx, gameChannel(gameID), EventTypePlayerJoined, PlayerJoinedPayload{
+	service.record(game, gameChannel(gameID), EventTypePlayerJoined, PlayerJoinedPayload{
 		PlayerID:   player.ID(),
 		PlayerName: player.Name(),
 	})
 
+	if err := service.saveAndPublish(ctx, game); err != nil {
+		return nil, Player{}, fmt.Errorf("saving game: %w", err)
+	}
+
 	return game, player, nil
 }
 
+// SpectateGame adds a named spectator to the game and announces them to
+// subscribers.
+func (service *Service) SpectateGame(ctx context.Context, gameID, spectatorName string) (*Game, Spectator, error) {
+	unlock, err := service.lockGame(ctx, gameID)
+	if err != nil {
+		return nil, Spectator{}, err
+	}
+	defer unlock()
+
+	game, err := service.gameForUpdate(ctx, gameID)
+	if err != nil {
+		return nil, Spectator{}, fmt.Errorf("spectating game: %w", err)
+	}
+
+	spectator, err := game.AddSpectator(spectatorName)
+	if err != nil {
+		return nil, Spectator{}, fmt.Errorf("adding spectator: %w", err)
+	}
+
+	service.record(game, gameChannel(gameID), EventTypeSpectatorJoined, SpectatorJoinedPayload{
+		SpectatorID:   spectator.ID(),
+		SpectatorName: spectator.Name(),
+	})
+
+	if err := service.saveAndPublish(ctx, game); err != nil {
+		return nil, Spectator{}, fmt.Errorf("saving game: %w", err)
+	}
+
+	return game, spectator, nil
+}
+
+// LeaveGame stops the spectator watching the game, freeing their slot, and
+// tells subscribers they left.
+func (service *Service) LeaveGame(ctx context.Context, gameID, spectatorID string) (*Game, error) {
+	unlock, err := service.lockGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	game, err := service.gameForUpdate(ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("leaving game: %w", err)
+	}
+
+	if err := game.RemoveSpectator(spectatorID); err != nil {
+		return nil, fmt.Errorf("removing spectator: %w", err)
+	}
+
+	service.record(game, gameChannel(gameID), EventTypeSpectatorLeft, SpectatorLeftPayload{SpectatorID: spectatorID})
+
+	if err := service.saveAndPublish(ctx, game); err != nil {
+		return nil, fmt.Errorf("saving game: %w", err)
+	}
+
+	return game, nil
+}
+
 // StartGame starts the game and deals every player their opening rack.
 func (service *Service) StartGame(ctx context.Context, gameID string) (*Game, error) {
-	defer service.lockGame(gameID)()
+	unlock, err := service.lockGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	game, err := service.GameByID(ctx, gameID)
+	game, err := service.gameForUpdate(ctx, gameID)
 	if err != nil {
 		return nil, fmt.Errorf("loading game for turn: %w", err)
 	}
@@ -114,25 +235,29 @@ func (service *Service) StartGame(ctx context.Context, gameID string) (*Game, er
 		return nil, fmt.Errorf("starting game: %w", err)
 	}
 
-	if err := service.store.SaveGame(ctx, game); err != nil {
-		return nil, fmt.Errorf("saving game: %w", err)
-	}
-
-	service.publish(ctx, gameChannel(gameID), EventTypeGameStarted, GameStartedPayload{})
+	service.record(game, gameChannel(gameID), EventTypeGameStarted, GameStartedPayload{})
 	for _, player := range game.Players() {
-		service.publish(ctx, playerChannel(gameID, player.ID()), EventTypeGameStarted, GameStartedPayload{
+		service.record(game, playerChannel(gameID, player.ID()), EventTypeGameStarted, GameStartedPayload{
 			Letters: letterStrings(player.Letters()),
 		})
 	}
 
+	if err := service.saveAndPublish(ctx, game); err != nil {
+		return nil, fmt.Errorf("saving game: %w", err)
+	}
+
 	return game, nil
 }
 
 // PlayWord plays a word for the given player and broadcasts the result.
 func (service *Service) PlayWord(ctx context.Context, gameID, playerID string, word Word) (*Game, PlacementResult, error) {
-	defer service.lockGame(gameID)()
+	unlock, err := service.lockGame(ctx, gameID)
+	if err != nil {
+		return nil, PlacementResult{}, err
+	}
+	defer unlock()
 
-	game, err := service.GameByID(ctx, gameID)
+	game, err := service.gameForUpdate(ctx, gameID)
 	if err != nil {
 		return nil, PlacementResult{}, fmt.Errorf("loading game for play: %w", err)
 	}
@@ -142,12 +267,8 @@ func (service *Service) PlayWord(ctx context.Context, gameID, playerID string, w
 		return nil, PlacementResult{}, fmt.Errorf("playing word: %w", err)
 	}
 
-	if err := service.store.SaveGame(ctx, game); err != nil {
-		return nil, PlacementResult{}, fmt.Errorf("saving game: %w", err)
-	}
-
 	start := result.DirectWord.Start()
-	service.publish(ctx, gameChannel(gameID), EventTypeWordPlayed, WordPlayedPayload{
+	service.record(game, gameChannel(gameID), EventTypeWordPlayed, WordPlayedPayload{
 		PlayerID:     playerID,
 		X:            start.Column(),
 		Y:            start.Row(),
@@ -157,17 +278,25 @@ func (service *Service) PlayWord(ctx context.Context, gameID, playerID string, w
 		NextPlayerID: game.CurrentPlayerID(),
 		Round:        game.Round(),
 	})
-	service.publishRack(ctx, game, playerID)
-	service.publishGameEndedIfFinished(ctx, game)
+	service.recordRack(game, playerID)
+	service.recordGameEndedIfFinished(game)
+
+	if err := service.saveAndPublish(ctx, game); err != nil {
+		return nil, PlacementResult{}, fmt.Errorf("saving game: %w", err)
+	}
 
 	return game, result, nil
 }
 
 // PassTurn forfeits the given player's turn and broadcasts it.
 func (service *Service) PassTurn(ctx context.Context, gameID, playerID string) (*Game, error) {
-	defer service.lockGame(gameID)()
+	unlock, err := service.lockGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	game, err := service.GameByID(ctx, gameID)
+	game, err := service.gameForUpdate(ctx, gameID)
 	if err != nil {
 		return nil, fmt.Errorf("loading game for turn: %w", err)
 	}
@@ -176,16 +305,16 @@ func (service *Service) PassTurn(ctx context.Context, gameID, playerID string) (
 		return nil, fmt.Errorf("passing turn: %w", err)
 	}
 
-	if err := service.store.SaveGame(ctx, game); err != nil {
-		return nil, fmt.Errorf("saving game: %w", err)
-	}
-
-	service.publish(ctx, gameChannel(gameID), EventTypeTurnPassed, TurnPassedPayload{
+	service.record(game, gameChannel(gameID), EventTypeTurnPassed, TurnPassedPayload{
 		PlayerID:     playerID,
 		NextPlayerID: game.CurrentPlayerID(),
 		Round:        game.Round(),
 	})
-	service.publishGameEndedIfFinished(ctx, game)
+	service.recordGameEndedIfFinished(game)
+
+	if err := service.saveAndPublish(ctx, game); err != nil {
+		return nil, fmt.Errorf("saving game: %w", err)
+	}
 
 	return game, nil
 }
@@ -193,9 +322,13 @@ func (service *Service) PassTurn(ctx context.Context, gameID, playerID string) (
 // ExchangeLetters swaps letters for the given player and broadcasts the
 // exchange without revealing the letters.
 func (service *Service) ExchangeLetters(ctx context.Context, gameID, playerID string, letters []rune) (*Game, error) {
-	defer service.lockGame(gameID)()
+	unlock, err := service.lockGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	game, err := service.GameByID(ctx, gameID)
+	game, err := service.gameForUpdate(ctx, gameID)
 	if err != nil {
 		return nil, fmt.Errorf("loading game for turn: %w", err)
 	}
@@ -204,27 +337,31 @@ func (service *Service) ExchangeLetters(ctx context.Context, gameID, playerID st
 		return nil, fmt.Errorf("exchanging letters: %w", err)
 	}
 
-	if err := service.store.SaveGame(ctx, game); err != nil {
-		return nil, fmt.Errorf("saving game: %w", err)
-	}
-
-	service.publish(ctx, gameChannel(gameID), EventTypeLettersExchanged, LettersExchangedPayload{
+	service.record(game, gameChannel(gameID), EventTypeLettersExchanged, LettersExchangedPayload{
 		PlayerID:     playerID,
 		Count:        len(letters),
 		NextPlayerID: game.CurrentPlayerID(),
 		Round:        game.Round(),
 	})
-	service.publishRack(ctx, game, playerID)
-	service.publishGameEndedIfFinished(ctx, game)
+	service.recordRack(game, playerID)
+	service.recordGameEndedIfFinished(game)
+
+	if err := service.saveAndPublish(ctx, game); err != nil {
+		return nil, fmt.Errorf("saving game: %w", err)
+	}
 
 	return game, nil
 }
 
 // ChallengeWord opens a consensus vote against the last played word.
 func (service *Service) ChallengeWord(ctx context.Context, gameID, playerID string) (*Game, ChallengeOutcome, error) {
-	defer service.lockGame(gameID)()
+	unlock, err := service.lockGame(ctx, gameID)
+	if err != nil {
+		return nil, ChallengeOutcome{}, err
+	}
+	defer unlock()
 
-	game, err := service.GameByID(ctx, gameID)
+	game, err := service.gameForUpdate(ctx, gameID)
 	if err != nil {
 		return nil, ChallengeOutcome{}, fmt.Errorf("loading game for challenge: %w", err)
 	}
@@ -234,11 +371,7 @@ func (service *Service) ChallengeWord(ctx context.Context, gameID, playerID stri
 		return nil, ChallengeOutcome{}, fmt.Errorf("challenging word: %w", err)
 	}
 
-	if err := service.store.SaveGame(ctx, game); err != nil {
-		return nil, ChallengeOutcome{}, fmt.Errorf("saving game: %w", err)
-	}
-
-	service.publish(ctx, gameChannel(gameID), EventTypeChallengeStarted, ChallengeStartedPayload{
+	service.record(game, gameChannel(gameID), EventTypeChallengeStarted, ChallengeStartedPayload{
 		ChallengerID:   outcome.ChallengerID,
 		MoverID:        outcome.MoverID,
 		VotesInvalid:   outcome.VotesInvalid,
@@ -246,7 +379,11 @@ func (service *Service) ChallengeWord(ctx context.Context, gameID, playerID stri
 		VotesNeeded:    outcome.VotesNeeded,
 		EligibleVoters: outcome.EligibleVoters,
 	})
-	service.publishChallengeResolution(ctx, game, outcome)
+	service.recordChallengeResolution(game, outcome)
+
+	if err := service.saveAndPublish(ctx, game); err != nil {
+		return nil, ChallengeOutcome{}, fmt.Errorf("saving game: %w", err)
+	}
 
 	return game, outcome, nil
 }
@@ -254,9 +391,13 @@ func (service *Service) ChallengeWord(ctx context.Context, gameID, playerID stri
 // CastVote adds a player's vote to the open challenge and broadcasts the
 // tally, resolving the challenge once the outcome is decided.
 func (service *Service) CastVote(ctx context.Context, gameID, playerID string, vote Vote) (*Game, ChallengeOutcome, error) {
-	defer service.lockGame(gameID)()
+	unlock, err := service.lockGame(ctx, gameID)
+	if err != nil {
+		return nil, ChallengeOutcome{}, err
+	}
+	defer unlock()
 
-	game, err := service.GameByID(ctx, gameID)
+	game, err := service.gameForUpdate(ctx, gameID)
 	if err != nil {
 		return nil, ChallengeOutcome{}, fmt.Errorf("loading game for challenge: %w", err)
 	}
@@ -266,28 +407,155 @@ func (service *Service) CastVote(ctx context.Context, gameID, playerID string, v
 		return nil, ChallengeOutcome{}, fmt.Errorf("casting vote: %w", err)
 	}
 
-	if err := service.store.SaveGame(ctx, game); err != nil {
-		return nil, ChallengeOutcome{}, fmt.Errorf("saving game: %w", err)
-	}
-
-	service.publish(ctx, gameChannel(gameID), EventTypeChallengeVoteCast, ChallengeVoteCastPayload{
+	service.record(game, gameChannel(gameID), EventTypeChallengeVoteCast, ChallengeVoteCastPayload{
 		PlayerID:     playerID,
 		VotesInvalid: outcome.VotesInvalid,
 		VotesValid:   outcome.VotesValid,
 		VotesNeeded:  outcome.VotesNeeded,
 	})
-	service.publishChallengeResolution(ctx, game, outcome)
+	service.recordChallengeResolution(game, outcome)
+
+	if err := service.saveAndPublish(ctx, game); err != nil {
+		return nil, ChallengeOutcome{}, fmt.Errorf("saving game: %w", err)
+	}
 
 	return game, outcome, nil
 }
 
-// Subscribe returns the stream of events for a game, including the private
-// events of the given player when playerID is not empty.
-func (service *Service) Subscribe(ctx context.Context, gameID, playerID string) Subscription {
-	return service.broker.Subscribe(ctx, gameChannel(gameID), playerChannel(gameID, playerID))
+// SendMessage posts a chat message from a player or spectator. A message
+// with a recipient is whispered to that player alone.
+func (service *Service) SendMessage(ctx context.Context, gameID, senderID, recipientID, text string) (*Game, ChatMessage, error) {
+	unlock, err := service.lockGame(ctx, gameID)
+	if err != nil {
+		return nil, ChatMessage{}, err
+	}
+	defer unlock()
+
+	game, err := service.gameForUpdate(ctx, gameID)
+	if err != nil {
+		return nil, ChatMessage{}, fmt.Errorf("loading game for chat: %w", err)
+	}
+
+	now := time.Now()
+	limiterKey := gameID + ":" + senderID
+	if !service.chatLimiter.allow(limiterKey, now) {
+		return nil, ChatMessage{}, ErrRateLimited
+	}
+
+	message, err := game.AddMessage(senderID, recipientID, text, now)
+	if err != nil {
+		return nil, ChatMessage{}, fmt.Errorf("adding message: %w", err)
+	}
+
+	// only accepted messages count, so rejected ones don't spend the quota
+	service.chatLimiter.record(limiterKey, now)
+
+	payload := ChatMessagePayload{
+		ID:          message.ID,
+		SenderID:    message.SenderID,
+		SenderName:  message.SenderName,
+		RecipientID: message.RecipientID,
+		Text:        message.Text,
+		SentAt:      message.SentAt,
+	}
+
+	switch {
+	case !message.Whisper():
+		service.record(game, gameChannel(gameID), EventTypeChatMessage, payload)
+	default:
+		service.record(game, playerChannel(gameID, message.RecipientID), EventTypeChatMessage, payload)
+		if _, senderIsPlayer := game.PlayerByID(senderID); senderIsPlayer && senderID != message.RecipientID {
+			service.record(game, playerChannel(gameID, senderID), EventTypeChatMessage, payload)
+		}
+	}
+
+	if err := service.saveAndPublish(ctx, game); err != nil {
+		return nil, ChatMessage{}, fmt.Errorf("saving game: %w", err)
+	}
+
+	return game, message, nil
+}
+
+// MuteParticipant lets the host mute or unmute a player or spectator in the
+// game's chat.
+func (service *Service) MuteParticipant(ctx context.Context, gameID, hostID, participantID string, muted bool) (*Game, error) {
+	unlock, err := service.lockGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	game, err := service.gameForUpdate(ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("loading game for mute: %w", err)
+	}
+
+	if err := game.SetMuted(hostID, participantID, muted); err != nil {
+		return nil, fmt.Errorf("muting participant: %w", err)
+	}
+
+	service.record(game, gameChannel(gameID), EventTypeParticipantMuted, ParticipantMutedPayload{
+		ParticipantID: participantID,
+		Muted:         muted,
+	})
+
+	if err := service.saveAndPublish(ctx, game); err != nil {
+		return nil, fmt.Errorf("saving game: %w", err)
+	}
+
+	return game, nil
+}
+
+// Subscribe returns the stream of events for a game. A player of the game
+// also receives their private events. Anyone else, named spectator or not,
+// only ever receives public events, held back by the game's spectator delay.
+//
+// A subscriber resuming after lastEventID first receives the logged events
+// it missed and is then switched to live delivery without duplicates. Zero
+// means the subscriber has seen nothing and wants live events only.
+func (service *Service) Subscribe(ctx context.Context, gameID, playerID string, lastEventID uint64) (Subscription, error) {
+	game, err := service.GameByID(ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("subscribing to game: %w", err)
+	}
+
+	channels := []string{gameChannel(gameID)}
+	_, isPlayer := game.PlayerByID(playerID)
+	if isPlayer {
+		channels = append(channels, playerChannel(gameID, playerID))
+	}
+
+	var subscription Subscription = service.broker.Subscribe(ctx, channels...)
+
+	if lastEventID > 0 {
+		// reload after subscribing so nothing falls between the log and the
+		// live stream; duplicates are filtered by ID instead
+		game, err = service.GameByID(ctx, gameID)
+		if err != nil {
+			subscription.Close()
+			return nil, fmt.Errorf("loading game for replay: %w", err)
+		}
+
+		backlog := game.EventsAfter(lastEventID, channels...)
+		if !game.eventLogCovers(lastEventID) {
+			// the log was trimmed past the subscriber's position, or never
+			// reached it, so a replay would have a hole in it; have them
+			// reload instead, and deliver everything the reload won't show
+			backlog = []LoggedEvent{{Event: Event{Type: EventTypeResyncRequired}}}
+			lastEventID = game.LastEventID()
+		}
+
+		subscription = newReplayingSubscription(subscription, backlog, lastEventID)
+	}
+
+	if delay := game.Config().SpectatorDelay; delay > 0 && !isPlayer {
+		subscription = newDelayedSubscription(subscription, delay)
+	}
+
+	return subscription, nil
 }
 
-func (service *Service) publishChallengeResolution(ctx context.Context, game *Game, outcome ChallengeOutcome) {
+func (service *Service) recordChallengeResolution(game *Game, outcome ChallengeOutcome) {
 	if !outcome.Resolved {
 		return
 	}
@@ -303,14 +571,14 @@ func (service *Service) publishChallengeResolution(ctx context.Context, game *Ga
 		payload.RescindedWord = string(outcome.RescindedWord.Letters())
 	}
 
-	service.publish(ctx, gameChannel(game.ID()), EventTypeChallengeResolved, payload)
+	service.record(game, gameChannel(game.ID()), EventTypeChallengeResolved, payload)
 
 	if outcome.Upheld {
-		service.publishRack(ctx, game, outcome.MoverID)
+		service.recordRack(game, outcome.MoverID)
 	}
 }
 
-func (service *Service) publishGameEndedIfFinished(ctx context.Context, game *Game) {
+func (service *Service) recordGameEndedIfFinished(game *Game) {
 	if !game.Finished() {
 		return
 	}
@@ -320,45 +588,58 @@ func (service *Service) publishGameEndedIfFinished(ctx context.Context, game *Ga
 		scores[player.ID()] = player.Score()
 	}
 
-	service.publish(ctx, gameChannel(game.ID()), EventTypeGameEnded, GameEndedPayload{
+	service.record(game, gameChannel(game.ID()), EventTypeGameEnded, GameEndedPayload{
 		WinnerIDs: game.WinnerIDs(),
 		Scores:    scores,
 	})
 }
 
-func (service *Service) publishRack(ctx context.Context, game *Game, playerID string) {
+func (service *Service) recordRack(game *Game, playerID string) {
 	player, exists := game.PlayerByID(playerID)
 	if !exists {
 		return
 	}
 
-	service.publish(ctx, playerChannel(game.ID(), playerID), EventTypeRackUpdated, RackUpdatedPayload{
+	service.record(game, playerChannel(game.ID(), playerID), EventTypeRackUpdated, RackUpdatedPayload{
 		Letters: letterStrings(player.Letters()),
 	})
 }
 
-func (service *Service) publish(ctx context.Context, channel string, eventType EventType, payload any) {
+// record appends an event to the game's log. It is published by
+// saveAndPublish once the game, log included, is saved.
+func (service *Service) record(game *Game, channel string, eventType EventType, payload any) {
 	data, err := json.Marshal(payload)
 	if err != nil {
 		service.logger.Error("marshaling event payload", "type", eventType, "error", err)
 		return
 	}
 
-	service.broker.Publish(ctx, channel, Event{Type: eventType, Payload: data})
+	game.recordEvent(channel, Event{Type: eventType, Payload: data}, time.Now())
+}
+
+// saveAndPublish saves the game and then publishes the events recorded
+// against it, so subscribers never hear about a change that was not kept.
+func (service *Service) saveAndPublish(ctx context.Context, game *Game) error {
+	game.advanceVersion()
+	if err := service.store.SaveGame(ctx, game); err != nil {
+		return err
+	}
+
+	for _, logged := range game.takeUnpublished() {
+		service.broker.Publish(ctx, logged.Channel, logged.Event)
+	}
+
+	return nil
 }
 
 // lockGame serializes mutations per game and returns the unlock function.
-func (service *Service) lockGame(gameID string) func() {
-	service.mutex.Lock()
-	lock, exists := service.gameLocks[gameID]
-	if !exists {
-		lock = &sync.Mutex{}
-		service.gameLocks[gameID] = lock
+func (service *Service) lockGame(ctx context.Context, gameID string) (func(), error) {
+	unlock, err := service.locker.Lock(ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("locking game: %w", err)
 	}
-	service.mutex.Unlock()
 
-	lock.Lock()
-	return lock.Unlock
+	return unlock, nil
 }
 
 func gameChannel(gameID string) string {
@@ -2,9 +2,13 @@ package words_test
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
 	"testing"
+	"time"
 
+	"github.com/carterjs/words/internal/lock"
+	"github.com/carterjs/words/internal/pubsub"
 	"github.com/carterjs/words/internal/words"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -48,12 +52,47 @@ func TestService_CreateGame(t *testing.T) {
 	}
 }
 
+func TestService_ListGames(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		limit     int
+		wantLimit int
+	}{
+		{name: "defaults the page size", wantLimit: 20},
+		{name: "keeps a page size within bounds", limit: 5, wantLimit: 5},
+		{name: "caps the page size", limit: 500, wantLimit: 100},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var requested words.GameQuery
+			service := newTestService(&words.MockStore{
+				ListGamesFunc: func(ctx context.Context, query words.GameQuery) (words.GamePage, error) {
+					requested = query
+					return words.GamePage{}, nil
+				},
+			}, &words.MockBroker{})
+
+			_, err := service.ListGames(t.Context(), words.GameQuery{Limit: test.limit})
+
+			require.NoError(t, err)
+			assert.Equal(t, test.wantLimit, requested.Limit)
+		})
+	}
+}
+
 func TestService_PlayWord(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
 		name           string
 		outOfTurn      bool
+		expectVersion  bool
+		staleVersion   bool
 		wantErr        error
 		wantEventTypes []words.EventType
 	}{
@@ -66,6 +105,17 @@ func TestService_PlayWord(t *testing.T) {
 			outOfTurn: true,
 			wantErr:   words.ErrNotYourTurn,
 		},
+		{
+			name:           "plays against the expected version",
+			expectVersion:  true,
+			wantEventTypes: []words.EventType{words.EventTypeWordPlayed, words.EventTypeRackUpdated},
+		},
+		{
+			name:          "rejects a play against a stale version",
+			expectVersion: true,
+			staleVersion:  true,
+			wantErr:       words.ErrVersionMismatch,
+		},
 	}
 
 	for _, test := range tests {
@@ -80,7 +130,17 @@ func TestService_PlayWord(t *testing.T) {
 				playerID = game.Players()[1].ID()
 			}
 
-			_, result, err := service.PlayWord(t.Context(), game.ID(), playerID, horizontal(0, 0, "AA"))
+			ctx := t.Context()
+			startVersion := game.Version()
+			if test.expectVersion {
+				expected := startVersion
+				if test.staleVersion {
+					expected++
+				}
+				ctx = words.WithExpectedVersion(ctx, expected)
+			}
+
+			_, result, err := service.PlayWord(ctx, game.ID(), playerID, horizontal(0, 0, "AA"))
 
 			if test.wantErr != nil {
 				assert.ErrorIs(t, err, test.wantErr)
@@ -91,12 +151,65 @@ func TestService_PlayWord(t *testing.T) {
 			require.NoError(t, err)
 			assert.Equal(t, 2, result.Points)
 			assert.Equal(t, test.wantEventTypes, *published)
+			assert.Equal(t, startVersion+1, game.Version())
+		})
+	}
+}
+
+func TestService_SendMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		whisper      bool
+		rejected     int
+		messages     int
+		wantErr      error
+		wantChannels int
+	}{
+		{name: "broadcasts a public message on the game channel", messages: 1, wantChannels: 1},
+		{name: "whispers on both players' private channels", whisper: true, messages: 1, wantChannels: 2},
+		{name: "rate limits a chatty sender", messages: 6, wantErr: words.ErrRateLimited},
+		{name: "does not count rejected messages", rejected: 6, messages: 5, wantChannels: 5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			game := newLobbyGame(t, 2, testConfig(map[rune]int{'A': 20}, 3))
+			service, published := newGameService(game)
+
+			senderID := game.Players()[0].ID()
+			recipientID := ""
+			if test.whisper {
+				recipientID = game.Players()[1].ID()
+			}
+
+			for range test.rejected {
+				_, _, err := service.SendMessage(t.Context(), game.ID(), senderID, recipientID, " ")
+				require.ErrorIs(t, err, words.ErrEmptyMessage)
+			}
+
+			var err error
+			for range test.messages {
+				_, _, err = service.SendMessage(t.Context(), game.ID(), senderID, recipientID, "hello")
+			}
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, *published, test.wantChannels)
+			assert.Len(t, game.MessagesFor(senderID), test.messages)
 		})
 	}
 }
 
 func newTestService(store *words.MockStore, broker *words.MockBroker) *words.Service {
-	return words.NewService(store, broker, slog.New(slog.DiscardHandler))
+	return words.NewService(store, broker, lock.NewLocal(), slog.New(slog.DiscardHandler))
 }
 
 // newGameService wires a service around one in-memory game, recording the
@@ -118,3 +231,147 @@ func newGameService(game *words.Game) (*words.Service, *[]words.EventType) {
 
 	return service, published
 }
+
+func TestService_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		subscriber     string
+		delay          time.Duration
+		resumeAfter    uint64
+		passAfter      bool
+		wantEventTypes []words.EventType
+	}{
+		{
+			name:       "delivers a player's private events",
+			subscriber: "player",
+			wantEventTypes: []words.EventType{
+				words.EventTypeGameStarted, words.EventTypeGameStarted,
+				words.EventTypeWordPlayed, words.EventTypeRackUpdated,
+			},
+		},
+		{
+			name:           "withholds private events from a named spectator",
+			subscriber:     "spectator",
+			wantEventTypes: []words.EventType{words.EventTypeGameStarted, words.EventTypeWordPlayed},
+		},
+		{
+			name:           "withholds private events from an unknown player ID",
+			subscriber:     "impostor",
+			wantEventTypes: []words.EventType{words.EventTypeGameStarted, words.EventTypeWordPlayed},
+		},
+		{
+			name:        "replays a player's missed events on resume",
+			subscriber:  "player",
+			resumeAfter: 1,
+			wantEventTypes: []words.EventType{
+				words.EventTypeGameStarted, words.EventTypeGameStarted,
+				words.EventTypeWordPlayed, words.EventTypeRackUpdated,
+			},
+		},
+		{
+			name:           "replays only public events to a resuming spectator",
+			subscriber:     "spectator",
+			resumeAfter:    1,
+			wantEventTypes: []words.EventType{words.EventTypeGameStarted, words.EventTypeWordPlayed},
+		},
+		{
+			name:        "asks a subscriber resuming past the log to resync",
+			subscriber:  "player",
+			resumeAfter: 1000,
+			passAfter:   true,
+			wantEventTypes: []words.EventType{
+				words.EventTypeResyncRequired, words.EventTypeTurnPassed,
+			},
+		},
+		{
+			name:           "delays public events for spectators",
+			subscriber:     "spectator",
+			delay:          50 * time.Millisecond,
+			wantEventTypes: []words.EventType{words.EventTypeGameStarted, words.EventTypeWordPlayed},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := testConfig(map[rune]int{'A': 20}, 3)
+			config.SpectatorDelay = test.delay
+			game := newLobbyGame(t, 2, config)
+			service := newBrokeredService(game)
+
+			_, spectator, err := service.SpectateGame(t.Context(), game.ID(), "watcher")
+			require.NoError(t, err)
+
+			subscriberID := map[string]string{
+				"player":    game.Players()[0].ID(),
+				"spectator": spectator.ID(),
+				"impostor":  "not-a-player",
+			}[test.subscriber]
+
+			play := func() {
+				_, err := service.StartGame(t.Context(), game.ID())
+				require.NoError(t, err)
+				_, _, err = service.PlayWord(t.Context(), game.ID(), game.CurrentPlayerID(), horizontal(0, 0, "AA"))
+				require.NoError(t, err)
+			}
+
+			// a resuming subscriber connects only after the play it missed
+			if test.resumeAfter > 0 {
+				play()
+			}
+
+			subscription, err := service.Subscribe(t.Context(), game.ID(), subscriberID, test.resumeAfter)
+			require.NoError(t, err)
+			defer subscription.Close()
+
+			start := time.Now()
+
+			if test.resumeAfter == 0 {
+				play()
+			}
+
+			if test.passAfter {
+				_, err := service.PassTurn(t.Context(), game.ID(), game.CurrentPlayerID())
+				require.NoError(t, err)
+			}
+
+			var received []words.EventType
+			for {
+				ctx, cancel := context.WithTimeout(t.Context(), test.delay+100*time.Millisecond)
+				event, err := subscription.Next(ctx)
+				cancel()
+				if err != nil {
+					break
+				}
+
+				received = append(received, event.Type)
+
+				if event.Type == words.EventTypeGameStarted && test.subscriber != "player" {
+					var payload words.GameStartedPayload
+					require.NoError(t, json.Unmarshal(event.Payload, &payload))
+					assert.Empty(t, payload.Letters)
+				}
+			}
+
+			assert.ElementsMatch(t, test.wantEventTypes, received)
+			assert.GreaterOrEqual(t, time.Since(start), test.delay)
+		})
+	}
+}
+
+// newBrokeredService wires a service around one in-memory game and a real
+// in-process broker, so tests observe exactly what subscribers receive.
+func newBrokeredService(game *words.Game) *words.Service {
+	return words.NewService(
+		&words.MockStore{
+			GameByIDFunc: func(ctx context.Context, gameID string) (*words.Game, error) { return game, nil },
+			SaveGameFunc: func(ctx context.Context, game *words.Game) error { return nil },
+		},
+		pubsub.NewGameBroker(pubsub.Config{}),
+		lock.NewLocal(),
+		slog.New(slog.DiscardHandler),
+	)
+}
@@ -0,0 +1,173 @@
+package words
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	// DefaultShareLinkLifetime is how long a share link lasts when its
+	// creator does not say.
+	DefaultShareLinkLifetime = 7 * 24 * time.Hour
+	// MaxShareLinkLifetime is the longest a share link may last.
+	MaxShareLinkLifetime = 30 * 24 * time.Hour
+)
+
+// ShareLink grants whoever holds its token a read-only view of one game:
+// its board, scores and history, never racks or anyone's identity. Links
+// expire, and any player of the game can revoke them sooner. A link is
+// known by its ID, a hash of the token, so stores and listings never hold
+// the token itself; only the link CreateShareLink returns carries it.
+type ShareLink struct {
+	ID        string    `json:"id"`
+	Token     string    `json:"token,omitempty"`
+	GameID    string    `json:"gameId"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Revoked   bool      `json:"revoked,omitempty"`
+}
+
+// Active reports whether the link still grants access at the given time.
+func (link ShareLink) Active(now time.Time) bool {
+	return !link.Revoked && now.Before(link.ExpiresAt)
+}
+
+// ShareLinkID returns the ID of the link the token opens.
+func ShareLinkID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateShareLink mints a link to the game for one of its players, lasting
+// the given lifetime, or DefaultShareLinkLifetime if it is zero. Lifetimes
+// beyond MaxShareLinkLifetime are refused with ErrInvalidShareLinkLifetime.
+func (service *Service) CreateShareLink(ctx context.Context, gameID, playerID string, lifetime time.Duration) (ShareLink, error) {
+	if lifetime == 0 {
+		lifetime = DefaultShareLinkLifetime
+	}
+	if lifetime < 0 || lifetime > MaxShareLinkLifetime {
+		return ShareLink{}, ErrInvalidShareLinkLifetime
+	}
+
+	if err := service.requirePlayer(ctx, gameID, playerID); err != nil {
+		return ShareLink{}, err
+	}
+
+	token := rand.Text()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	link := ShareLink{
+		ID:        ShareLinkID(token),
+		GameID:    gameID,
+		CreatedBy: playerID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(lifetime),
+	}
+
+	if err := service.store.SaveShareLink(ctx, link); err != nil {
+		return ShareLink{}, fmt.Errorf("saving share link: %w", err)
+	}
+
+	link.Token = token
+	return link, nil
+}
+
+// ShareLinks returns every link to the game, oldest first, for one of its
+// players. Expired and revoked links are included until the store drops
+// them. Their tokens are not included.
+func (service *Service) ShareLinks(ctx context.Context, gameID, playerID string) ([]ShareLink, error) {
+	if err := service.requirePlayer(ctx, gameID, playerID); err != nil {
+		return nil, err
+	}
+
+	links, err := service.store.ShareLinksByGame(ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("listing share links: %w", err)
+	}
+
+	slices.SortFunc(links, func(first, second ShareLink) int {
+		return first.CreatedAt.Compare(second.CreatedAt)
+	})
+
+	return links, nil
+}
+
+// RevokeShareLink stops the game's link with the given ID from granting
+// access, on behalf of one of the game's players. Links to other games are
+// reported as ErrShareLinkNotFound.
+func (service *Service) RevokeShareLink(ctx context.Context, gameID, playerID, linkID string) error {
+	if err := service.requirePlayer(ctx, gameID, playerID); err != nil {
+		return err
+	}
+
+	link, err := service.store.ShareLinkByID(ctx, linkID)
+	if err != nil {
+		return fmt.Errorf("loading share link: %w", err)
+	}
+	if link.GameID != gameID {
+		return ErrShareLinkNotFound
+	}
+
+	link.Revoked = true
+	if err := service.store.SaveShareLink(ctx, link); err != nil {
+		return fmt.Errorf("saving share link: %w", err)
+	}
+
+	return nil
+}
+
+// SharedGame returns the game an active share link opens. Unknown, expired
+// and revoked tokens are all reported as ErrShareLinkNotFound, so a token
+// reveals nothing once it stops working. Callers must show only what the
+// link grants.
+func (service *Service) SharedGame(ctx context.Context, token string) (*Game, error) {
+	link, err := service.store.ShareLinkByID(ctx, ShareLinkID(token))
+	if err != nil {
+		return nil, fmt.Errorf("loading share link: %w", err)
+	}
+
+	if !link.Active(time.Now()) {
+		return nil, ErrShareLinkNotFound
+	}
+
+	return service.GameByID(ctx, link.GameID)
+}
+
+// SubscribeShared returns the event stream an active share link opens,
+// along with its game as of subscribing. The link's holder is subscribed as
+// an anonymous spectator: public events only, held back by the game's
+// spectator delay. The link is checked when the stream opens, and
+// lastEventID resumes it as in Subscribe.
+func (service *Service) SubscribeShared(ctx context.Context, token string, lastEventID uint64) (*Game, Subscription, error) {
+	game, err := service.SharedGame(ctx, token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subscription, err := service.Subscribe(ctx, game.ID(), "", lastEventID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return game, subscription, nil
+}
+
+// requirePlayer reports ErrPlayerNotFound unless the player is part of the
+// game.
+func (service *Service) requirePlayer(ctx context.Context, gameID, playerID string) error {
+	game, err := service.GameByID(ctx, gameID)
+	if err != nil {
+		return err
+	}
+
+	if _, isPlayer := game.PlayerByID(playerID); !isPlayer {
+		return ErrPlayerNotFound
+	}
+
+	return nil
+}
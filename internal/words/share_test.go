@@ -0,0 +1,203 @@
+package words_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/carterjs/words/internal/lock"
+	"github.com/carterjs/words/internal/pubsub"
+	"github.com/carterjs/words/internal/store"
+	"github.com/carterjs/words/internal/words"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_CreateShareLink(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		lifetime     time.Duration
+		stranger     bool
+		wantLifetime time.Duration
+		wantErr      error
+	}{
+		{name: "lasts the default lifetime", wantLifetime: words.DefaultShareLinkLifetime},
+		{name: "lasts the lifetime asked for", lifetime: time.Hour, wantLifetime: time.Hour},
+		{name: "refuses a lifetime over the limit", lifetime: words.MaxShareLinkLifetime + time.Second, wantErr: words.ErrInvalidShareLinkLifetime},
+		{name: "refuses a negative lifetime", lifetime: -time.Hour, wantErr: words.ErrInvalidShareLinkLifetime},
+		{name: "refuses someone not playing", stranger: true, wantErr: words.ErrPlayerNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, _ := newSharingService(t)
+			game, player := newSharedGame(t, service)
+
+			playerID := player.ID()
+			if test.stranger {
+				playerID = "stranger"
+			}
+
+			link, err := service.CreateShareLink(t.Context(), game.ID(), playerID, test.lifetime)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.NotEmpty(t, link.Token)
+			assert.Equal(t, words.ShareLinkID(link.Token), link.ID)
+			assert.Equal(t, game.ID(), link.GameID)
+			assert.Equal(t, player.ID(), link.CreatedBy)
+			assert.Equal(t, test.wantLifetime, link.ExpiresAt.Sub(link.CreatedAt))
+
+			links, err := service.ShareLinks(t.Context(), game.ID(), player.ID())
+			require.NoError(t, err)
+			stored := link
+			stored.Token = ""
+			assert.Equal(t, []words.ShareLink{stored}, links, "links are stored without their tokens")
+		})
+	}
+}
+
+func TestService_SharedGame(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		change  func(t *testing.T, service *words.Service, gameStore words.Store, link words.ShareLink) string
+		wantErr error
+	}{
+		{
+			name: "opens the game of an active link",
+			change: func(*testing.T, *words.Service, words.Store, words.ShareLink) string {
+				return ""
+			},
+		},
+		{
+			name: "refuses a revoked link",
+			change: func(t *testing.T, service *words.Service, _ words.Store, link words.ShareLink) string {
+				require.NoError(t, service.RevokeShareLink(t.Context(), link.GameID, link.CreatedBy, link.ID))
+				return ""
+			},
+			wantErr: words.ErrShareLinkNotFound,
+		},
+		{
+			name: "refuses an expired link",
+			change: func(t *testing.T, _ *words.Service, gameStore words.Store, link words.ShareLink) string {
+				link.ExpiresAt = time.Now().Add(-time.Second)
+				require.NoError(t, gameStore.SaveShareLink(t.Context(), link))
+				return ""
+			},
+			wantErr: words.ErrShareLinkNotFound,
+		},
+		{
+			name: "refuses an unknown token",
+			change: func(*testing.T, *words.Service, words.Store, words.ShareLink) string {
+				return "unknown"
+			},
+			wantErr: words.ErrShareLinkNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, gameStore := newSharingService(t)
+			game, player := newSharedGame(t, service)
+
+			link, err := service.CreateShareLink(t.Context(), game.ID(), player.ID(), 0)
+			require.NoError(t, err)
+
+			token := link.Token
+			if replaced := test.change(t, service, gameStore, link); replaced != "" {
+				token = replaced
+			}
+
+			shared, err := service.SharedGame(t.Context(), token)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, game.ID(), shared.ID())
+		})
+	}
+}
+
+func TestService_RevokeShareLink(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		otherGame bool
+		stranger  bool
+		wantErr   error
+	}{
+		{name: "revokes a link to the game"},
+		{name: "refuses a link to another game", otherGame: true, wantErr: words.ErrShareLinkNotFound},
+		{name: "refuses someone not playing", stranger: true, wantErr: words.ErrPlayerNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, _ := newSharingService(t)
+			game, player := newSharedGame(t, service)
+
+			link, err := service.CreateShareLink(t.Context(), game.ID(), player.ID(), 0)
+			require.NoError(t, err)
+
+			gameID, playerID := game.ID(), player.ID()
+			if test.otherGame {
+				other, otherPlayer := newSharedGame(t, service)
+				gameID, playerID = other.ID(), otherPlayer.ID()
+			}
+			if test.stranger {
+				playerID = "stranger"
+			}
+
+			err = service.RevokeShareLink(t.Context(), gameID, playerID, link.ID)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+
+				_, err := service.SharedGame(t.Context(), link.Token)
+				assert.NoError(t, err, "a refused revocation leaves the link working")
+				return
+			}
+			require.NoError(t, err)
+
+			links, err := service.ShareLinks(t.Context(), game.ID(), player.ID())
+			require.NoError(t, err)
+			require.Len(t, links, 1)
+			assert.True(t, links[0].Revoked)
+		})
+	}
+}
+
+func newSharingService(t *testing.T) (*words.Service, words.Store) {
+	t.Helper()
+
+	logger := slog.New(slog.DiscardHandler)
+	gameStore := store.NewFS(t.TempDir(), logger)
+
+	return words.NewService(gameStore, pubsub.NewGameBroker(pubsub.Config{}), lock.NewLocal(), logger), gameStore
+}
+
+func newSharedGame(t *testing.T, service *words.Service) (*words.Game, words.Player) {
+	t.Helper()
+
+	game, err := service.CreateGame(t.Context(), "standard", words.ConfigOverrides{})
+	require.NoError(t, err)
+
+	game, player, err := service.JoinGame(t.Context(), game.ID(), "player-0")
+	require.NoError(t, err)
+
+	return game, player
+}
@@ -0,0 +1,187 @@
+package words
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxSpectators bounds how many spectators can watch a single game at once;
+// a spectator who leaves frees their slot.
+const maxSpectators = 100
+
+// Spectator is someone watching a game without playing in it. Spectators
+// only ever see the game's public events.
+type Spectator struct {
+	id   string
+	name string
+}
+
+// ID returns the spectator's unique identifier.
+func (spectator Spectator) ID() string {
+	return spectator.id
+}
+
+// Name returns the spectator's display name.
+func (spectator Spectator) Name() string {
+	return spectator.name
+}
+
+// AddSpectator adds a spectator to the game and returns them. Unlike
+// players, spectators may join at any point, including after the game ends,
+// but only maxSpectators may watch at once: until some leave with
+// RemoveSpectator, further spectators are refused with
+// ErrTooManySpectators. Names are validated as players' are.
+func (game *Game) AddSpectator(name string) (Spectator, error) {
+	if err := validateName(name); err != nil {
+		return Spectator{}, err
+	}
+
+	if len(game.spectators) >= maxSpectators {
+		return Spectator{}, ErrTooManySpectators
+	}
+
+	return game.addSpectator(uuid.NewString(), name), nil
+}
+
+func (game *Game) addSpectator(spectatorID, name string) Spectator {
+	spectator := Spectator{id: spectatorID, name: name}
+	game.spectators = append(game.spectators, spectator)
+
+	game.recordAction(Action{Type: ActionTypeSpectate, PlayerID: spectatorID, Name: name})
+
+	return spectator
+}
+
+// RemoveSpectator stops the spectator watching the game, freeing their slot.
+// Anyone not spectating is reported as ErrNotParticipant.
+func (game *Game) RemoveSpectator(spectatorID string) error {
+	index := slices.IndexFunc(game.spectators, func(spectator Spectator) bool {
+		return spectator.id == spectatorID
+	})
+	if index < 0 {
+		return ErrNotParticipant
+	}
+
+	game.spectators = slices.Delete(game.spectators, index, index+1)
+
+	game.recordAction(Action{Type: ActionTypeLeave, PlayerID: spectatorID})
+
+	return nil
+}
+
+// Spectators returns the game's spectators in the order they joined.
+func (game *Game) Spectators() []Spectator {
+	spectators := make([]Spectator, len(game.spectators))
+	copy(spectators, game.spectators)
+	return spectators
+}
+
+// SpectatorByID returns the spectator with the given ID and whether they are
+// watching the game.
+func (game *Game) SpectatorByID(spectatorID string) (Spectator, bool) {
+	for _, spectator := range game.spectators {
+		if spectator.id == spectatorID {
+			return spectator, true
+		}
+	}
+
+	return Spectator{}, false
+}
+
+// delayedSubscription holds each event back until the delay has passed since
+// it was published, so spectators cannot relay moves to players in real
+// time.
+type delayedSubscription struct {
+	subscription Subscription
+	delay        time.Duration
+	events       chan timedEvent
+	cancel       context.CancelFunc
+
+	// pending is an event already taken from events whose delay had not
+	// elapsed when the caller's context ended.
+	pending *timedEvent
+	err     error
+}
+
+type timedEvent struct {
+	event      Event
+	receivedAt time.Time
+}
+
+// delayedEventBuffer bounds how many received events a delayed subscription
+// holds before it stops reading from the broker, leaving any further backlog
+// to the broker's own buffering.
+const delayedEventBuffer = 64
+
+func newDelayedSubscription(subscription Subscription, delay time.Duration) *delayedSubscription {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	delayed := &delayedSubscription{
+		subscription: subscription,
+		delay:        delay,
+		events:       make(chan timedEvent, delayedEventBuffer),
+		cancel:       cancel,
+	}
+
+	go delayed.receive(ctx)
+
+	return delayed
+}
+
+// receive stamps events as they arrive so the delay runs from publication
+// rather than from when the caller gets around to reading them.
+func (delayed *delayedSubscription) receive(ctx context.Context) {
+	defer close(delayed.events)
+
+	for {
+		event, err := delayed.subscription.Next(ctx)
+		if err != nil {
+			delayed.err = err
+			return
+		}
+
+		select {
+		case delayed.events <- timedEvent{event: event, receivedAt: time.Now()}:
+		case <-ctx.Done():
+			delayed.err = ctx.Err()
+			return
+		}
+	}
+}
+
+// Next blocks until an event's delay has elapsed, the subscription ends, or
+// the context ends.
+func (delayed *delayedSubscription) Next(ctx context.Context) (Event, error) {
+	if delayed.pending == nil {
+		select {
+		case next, open := <-delayed.events:
+			if !open {
+				return Event{}, delayed.err
+			}
+			delayed.pending = &next
+		case <-ctx.Done():
+			return Event{}, ctx.Err()
+		}
+	}
+
+	timer := time.NewTimer(time.Until(delayed.pending.receivedAt.Add(delayed.delay)))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		event := delayed.pending.event
+		delayed.pending = nil
+		return event, nil
+	case <-ctx.Done():
+		return Event{}, ctx.Err()
+	}
+}
+
+// Close ends the subscription and stops receiving events.
+func (delayed *delayedSubscription) Close() {
+	delayed.cancel()
+	delayed.subscription.Close()
+}
@@ -0,0 +1,100 @@
+package words_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/carterjs/words/internal/words"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGame_AddSpectator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		spectator string
+		watching  int
+		wantErr   error
+	}{
+		{name: "adds named spectators", spectator: "watcher"},
+		{name: "rejects blank names", spectator: "  ", wantErr: words.ErrInvalidName},
+		{name: "rejects long names", spectator: strings.Repeat("a", 41), wantErr: words.ErrInvalidName},
+		{name: "rejects control characters", spectator: "watch\ner", wantErr: words.ErrInvalidName},
+		{name: "rejects spectators over the cap", spectator: "watcher", watching: 100, wantErr: words.ErrTooManySpectators},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			game := newLobbyGame(t, 1, testConfig(map[rune]int{'A': 20}, 3))
+			for index := range test.watching {
+				_, err := game.AddSpectator(fmt.Sprintf("spectator-%d", index))
+				require.NoError(t, err)
+			}
+
+			spectator, err := game.AddSpectator(test.spectator)
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+				assert.Len(t, game.Spectators(), test.watching)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.spectator, spectator.Name())
+			assert.Equal(t, []words.Spectator{spectator}, game.Spectators())
+		})
+	}
+}
+
+func TestGame_RemoveSpectator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		watching int
+		leaving  int
+		stranger bool
+		wantErr  error
+	}{
+		{name: "frees a slot under the cap for the next spectator", watching: 100, leaving: 1},
+		{name: "frees every slot a departing spectator held", watching: 100, leaving: 100},
+		{name: "refuses someone not spectating", watching: 1, stranger: true, wantErr: words.ErrNotParticipant},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			game := newLobbyGame(t, 1, testConfig(map[rune]int{'A': 20}, 3))
+			var spectators []words.Spectator
+			for index := range test.watching {
+				spectator, err := game.AddSpectator(fmt.Sprintf("spectator-%d", index))
+				require.NoError(t, err)
+				spectators = append(spectators, spectator)
+			}
+
+			if test.stranger {
+				assert.ErrorIs(t, game.RemoveSpectator("stranger"), test.wantErr)
+				assert.Len(t, game.Spectators(), test.watching)
+				return
+			}
+
+			for _, spectator := range spectators[:test.leaving] {
+				require.NoError(t, game.RemoveSpectator(spectator.ID()))
+				_, watching := game.SpectatorByID(spectator.ID())
+				assert.False(t, watching)
+			}
+
+			for index := range test.leaving {
+				_, err := game.AddSpectator(fmt.Sprintf("newcomer-%d", index))
+				require.NoError(t, err, "a slot freed by a departing spectator is reused")
+			}
+			_, err := game.AddSpectator("one too many")
+			assert.ErrorIs(t, err, words.ErrTooManySpectators)
+		})
+	}
+}
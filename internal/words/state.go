@@ -1,12 +1,22 @@
 package words
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
 
 // GameState is a serializable snapshot of a game, used by stores to persist
 // and rebuild games. The board is not stored directly; it is rebuilt by
-// replaying the words.
+// replaying the words. Schema is the format the snapshot was written in;
+// see DecodeGameState.
 type GameState struct {
+	Schema         int                  `json:"schema"`
 	ID             string               `json:"id"`
+	Version        uint64               `json:"version"`
+	StateVersion   uint64               `json:"stateVersion,omitempty"`
+	CreatedAt      time.Time            `json:"createdAt"`
 	Started        bool                 `json:"started"`
 	Finished       bool                 `json:"finished"`
 	Round          int                  `json:"round"`
@@ -16,10 +26,18 @@ type GameState struct {
 	Pool           []rune               `json:"pool"`
 	PoolIndex      int                  `json:"poolIndex"`
 	Players        []PlayerState        `json:"players"`
+	Spectators     []SpectatorState     `json:"spectators,omitempty"`
+	Messages       []ChatMessage        `json:"messages,omitempty"`
+	Muted          []string             `json:"muted,omitempty"`
 	Words          []PlacedWordState    `json:"words"`
 	LastWord       *LastPlacedWordState `json:"lastWord,omitempty"`
 	Challenge      *ChallengeState      `json:"challenge,omitempty"`
 	WinnerIDs      []string             `json:"winnerIds,omitempty"`
+	EventSequence  uint64               `json:"eventSequence,omitempty"`
+	Events         []LoggedEvent        `json:"events,omitempty"`
+	Moves          []Move               `json:"moves,omitempty"`
+	ShuffleSeed    uint64               `json:"shuffleSeed,omitempty"`
+	Shuffles       uint64               `json:"shuffles,omitempty"`
 }
 
 // PlayerState is a serializable snapshot of a player.
@@ -31,6 +49,12 @@ type PlayerState struct {
 	FinalAdjustment int          `json:"finalAdjustment"`
 }
 
+// SpectatorState is a serializable snapshot of a spectator.
+type SpectatorState struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
 // PlacedWordState is a serializable snapshot of a placed word.
 type PlacedWordState struct {
 	Column    int       `json:"column"`
@@ -40,6 +64,28 @@ type PlacedWordState struct {
 	Blanks    []Point   `json:"blanks,omitempty"`
 }
 
+func newPlacedWordState(word Word) *PlacedWordState {
+	wordState := &PlacedWordState{
+		Column:    word.Start().Column(),
+		Row:       word.Start().Row(),
+		Direction: word.Direction(),
+		Letters:   string(word.letters),
+	}
+
+	// left nil without blanks, as a stored snapshot decodes it
+	if blanks := word.Blanks(); len(blanks) > 0 {
+		wordState.Blanks = blanks
+	}
+
+	return wordState
+}
+
+// word rebuilds the word the snapshot describes.
+func (wordState PlacedWordState) word() Word {
+	word := NewWord(NewPoint(wordState.Column, wordState.Row), wordState.Direction, wordState.Letters)
+	return word.WithBlanks(wordState.Blanks...)
+}
+
 // LastPlacedWordState is a serializable snapshot of the challenge window.
 type LastPlacedWordState struct {
 	PlayerID string `json:"playerId"`
@@ -55,6 +101,7 @@ type ChallengeState struct {
 // State returns a snapshot of the game for persistence.
 func (game *Game) State() GameState {
 	state := GameState{
+		Schema:         CurrentSchema,
 		ID:             game.id,
 		Started:        game.started,
 		Finished:       game.finished,
@@ -65,7 +112,21 @@ func (game *Game) State() GameState {
 		Pool:           game.pool,
 		PoolIndex:      game.poolIndex,
 		WinnerIDs:      game.winnerIDs,
+		Messages:       game.messages,
+		EventSequence:  game.eventSequence,
+		Version:        game.version,
+		StateVersion:   game.stateVersion,
+		CreatedAt:      game.createdAt,
+		Events:         game.eventLog,
+		Moves:          game.moves,
+		ShuffleSeed:    game.shuffleSeed,
+		Shuffles:       game.shuffles,
+	}
+
+	for participantID := range game.muted {
+		state.Muted = append(state.Muted, participantID)
 	}
+	sort.Strings(state.Muted)
 
 	for _, player := range game.players {
 		state.Players = append(state.Players, PlayerState{
@@ -77,16 +138,17 @@ func (game *Game) State() GameState {
 		})
 	}
 
-	for _, word := range game.board.words {
-		state.Words = append(state.Words, PlacedWordState{
-			Column:    word.Start().Column(),
-			Row:       word.Start().Row(),
-			Direction: word.Direction(),
-			Letters:   string(word.letters),
-			Blanks:    word.Blanks(),
+	for _, spectator := range game.spectators {
+		state.Spectators = append(state.Spectators, SpectatorState{
+			ID:   spectator.id,
+			Name: spectator.name,
 		})
 	}
 
+	for _, word := range game.board.words {
+		state.Words = append(state.Words, *newPlacedWordState(word))
+	}
+
 	if game.lastWord != nil {
 		state.LastWord = &LastPlacedWordState{
 			PlayerID: game.lastWord.playerID,
@@ -109,6 +171,17 @@ func (game *Game) State() GameState {
 	return state
 }
 
+// Compacted returns the snapshot without what only a game still in play
+// needs: the log of events kept for reconnecting subscribers, and the
+// letters already drawn from the pool.
+func (state GameState) Compacted() GameState {
+	state.Events = nil
+	state.Pool = slices.Clone(state.Pool[state.PoolIndex:])
+	state.PoolIndex = 0
+
+	return state
+}
+
 // NewGameFromState rebuilds a game from a stored snapshot.
 func NewGameFromState(state GameState) (*Game, error) {
 	game := &Game{
@@ -122,9 +195,31 @@ func NewGameFromState(state GameState) (*Game, error) {
 		pool:           state.Pool,
 		poolIndex:      state.PoolIndex,
 		winnerIDs:      state.WinnerIDs,
+		messages:       state.Messages,
+		eventSequence:  state.EventSequence,
+		version:        state.Version,
+		stateVersion:   state.StateVersion,
+		createdAt:      state.CreatedAt,
+		eventLog:       state.Events,
+		moves:          state.Moves,
+		shuffleSeed:    state.ShuffleSeed,
+		shuffles:       state.Shuffles,
 		board:          NewBoard(state.Config),
 	}
 
+	if game.stateVersion == 0 {
+		// snapshots from before state versions were kept, which may have
+		// changed at any save
+		game.stateVersion = game.version
+	}
+
+	for _, participantID := range state.Muted {
+		if game.muted == nil {
+			game.muted = make(map[string]struct{})
+		}
+		game.muted[participantID] = struct{}{}
+	}
+
 	for _, playerState := range state.Players {
 		game.players = append(game.players, Player{
 			id:              playerState.ID,
@@ -135,11 +230,15 @@ func NewGameFromState(state GameState) (*Game, error) {
 		})
 	}
 
-	for _, wordState := range state.Words {
-		word := NewWord(NewPoint(wordState.Column, wordState.Row), wordState.Direction, wordState.Letters)
-		word = word.WithBlanks(wordState.Blanks...)
+	for _, spectatorState := range state.Spectators {
+		game.spectators = append(game.spectators, Spectator{
+			id:   spectatorState.ID,
+			name: spectatorState.Name,
+		})
+	}
 
-		if _, err := game.board.PlaceWord(word); err != nil {
+	for _, wordState := range state.Words {
+		if _, err := game.board.PlaceWord(wordState.word()); err != nil {
 			return nil, fmt.Errorf("replaying stored word %q: %w", wordState.Letters, err)
 		}
 	}
@@ -0,0 +1,266 @@
+package words
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TranscriptFormat names a way of writing a game's transcript.
+type TranscriptFormat string
+
+const (
+	// TranscriptFormatText is an annotated plain-text transcript for people.
+	TranscriptFormatText TranscriptFormat = "text"
+	// TranscriptFormatJSONLines writes one TranscriptEntry per line.
+	TranscriptFormatJSONLines TranscriptFormat = "jsonl"
+)
+
+// TranscriptEntry describes one move of a game: who made it, what it did,
+// and every player's running total after it.
+type TranscriptEntry struct {
+	// Move is the entry's 1-based position in the game's history.
+	Move       int                 `json:"move"`
+	Type       MoveType            `json:"type"`
+	PlayerID   string              `json:"playerId"`
+	PlayerName string              `json:"playerName"`
+	Word       string              `json:"word,omitempty"`
+	Position   *TranscriptPosition `json:"position,omitempty"`
+	// WordsFormed lists the played word followed by any word it made
+	// across it.
+	WordsFormed []string `json:"wordsFormed,omitempty"`
+	// Letters are the letters exchanged, or left on a rack at the end.
+	Letters []string       `json:"letters,omitempty"`
+	Points  int            `json:"points"`
+	Totals  map[string]int `json:"totals"`
+}
+
+// TranscriptPosition is where a played word starts and which way it runs.
+type TranscriptPosition struct {
+	Column    int       `json:"column"`
+	Row       int       `json:"row"`
+	Direction Direction `json:"direction"`
+}
+
+// Transcript returns an entry for every move in the game's history. A game
+// played before moves were recorded is reported as ErrNoMoveHistory.
+func (game *Game) Transcript() ([]TranscriptEntry, error) {
+	var entries []TranscriptEntry
+	err := game.ReplayMoves(func(entry TranscriptEntry, _ *Board) error {
+		entries = append(entries, entry)
+		return nil
+	})
+
+	return entries, err
+}
+
+// ReplayMoves replays the game's history onto an empty board, visiting each
+// move's entry with the board as the move left it. The same board is reused
+// for every move, so visitors must copy anything they keep. A game played
+// before moves were recorded is reported as ErrNoMoveHistory.
+func (game *Game) ReplayMoves(visit func(TranscriptEntry, *Board) error) error {
+	if len(game.moves) == 0 && (game.round > 1 || game.turn > 0 || game.finished) {
+		return ErrNoMoveHistory
+	}
+
+	board := NewBoard(game.config)
+	totals := make(map[string]int, len(game.players))
+	for _, player := range game.players {
+		totals[player.id] = 0
+	}
+
+	var played []Word
+	for index, move := range game.moves {
+		playerIndex := game.playerIndex(move.PlayerID)
+		if playerIndex < 0 {
+			return fmt.Errorf("replaying move %d: %w: %w", index+1, ErrReplayDiverged, ErrPlayerNotFound)
+		}
+
+		entry := TranscriptEntry{
+			Move:       index + 1,
+			Type:       move.Type,
+			PlayerID:   move.PlayerID,
+			PlayerName: game.players[playerIndex].name,
+			Points:     move.Points,
+		}
+		if len(move.Letters) > 0 {
+			entry.Letters = letterStrings(move.Letters)
+		}
+
+		switch move.Type {
+		case MoveTypePlay:
+			if move.Word == nil {
+				return fmt.Errorf("replaying move %d: play without a word: %w", index+1, ErrReplayDiverged)
+			}
+
+			word := move.Word.word()
+			result, err := board.PlaceWord(word)
+			if err != nil {
+				return fmt.Errorf("replaying move %d: %w: %w", index+1, ErrReplayDiverged, err)
+			}
+			played = append(played, result.DirectWord)
+
+			entry.Word = result.DirectWord.String()
+			entry.Position = &TranscriptPosition{Column: word.Start().Column(), Row: word.Start().Row(), Direction: word.Direction()}
+			entry.WordsFormed = append(entry.WordsFormed, result.DirectWord.String())
+			for _, indirect := range result.IndirectWords {
+				entry.WordsFormed = append(entry.WordsFormed, indirect.String())
+			}
+		case MoveTypeWithdrawn:
+			if len(played) == 0 {
+				return fmt.Errorf("replaying move %d: nothing to withdraw: %w", index+1, ErrReplayDiverged)
+			}
+
+			if err := board.removeLastWord(); err != nil {
+				return fmt.Errorf("replaying move %d: %w: %w", index+1, ErrReplayDiverged, err)
+			}
+
+			entry.Word = played[len(played)-1].String()
+			played = played[:len(played)-1]
+		}
+
+		totals[move.PlayerID] += move.Points
+		entry.Totals = make(map[string]int, len(totals))
+		for playerID, total := range totals {
+			entry.Totals[playerID] = total
+		}
+
+		if err := visit(entry, board); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteTranscript writes the game's transcript in the given format. Plain
+// text transcripts draw the board after every boardEvery moves, and after
+// the last; zero draws none. JSON Lines transcripts never draw the board.
+func (game *Game) WriteTranscript(w io.Writer, format TranscriptFormat, boardEvery int) error {
+	switch format {
+	case TranscriptFormatText:
+		return game.writeTextTranscript(w, boardEvery)
+	case TranscriptFormatJSONLines:
+		encoder := json.NewEncoder(w)
+		return game.ReplayMoves(func(entry TranscriptEntry, _ *Board) error {
+			return encoder.Encode(entry)
+		})
+	default:
+		return fmt.Errorf("writing transcript as %q: %w", format, ErrUnknownFormat)
+	}
+}
+
+func (game *Game) writeTextTranscript(w io.Writer, boardEvery int) error {
+	writer := bufio.NewWriter(w)
+
+	names := make([]string, len(game.players))
+	for index, player := range game.players {
+		names[index] = player.name
+	}
+	fmt.Fprintf(writer, "Game %s\n", game.id)
+	fmt.Fprintf(writer, "Players: %s\n", strings.Join(names, ", "))
+
+	err := game.ReplayMoves(func(entry TranscriptEntry, board *Board) error {
+		fmt.Fprintf(writer, "\n%d. %s\n", entry.Move, describeMove(entry))
+
+		if len(entry.WordsFormed) > 0 {
+			fmt.Fprintf(writer, "   Words formed: %s\n", strings.Join(entry.WordsFormed, ", "))
+		}
+		fmt.Fprintf(writer, "   Totals: %s\n", game.describeTotals(entry.Totals))
+
+		if boardEvery > 0 && (entry.Move%boardEvery == 0 || entry.Move == len(game.moves)) {
+			fmt.Fprintf(writer, "\n%s", board.String())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if game.finished {
+		fmt.Fprintf(writer, "\nFinal scores: %s\n", game.describeFinalScores())
+	}
+
+	return writer.Flush()
+}
+
+// describeMove renders the entry as a sentence.
+func describeMove(entry TranscriptEntry) string {
+	switch entry.Type {
+	case MoveTypePlay:
+		direction := "across"
+		if entry.Position.Direction == DirectionVertical {
+			direction = "down"
+		}
+
+		return fmt.Sprintf("%s played %s at (%d, %d) %s for %s",
+			entry.PlayerName, entry.Word, entry.Position.Column, entry.Position.Row, direction, describePoints(entry.Points))
+	case MoveTypeExchange:
+		return fmt.Sprintf("%s exchanged %s", entry.PlayerName, strings.Join(entry.Letters, ""))
+	case MoveTypePass:
+		return fmt.Sprintf("%s passed", entry.PlayerName)
+	case MoveTypeWithdrawn:
+		return fmt.Sprintf("%s withdrew %s after a successful challenge, losing %s", entry.PlayerName, entry.Word, describePoints(-entry.Points))
+	case MoveTypeEndRack:
+		if entry.Points < 0 {
+			return fmt.Sprintf("%s lost %s for %s left on their rack", entry.PlayerName, describePoints(-entry.Points), strings.Join(entry.Letters, ""))
+		}
+
+		return fmt.Sprintf("%s gained %s for %s left on an opponent's rack", entry.PlayerName, describePoints(entry.Points), strings.Join(entry.Letters, ""))
+	default:
+		return fmt.Sprintf("%s made an unknown move %q", entry.PlayerName, entry.Type)
+	}
+}
+
+func describePoints(points int) string {
+	if points == 1 {
+		return "1 point"
+	}
+
+	return fmt.Sprintf("%d points", points)
+}
+
+// describeTotals lists every player's total in play order.
+func (game *Game) describeTotals(totals map[string]int) string {
+	described := make([]string, len(game.players))
+	for index, player := range game.players {
+		described[index] = fmt.Sprintf("%s %d", player.name, totals[player.id])
+	}
+
+	return strings.Join(described, ", ")
+}
+
+func (game *Game) describeFinalScores() string {
+	described := make([]string, len(game.players))
+	for index, player := range game.players {
+		described[index] = fmt.Sprintf("%s %d", player.name, player.Score())
+
+		for _, winnerID := range game.winnerIDs {
+			if winnerID == player.id {
+				described[index] += " (winner)"
+			}
+		}
+	}
+
+	return strings.Join(described, ", ")
+}
+
+// WriteTranscript writes a finished game's transcript with
+// Game.WriteTranscript. Racks stay private while a game is in play, so
+// unfinished games are refused with ErrGameNotFinished.
+func (service *Service) WriteTranscript(ctx context.Context, gameID string, w io.Writer, format TranscriptFormat, boardEvery int) error {
+	game, err := service.store.GameByID(ctx, gameID)
+	if err != nil {
+		return fmt.Errorf("loading game: %w", err)
+	}
+
+	if !game.finished {
+		return ErrGameNotFinished
+	}
+
+	return game.WriteTranscript(w, format, boardEvery)
+}
@@ -0,0 +1,143 @@
+package words_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/carterjs/words/internal/words"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGame_Transcript(t *testing.T) {
+	t.Parallel()
+
+	game := newTranscribedGame(t)
+	players := game.Players()
+	first, second := players[0], players[1]
+
+	entries, err := game.Transcript()
+	require.NoError(t, err)
+	require.Len(t, entries, 8)
+
+	assert.Equal(t, words.TranscriptEntry{
+		Move:        2,
+		Type:        words.MoveTypePlay,
+		PlayerID:    second.ID(),
+		PlayerName:  second.Name(),
+		Word:        "AA",
+		Position:    &words.TranscriptPosition{Column: 0, Row: 1, Direction: words.DirectionHorizontal},
+		WordsFormed: []string{"AA", "AA", "AA"},
+		Points:      6,
+		Totals:      map[string]int{first.ID(): 2, second.ID(): 6},
+	}, entries[1])
+
+	assert.Equal(t, words.MoveTypePass, entries[2].Type)
+
+	last := entries[len(entries)-1]
+	assert.Equal(t, words.MoveTypeEndRack, last.Type)
+	assert.Equal(t, []string{"A", "A", "A"}, last.Letters)
+	assert.Equal(t, map[string]int{first.ID(): first.Score(), second.ID(): second.Score()}, last.Totals)
+}
+
+func TestGame_WriteTranscript(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		format     words.TranscriptFormat
+		boardEvery int
+		wantLines  []string
+		wantBoards int
+		wantErr    error
+	}{
+		{
+			name:   "writes an annotated transcript",
+			format: words.TranscriptFormatText,
+			wantLines: []string{
+				"1. player-0 played AA at (0, 0) across for 2 points",
+				"2. player-1 played AA at (0, 1) across for 6 points",
+				"   Words formed: AA, AA, AA",
+				"   Totals: player-0 2, player-1 6",
+				"3. player-0 passed",
+				"7. player-0 lost 3 points for AAA left on their rack",
+				"Final scores: player-0 -1, player-1 3 (winner)",
+			},
+		},
+		{
+			name:       "draws the board every few moves and after the last",
+			format:     words.TranscriptFormatText,
+			boardEvery: 3,
+			wantBoards: 3,
+		},
+		{
+			name:    "refuses an unknown format",
+			format:  "pdf",
+			wantErr: words.ErrUnknownFormat,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			game := newTranscribedGame(t)
+
+			var transcript bytes.Buffer
+			err := game.WriteTranscript(&transcript, test.format, test.boardEvery)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			lines := strings.Split(transcript.String(), "\n")
+			for _, line := range test.wantLines {
+				assert.Contains(t, lines, line)
+			}
+
+			emptyBoard := words.NewBoard(game.Config()).String()
+			headingLine := strings.SplitAfter(emptyBoard, "\n")[0]
+			assert.Equal(t, test.wantBoards*2, strings.Count(transcript.String(), headingLine))
+		})
+	}
+}
+
+func TestGame_WriteTranscript_JSONLines(t *testing.T) {
+	t.Parallel()
+
+	game := newTranscribedGame(t)
+
+	var transcript bytes.Buffer
+	require.NoError(t, game.WriteTranscript(&transcript, words.TranscriptFormatJSONLines, 2))
+
+	want, err := game.Transcript()
+	require.NoError(t, err)
+
+	decoder := json.NewDecoder(&transcript)
+	var got []words.TranscriptEntry
+	for decoder.More() {
+		var entry words.TranscriptEntry
+		require.NoError(t, decoder.Decode(&entry))
+		got = append(got, entry)
+	}
+	assert.Equal(t, want, got)
+}
+
+// newTranscribedGame returns a finished game with two plays, the second
+// forming words across the first, and enough passes to end it.
+func newTranscribedGame(t *testing.T) *words.Game {
+	t.Helper()
+
+	game := newStartedGame(t, 2, testConfig(map[rune]int{'A': 16}, 3))
+	playCurrent(t, game, horizontal(0, 0, "AA"))
+	playCurrent(t, game, horizontal(0, 1, "AA"))
+	for range 4 {
+		require.NoError(t, game.PassTurn(game.CurrentPlayerID()))
+	}
+	require.True(t, game.Finished())
+
+	return game
+}
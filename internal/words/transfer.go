@@ -0,0 +1,286 @@
+package words
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// transferFileSuffix names each game's snapshot inside a tar export.
+const transferFileSuffix = ".json"
+
+// TransferFormat is how an export lays out its games.
+type TransferFormat string
+
+const (
+	// TransferFormatJSONLines writes one GameState per line.
+	TransferFormatJSONLines TransferFormat = "jsonl"
+	// TransferFormatTar writes one <id>.json GameState file per game.
+	TransferFormatTar TransferFormat = "tar"
+)
+
+// ParseTransferFormat returns the named format, rejecting unknown ones with
+// ErrUnknownFormat.
+func ParseTransferFormat(name string) (TransferFormat, error) {
+	switch format := TransferFormat(name); format {
+	case TransferFormatJSONLines, TransferFormatTar:
+		return format, nil
+	default:
+		return "", fmt.Errorf("transfer format %q: %w", name, ErrUnknownFormat)
+	}
+}
+
+// TransferFilter chooses the games an export or import includes: those
+// matching the query's filters and, if there are any GameIDs, among them.
+// The query's cursor and limit are ignored. Snapshots do not record when a
+// game was last saved, so imports ignore the updated-time bounds.
+type TransferFilter struct {
+	GameIDs []string
+	Query   GameQuery
+}
+
+// includes reports whether the filter selects the game.
+func (filter TransferFilter) includes(summary GameSummary) bool {
+	if len(filter.GameIDs) > 0 && !slices.Contains(filter.GameIDs, summary.ID) {
+		return false
+	}
+
+	return filter.Query.Matches(summary)
+}
+
+// ExportGames writes a snapshot of every stored game the filter selects, in
+// the given format, returning how many it wrote. Games are written newest
+// first; one deleted while the export runs is left out.
+func (service *Service) ExportGames(ctx context.Context, w io.Writer, format TransferFormat, filter TransferFilter) (int, error) {
+	var write func(state GameState, updatedAt time.Time) error
+	var finish func() error
+
+	switch format {
+	case TransferFormatJSONLines:
+		encoder := json.NewEncoder(w)
+		write = func(state GameState, _ time.Time) error {
+			return encoder.Encode(state)
+		}
+		finish = func() error { return nil }
+	case TransferFormatTar:
+		archive := tar.NewWriter(w)
+		write = func(state GameState, updatedAt time.Time) error {
+			return writeTarState(archive, state, updatedAt)
+		}
+		finish = archive.Close
+	default:
+		return 0, fmt.Errorf("exporting games as %q: %w", format, ErrUnknownFormat)
+	}
+
+	var exported int
+	err := service.eachGameSummary(ctx, filter.Query, func(summary GameSummary) error {
+		if !filter.includes(summary) {
+			return nil
+		}
+
+		game, err := service.store.GameByID(ctx, summary.ID)
+		if errors.Is(err, ErrGameNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("loading game %s to export: %w", summary.ID, err)
+		}
+
+		if err := write(game.State(), summary.UpdatedAt); err != nil {
+			return fmt.Errorf("writing game %s: %w", summary.ID, err)
+		}
+		exported++
+
+		return nil
+	})
+	if err != nil {
+		return exported, err
+	}
+
+	if err := finish(); err != nil {
+		return exported, fmt.Errorf("finishing export: %w", err)
+	}
+
+	return exported, nil
+}
+
+func writeTarState(archive *tar.Writer, state GameState, updatedAt time.Time) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encoding game: %w", err)
+	}
+
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     state.ID + transferFileSuffix,
+		Mode:     0o644,
+		Size:     int64(len(data)),
+		ModTime:  updatedAt,
+	}
+	if err := archive.WriteHeader(header); err != nil {
+		return err
+	}
+
+	_, err = archive.Write(data)
+	return err
+}
+
+// ImportReport lists what an import did with each game it read.
+type ImportReport struct {
+	// Imported lists the IDs of the games saved.
+	Imported []string `json:"imported"`
+	// Skipped lists the IDs of games left out because they are already
+	// stored.
+	Skipped []string `json:"skipped"`
+	// Rejected lists the games that failed validation.
+	Rejected []ImportRejection `json:"rejected"`
+}
+
+// ImportRejection explains why one game in an import was not saved.
+type ImportRejection struct {
+	// Entry is the game's 1-based position in the import.
+	Entry  int    `json:"entry"`
+	GameID string `json:"gameId,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// ImportGames reads snapshots in the given format and saves each one the
+// filter selects as a new game at version 1, since version numbers are only
+// meaningful within the store that issued them. Snapshots in older schemas
+// are migrated. A game that is already stored is skipped rather than
+// overwritten, and one that does not rebuild with NewGameFromState is
+// rejected; neither stops the import. Input that cannot be read stops it
+// with ErrMalformedTransfer, leaving the games before it imported.
+func (service *Service) ImportGames(ctx context.Context, r io.Reader, format TransferFormat, filter TransferFilter) (ImportReport, error) {
+	var report ImportReport
+
+	filter.Query.UpdatedAfter = time.Time{}
+	filter.Query.UpdatedBefore = time.Time{}
+
+	importEntry := func(entry int, name string, data []byte) error {
+		state, err := DecodeGameState(data)
+		if err != nil {
+			report.Rejected = append(report.Rejected, ImportRejection{Entry: entry, GameID: name, Reason: err.Error()})
+			return nil
+		}
+
+		imported, err := service.importGame(ctx, state, filter)
+		switch {
+		case errors.Is(err, errFilteredOut):
+		case errors.Is(err, errAlreadyStored):
+			report.Skipped = append(report.Skipped, state.ID)
+		case errors.Is(err, errInvalidState):
+			report.Rejected = append(report.Rejected, ImportRejection{Entry: entry, GameID: state.ID, Reason: err.Error()})
+		case err != nil:
+			return fmt.Errorf("importing game %s: %w", state.ID, err)
+		default:
+			report.Imported = append(report.Imported, imported.ID())
+		}
+
+		return nil
+	}
+
+	switch format {
+	case TransferFormatJSONLines:
+		decoder := json.NewDecoder(r)
+		for entry := 1; ; entry++ {
+			var data json.RawMessage
+			if err := decoder.Decode(&data); err == io.EOF {
+				return report, nil
+			} else if err != nil {
+				return report, fmt.Errorf("reading entry %d: %w: %w", entry, ErrMalformedTransfer, err)
+			}
+
+			if err := importEntry(entry, "", data); err != nil {
+				return report, err
+			}
+		}
+	case TransferFormatTar:
+		archive := tar.NewReader(r)
+		for entry := 1; ; {
+			header, err := archive.Next()
+			if err == io.EOF {
+				return report, nil
+			}
+			if err != nil {
+				return report, fmt.Errorf("reading entry %d: %w: %w", entry, ErrMalformedTransfer, err)
+			}
+			if header.Typeflag != tar.TypeReg || !strings.HasSuffix(header.Name, transferFileSuffix) {
+				continue
+			}
+
+			data, err := io.ReadAll(archive)
+			if err != nil {
+				return report, fmt.Errorf("reading entry %d: %w: %w", entry, ErrMalformedTransfer, err)
+			}
+
+			if err := importEntry(entry, strings.TrimSuffix(path.Base(header.Name), transferFileSuffix), data); err != nil {
+				return report, err
+			}
+			entry++
+		}
+	default:
+		return report, fmt.Errorf("importing games as %q: %w", format, ErrUnknownFormat)
+	}
+}
+
+var (
+	errFilteredOut   = errors.New("game not selected")
+	errAlreadyStored = errors.New("game already stored")
+	errInvalidState  = errors.New("invalid game state")
+)
+
+// importGame validates the snapshot and saves it as a new game.
+func (service *Service) importGame(ctx context.Context, state GameState, filter TransferFilter) (*Game, error) {
+	if state.ID == "" {
+		return nil, fmt.Errorf("%w: missing id", errInvalidState)
+	}
+
+	// stores build paths from game IDs, so only take the canonical UUIDs
+	// this service issues
+	if id, err := uuid.Parse(state.ID); err != nil || id.String() != state.ID {
+		return nil, fmt.Errorf("%w: id %q is not a UUID", errInvalidState, state.ID)
+	}
+
+	state.Version = 1
+
+	game, err := NewGameFromState(state)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidState, err)
+	}
+
+	if !filter.includes(game.Summary(time.Time{})) {
+		return nil, errFilteredOut
+	}
+
+	unlock, err := service.lockGame(ctx, state.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if _, err := service.store.GameByID(ctx, state.ID); err == nil {
+		return nil, errAlreadyStored
+	} else if !errors.Is(err, ErrGameNotFound) {
+		return nil, fmt.Errorf("checking for stored game: %w", err)
+	}
+
+	if err := service.store.SaveGame(ctx, game); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return nil, errAlreadyStored
+		}
+
+		return nil, fmt.Errorf("saving imported game: %w", err)
+	}
+
+	return game, nil
+}
@@ -0,0 +1,252 @@
+package words_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/carterjs/words/internal/lock"
+	"github.com/carterjs/words/internal/pubsub"
+	"github.com/carterjs/words/internal/store"
+	"github.com/carterjs/words/internal/words"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ExportGames(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		format words.TransferFormat
+		filter func(gameIDs []string) words.TransferFilter
+		want   func(gameIDs []string) []string
+	}{
+		{
+			name:   "moves every game as JSON lines",
+			format: words.TransferFormatJSONLines,
+			filter: func([]string) words.TransferFilter { return words.TransferFilter{} },
+			want:   func(gameIDs []string) []string { return gameIDs },
+		},
+		{
+			name:   "moves every game as a tar",
+			format: words.TransferFormatTar,
+			filter: func([]string) words.TransferFilter { return words.TransferFilter{} },
+			want:   func(gameIDs []string) []string { return gameIDs },
+		},
+		{
+			name:   "moves only the chosen games",
+			format: words.TransferFormatTar,
+			filter: func(gameIDs []string) words.TransferFilter {
+				return words.TransferFilter{GameIDs: gameIDs[:2]}
+			},
+			want: func(gameIDs []string) []string { return gameIDs[:2] },
+		},
+		{
+			name:   "moves only games matching the query",
+			format: words.TransferFormatJSONLines,
+			filter: func([]string) words.TransferFilter {
+				return words.TransferFilter{Query: words.GameQuery{Status: words.GameStatusRunning}}
+			},
+			want: func(gameIDs []string) []string { return gameIDs[2:] },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			source := newStoredService(t)
+			var gameIDs []string
+			for range 3 {
+				game, err := source.CreateGame(t.Context(), "standard", words.ConfigOverrides{})
+				require.NoError(t, err)
+				gameIDs = append(gameIDs, game.ID())
+			}
+			_, _, err := source.JoinGame(t.Context(), gameIDs[2], "alice")
+			require.NoError(t, err)
+			_, err = source.StartGame(t.Context(), gameIDs[2])
+			require.NoError(t, err)
+
+			var exported bytes.Buffer
+			count, err := source.ExportGames(t.Context(), &exported, test.format, test.filter(gameIDs))
+			require.NoError(t, err)
+
+			want := test.want(gameIDs)
+			assert.Equal(t, len(want), count)
+
+			destination := newStoredService(t)
+			report, err := destination.ImportGames(t.Context(), &exported, test.format, words.TransferFilter{})
+			require.NoError(t, err)
+			assert.ElementsMatch(t, want, report.Imported)
+
+			for _, gameID := range want {
+				original, err := source.GameByID(t.Context(), gameID)
+				require.NoError(t, err)
+				imported, err := destination.GameByID(t.Context(), gameID)
+				require.NoError(t, err)
+
+				assert.Equal(t, uint64(1), imported.Version())
+				assertSameGame(t, original, imported)
+			}
+		})
+	}
+}
+
+func TestService_ImportGames(t *testing.T) {
+	t.Parallel()
+
+	var (
+		first  = uuid.NewString()
+		second = uuid.NewString()
+		stored = uuid.NewString()
+	)
+
+	tests := []struct {
+		name         string
+		input        string
+		filter       words.TransferFilter
+		wantImported []string
+		wantSkipped  []string
+		wantRejected []int
+		wantReason   string
+		wantErr      error
+	}{
+		{
+			name:         "imports new games",
+			input:        exportedState(t, first) + exportedState(t, second),
+			wantImported: []string{first, second},
+		},
+		{
+			name:         "skips stored games",
+			input:        exportedState(t, stored) + exportedState(t, first),
+			wantImported: []string{first},
+			wantSkipped:  []string{stored},
+		},
+		{
+			name:         "imports only the chosen games",
+			input:        exportedState(t, first) + exportedState(t, second),
+			filter:       words.TransferFilter{GameIDs: []string{second}},
+			wantImported: []string{second},
+		},
+		{
+			name:         "rejects invalid games and carries on",
+			input:        `{"schema":1}` + "\n" + `{"schema":99,"id":"future"}` + "\n" + exportedState(t, first),
+			wantImported: []string{first},
+			wantRejected: []int{1, 2},
+		},
+		{
+			name:         "rejects ids that are not UUIDs",
+			input:        exportedState(t, "../../escape") + exportedState(t, "index") + exportedState(t, strings.ToUpper(first)) + exportedState(t, second),
+			wantImported: []string{second},
+			wantRejected: []int{1, 2, 3},
+			wantReason:   "invalid game state",
+		},
+		{
+			name:    "stops at malformed input",
+			input:   exportedState(t, first) + `{"schema":`,
+			wantErr: words.ErrMalformedTransfer,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			service := newStoredService(t)
+			_, err := service.ImportGames(t.Context(), strings.NewReader(exportedState(t, stored)), words.TransferFormatJSONLines, words.TransferFilter{})
+			require.NoError(t, err)
+
+			report, err := service.ImportGames(t.Context(), strings.NewReader(test.input), words.TransferFormatJSONLines, test.filter)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.wantImported, report.Imported)
+			assert.Equal(t, test.wantSkipped, report.Skipped)
+
+			var rejected []int
+			for _, rejection := range report.Rejected {
+				rejected = append(rejected, rejection.Entry)
+				assert.Contains(t, rejection.Reason, test.wantReason)
+			}
+			assert.Equal(t, test.wantRejected, rejected)
+		})
+	}
+}
+
+func TestParseTransferFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    words.TransferFormat
+		wantErr error
+	}{
+		{name: "parses JSON lines", input: "jsonl", want: words.TransferFormatJSONLines},
+		{name: "parses tar", input: "tar", want: words.TransferFormatTar},
+		{name: "rejects an unknown format", input: "zip", wantErr: words.ErrUnknownFormat},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			format, err := words.ParseTransferFormat(test.input)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, format)
+		})
+	}
+}
+
+func newStoredService(t *testing.T) *words.Service {
+	t.Helper()
+
+	logger := slog.New(slog.DiscardHandler)
+
+	return words.NewService(store.NewFS(t.TempDir(), logger), pubsub.NewGameBroker(pubsub.Config{}), lock.NewLocal(), logger)
+}
+
+// exportedState returns one JSON line holding a new lobby game's snapshot
+// under the given ID.
+func exportedState(t *testing.T, gameID string) string {
+	t.Helper()
+
+	preset, exists := words.PresetByID("standard")
+	require.True(t, exists)
+
+	state := words.NewGame(preset.Config).State()
+	state.ID = gameID
+	state.Version = 7
+
+	data, err := json.Marshal(state)
+	require.NoError(t, err)
+
+	return string(data) + "\n"
+}
+
+// assertSameGame compares the games' snapshots apart from their versions.
+func assertSameGame(t *testing.T, want, got *words.Game) {
+	t.Helper()
+
+	wantState, gotState := want.State(), got.State()
+	wantState.Version, gotState.Version = 0, 0
+
+	wantData, err := json.Marshal(wantState)
+	require.NoError(t, err)
+	gotData, err := json.Marshal(gotState)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, string(wantData), string(gotData))
+}
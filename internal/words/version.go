@@ -0,0 +1,67 @@
+package words
+
+import (
+	"context"
+	"slices"
+)
+
+// Version returns how many times the game has been saved. Stores compare it
+// against their own copy to refuse lost updates.
+func (game *Game) Version() uint64 {
+	return game.version
+}
+
+// StateVersion returns the version of the last save that changed how the
+// game is played, ignoring saves that only added chat messages, spectators
+// or mutes. Clients make conditional updates against it, so a busy chat
+// does not fail every move made from a slightly older copy.
+func (game *Game) StateVersion() uint64 {
+	return game.stateVersion
+}
+
+// advanceVersion moves the game to the version its next save will create.
+func (game *Game) advanceVersion() {
+	game.version++
+
+	if changesState(game.actions) {
+		game.stateVersion = game.version
+	}
+}
+
+// changesState reports whether a save taking the actions changes the game's
+// state version. A save without actions, such as a game's first, always
+// does.
+func changesState(actions []Action) bool {
+	if len(actions) == 0 {
+		return true
+	}
+
+	for _, action := range actions {
+		switch action.Type {
+		case ActionTypeSendMessage, ActionTypeSpectate, ActionTypeLeave, ActionTypeMute:
+		default:
+			return true
+		}
+	}
+
+	return false
+}
+
+type expectedVersionKey struct{}
+
+// WithExpectedVersion returns a context under which mutations fail with
+// ErrVersionMismatch unless the game is still at one of the given state
+// versions.
+func WithExpectedVersion(ctx context.Context, versions ...uint64) context.Context {
+	return context.WithValue(ctx, expectedVersionKey{}, versions)
+}
+
+// checkExpectedVersion enforces any versions the context expects.
+func checkExpectedVersion(ctx context.Context, game *Game) error {
+	expected, exists := ctx.Value(expectedVersionKey{}).([]uint64)
+	if exists && !slices.Contains(expected, game.stateVersion) {
+		return ErrVersionMismatch
+	}
+
+	return nil
+}